@@ -0,0 +1,193 @@
+package audit
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/hbjs97/ctx/internal/fsutil"
+)
+
+// 감사 이벤트 종류.
+const (
+	EventGuardPass   = "guard_pass"
+	EventGuardBlock  = "guard_block"
+	EventGuardBypass = "guard_bypass"
+	EventGuardAllow  = "guard_allow" // ctx guard allow로 허용 토큰을 만듦
+	EventResolve     = "resolve"
+)
+
+const (
+	// DefaultMaxSize는 로그 파일을 회전하는 크기다.
+	DefaultMaxSize = 1 << 20
+	// DefaultKeep은 회전된 로그 파일(audit.log.1 ...)을 몇 개까지 남길지다.
+	DefaultKeep = 3
+)
+
+// Record는 감사 로그 한 줄이다.
+type Record struct {
+	Time       time.Time   `json:"time"`
+	Event      string      `json:"event"`
+	Repo       string      `json:"repo,omitempty"`   // origin의 owner/repo
+	Dir        string      `json:"dir,omitempty"`    // 작업 트리 경로
+	Remote     string      `json:"remote,omitempty"` // 검사한 remote URL
+	Profile    string      `json:"profile,omitempty"`
+	Stage      string      `json:"stage,omitempty"` // guard 검사 단계 (push, commit)
+	Violations []Violation `json:"violations,omitempty"`
+	Reason     string      `json:"reason,omitempty"` // 판정 단계, 우회 방법 등
+}
+
+// Violation은 guard 위반 항목 하나다.
+type Violation struct {
+	Field    string `json:"field"`
+	Expected string `json:"expected"`
+	Actual   string `json:"actual"`
+	Severity string `json:"severity"`
+	Rule     string `json:"rule,omitempty"`
+}
+
+// Filter는 Read가 반환할 기록의 조건이다. 빈 필드는 조건에서 빠진다.
+type Filter struct {
+	Event   string
+	Profile string
+	Repo    string // owner/repo 또는 작업 트리 경로의 일부
+	Since   time.Time
+}
+
+func (f Filter) match(r Record) bool {
+	switch {
+	case f.Event != "" && r.Event != f.Event:
+		return false
+	case f.Profile != "" && r.Profile != f.Profile:
+		return false
+	case f.Repo != "" && !strings.Contains(r.Repo, f.Repo) && !strings.Contains(r.Dir, f.Repo):
+		return false
+	case !f.Since.IsZero() && r.Time.Before(f.Since):
+		return false
+	}
+	return true
+}
+
+// DefaultPath는 $XDG_STATE_HOME/ctx/audit.log를 반환한다. XDG_STATE_HOME이 없으면 ~/.local/state를 쓴다.
+func DefaultPath() string {
+	if dir := os.Getenv("XDG_STATE_HOME"); dir != "" {
+		return filepath.Join(dir, "ctx", "audit.log")
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		home = "."
+	}
+	return filepath.Join(home, ".local", "state", "ctx", "audit.log")
+}
+
+// Log는 크기로 회전하는 JSONL 감사 로그다.
+type Log struct {
+	Path    string
+	MaxSize int64 // 이 크기를 넘기 전에 audit.log → audit.log.1로 회전한다
+	Keep    int   // 남길 회전 파일 수
+}
+
+// New는 기본 회전 설정으로 path의 Log를 만든다.
+func New(path string) *Log {
+	return &Log{Path: path, MaxSize: DefaultMaxSize, Keep: DefaultKeep}
+}
+
+// Append는 기록 한 줄을 덧붙인다. Time이 비어 있으면 현재 시각을 쓴다.
+// 여러 ctx 프로세스가 동시에 쓰거나 회전해도 줄이 섞이지 않도록 lock을 잡는다.
+func (l *Log) Append(r Record) error {
+	if r.Time.IsZero() {
+		r.Time = time.Now()
+	}
+	line, err := json.Marshal(r)
+	if err != nil {
+		return fmt.Errorf("audit.Append: %w", err)
+	}
+	line = append(line, '\n')
+
+	if err := os.MkdirAll(filepath.Dir(l.Path), 0700); err != nil {
+		return fmt.Errorf("audit.Append: %w", err)
+	}
+	lock, err := fsutil.Lock(l.Path)
+	if err != nil {
+		return fmt.Errorf("audit.Append: %w", err)
+	}
+	defer lock.Unlock()
+
+	if info, err := os.Stat(l.Path); err == nil && info.Size() > 0 && info.Size()+int64(len(line)) > l.MaxSize {
+		if err := l.rotate(); err != nil {
+			return fmt.Errorf("audit.Append: %w", err)
+		}
+	}
+
+	f, err := os.OpenFile(l.Path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0600)
+	if err != nil {
+		return fmt.Errorf("audit.Append: %w", err)
+	}
+	if _, err := f.Write(line); err != nil {
+		_ = f.Close() // 쓰기 에러를 우선 반환
+		return fmt.Errorf("audit.Append: %w", err)
+	}
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("audit.Append: %w", err)
+	}
+	return nil
+}
+
+// rotate는 audit.log.N을 audit.log.N+1로 밀고 audit.log를 audit.log.1로 옮긴다. Keep을 넘는 파일은 지운다.
+func (l *Log) rotate() error {
+	if err := os.Remove(l.rotated(l.Keep)); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	for i := l.Keep - 1; i >= 1; i-- {
+		if err := os.Rename(l.rotated(i), l.rotated(i+1)); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+	if l.Keep < 1 {
+		return os.Remove(l.Path)
+	}
+	return os.Rename(l.Path, l.rotated(1))
+}
+
+func (l *Log) rotated(i int) string {
+	return fmt.Sprintf("%s.%d", l.Path, i)
+}
+
+// Read는 회전된 파일을 포함해 오래된 순으로 filter에 맞는 기록을 반환한다.
+// 해석할 수 없는 줄은 건너뛴다. 로그가 없으면 빈 결과다.
+func (l *Log) Read(filter Filter) ([]Record, error) {
+	var records []Record
+	for i := l.Keep; i >= 0; i-- {
+		path := l.Path
+		if i > 0 {
+			path = l.rotated(i)
+		}
+		data, err := os.ReadFile(path)
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("audit.Read: %w", err)
+		}
+		sc := bufio.NewScanner(bytes.NewReader(data))
+		sc.Buffer(nil, 1<<20)
+		for sc.Scan() {
+			var r Record
+			if err := json.Unmarshal(sc.Bytes(), &r); err != nil {
+				continue // 쓰다 중단된 줄 등은 건너뛴다
+			}
+			if filter.match(r) {
+				records = append(records, r)
+			}
+		}
+		if err := sc.Err(); err != nil {
+			return nil, fmt.Errorf("audit.Read: %s: %w", path, err)
+		}
+	}
+	return records, nil
+}
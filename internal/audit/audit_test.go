@@ -0,0 +1,129 @@
+package audit_test
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/hbjs97/ctx/internal/audit"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLog_AppendAndRead(t *testing.T) {
+	log := audit.New(filepath.Join(t.TempDir(), "state", "audit.log"))
+
+	require.NoError(t, log.Append(audit.Record{Event: audit.EventGuardPass, Repo: "myorg/api", Profile: "work"}))
+	require.NoError(t, log.Append(audit.Record{
+		Event:      audit.EventGuardBlock,
+		Repo:       "me/dotfiles",
+		Profile:    "personal",
+		Violations: []audit.Violation{{Field: "user_email", Expected: "me@home.com", Actual: "me@work.com", Severity: "error"}},
+	}))
+
+	records, err := log.Read(audit.Filter{})
+	require.NoError(t, err)
+	require.Len(t, records, 2)
+	assert.Equal(t, audit.EventGuardPass, records[0].Event)
+	assert.False(t, records[0].Time.IsZero(), "Time이 비어 있으면 현재 시각으로 채워야 함")
+	assert.Equal(t, "user_email", records[1].Violations[0].Field)
+
+	info, err := os.Stat(log.Path)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+}
+
+func TestLog_ReadMissing(t *testing.T) {
+	records, err := audit.New(filepath.Join(t.TempDir(), "audit.log")).Read(audit.Filter{})
+	require.NoError(t, err)
+	assert.Empty(t, records)
+}
+
+func TestLog_ReadFilter(t *testing.T) {
+	log := audit.New(filepath.Join(t.TempDir(), "audit.log"))
+	old := time.Now().Add(-48 * time.Hour)
+	require.NoError(t, log.Append(audit.Record{Time: old, Event: audit.EventResolve, Repo: "myorg/api", Profile: "work"}))
+	require.NoError(t, log.Append(audit.Record{Event: audit.EventGuardBlock, Repo: "myorg/api", Profile: "work"}))
+	require.NoError(t, log.Append(audit.Record{Event: audit.EventGuardPass, Dir: "/home/me/dotfiles", Profile: "personal"}))
+
+	tests := []struct {
+		name   string
+		filter audit.Filter
+		want   int
+	}{
+		{"event", audit.Filter{Event: audit.EventGuardBlock}, 1},
+		{"profile", audit.Filter{Profile: "work"}, 2},
+		{"repo", audit.Filter{Repo: "myorg/"}, 2},
+		{"repo matches dir", audit.Filter{Repo: "dotfiles"}, 1},
+		{"since", audit.Filter{Since: time.Now().Add(-time.Hour)}, 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			records, err := log.Read(tt.filter)
+			require.NoError(t, err)
+			assert.Len(t, records, tt.want)
+		})
+	}
+}
+
+func TestLog_SkipsCorruptLines(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.log")
+	require.NoError(t, os.WriteFile(path, []byte("{\"event\":\"resolve\"}\n{\"event\":\"gua\n"), 0600))
+
+	records, err := audit.New(path).Read(audit.Filter{})
+	require.NoError(t, err)
+	require.Len(t, records, 1)
+	assert.Equal(t, audit.EventResolve, records[0].Event)
+}
+
+func TestLog_Rotate(t *testing.T) {
+	log := audit.New(filepath.Join(t.TempDir(), "audit.log"))
+	log.MaxSize = 200
+	log.Keep = 2
+
+	for i := range 20 {
+		require.NoError(t, log.Append(audit.Record{Event: audit.EventResolve, Repo: fmt.Sprintf("org/repo-%02d", i)}))
+	}
+
+	for _, p := range []string{log.Path, log.Path + ".1", log.Path + ".2"} {
+		info, err := os.Stat(p)
+		require.NoError(t, err, p)
+		assert.LessOrEqual(t, info.Size(), log.MaxSize, p)
+	}
+	_, err := os.Stat(log.Path + ".3")
+	assert.True(t, os.IsNotExist(err), "Keep을 넘는 파일은 지워야 함")
+
+	records, err := log.Read(audit.Filter{})
+	require.NoError(t, err)
+	require.NotEmpty(t, records)
+	assert.Equal(t, "org/repo-19", records[len(records)-1].Repo, "최신 기록이 마지막이어야 함")
+	for i := 1; i < len(records); i++ {
+		assert.Less(t, records[i-1].Repo, records[i].Repo, "회전 파일을 포함해 오래된 순이어야 함")
+	}
+}
+
+func TestLog_ConcurrentAppend(t *testing.T) {
+	log := audit.New(filepath.Join(t.TempDir(), "audit.log"))
+
+	var wg sync.WaitGroup
+	for i := range 20 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			assert.NoError(t, log.Append(audit.Record{Event: audit.EventGuardPass, Repo: fmt.Sprintf("org/repo-%d", i)}))
+		}()
+	}
+	wg.Wait()
+
+	records, err := log.Read(audit.Filter{})
+	require.NoError(t, err)
+	assert.Len(t, records, 20)
+}
+
+func TestDefaultPath_XDGStateHome(t *testing.T) {
+	t.Setenv("XDG_STATE_HOME", "/tmp/state")
+	assert.Equal(t, filepath.Join("/tmp/state", "ctx", "audit.log"), audit.DefaultPath())
+}
@@ -0,0 +1,2 @@
+// Package audit records guard decisions and profile resolutions in a local JSONL log.
+package audit
@@ -2,16 +2,22 @@ package cache
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
 	"time"
+
+	"github.com/hbjs97/ctx/internal/fsutil"
 )
 
 // Cache는 리포-프로필 매핑 캐시다.
 type Cache struct {
 	Version int              `json:"version"`
 	Entries map[string]Entry `json:"entries"`
+	// OwnerSyncs는 프로필별 마지막 조직 조회 시각(RFC3339)이다.
+	// probe로 판정한 뒤 owners 갱신을 제안하는 빈도를 제한한다.
+	OwnerSyncs map[string]string `json:"owner_syncs,omitempty"`
 }
 
 // Entry는 하나의 캐시 항목이다.
@@ -24,10 +30,12 @@ type Entry struct {
 
 // New는 빈 캐시를 생성한다.
 func New() *Cache {
-	return &Cache{Version: 1, Entries: make(map[string]Entry)}
+	return &Cache{Version: CurrentVersion, Entries: make(map[string]Entry)}
 }
 
 // Load는 캐시 파일을 파싱한다. 파일 없음/파싱 실패 시 빈 캐시 반환 (graceful).
+// 파싱에 실패한 파일은 "<path>.corrupt-<시각>"으로 옮겨 두고 경고를 출력한다.
+// 이전 버전 캐시는 메모리에서 마이그레이션하고, 더 새로운 버전은 ErrUnsupportedVersion을 반환한다.
 func Load(path string) (*Cache, error) {
 	data, err := os.ReadFile(path)
 	if os.IsNotExist(err) {
@@ -36,9 +44,25 @@ func Load(path string) (*Cache, error) {
 	if err != nil {
 		return nil, fmt.Errorf("cache.Load: %w", err)
 	}
+
+	var doc map[string]any
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return backupCorrupt(path, err), nil
+	}
+	applied, _, err := migrateDocument(doc)
+	if errors.Is(err, ErrUnsupportedVersion) {
+		return nil, fmt.Errorf("cache.Load: %w", err)
+	}
+	if err != nil {
+		return backupCorrupt(path, err), nil
+	}
+	if len(applied) > 0 {
+		data, _ = json.Marshal(doc) // Unmarshal한 값을 다시 인코딩하므로 실패하지 않는다
+	}
+
 	var c Cache
 	if err := json.Unmarshal(data, &c); err != nil {
-		return New(), nil
+		return backupCorrupt(path, err), nil
 	}
 	if c.Entries == nil {
 		c.Entries = make(map[string]Entry)
@@ -46,6 +70,17 @@ func Load(path string) (*Cache, error) {
 	return &c, nil
 }
 
+// backupCorrupt는 손상된 캐시 파일을 옆으로 옮기고 경고를 출력한 뒤 빈 캐시를 반환한다.
+func backupCorrupt(path string, cause error) *Cache {
+	backup := fmt.Sprintf("%s.corrupt-%s", path, time.Now().Format("20060102-150405"))
+	if err := os.Rename(path, backup); err != nil {
+		fmt.Fprintf(os.Stderr, "경고: 캐시 파일 %s 손상 (%v), 빈 캐시로 시작합니다 (백업 실패: %v)\n", path, cause, err)
+	} else {
+		fmt.Fprintf(os.Stderr, "경고: 캐시 파일 %s 손상 (%v), %s로 백업하고 빈 캐시로 시작합니다\n", path, cause, backup)
+	}
+	return New()
+}
+
 // Lookup은 키로 캐시를 조회한다. TTL과 config_hash가 유효해야 hit.
 func (c *Cache) Lookup(key, configHash string, ttlDays int) (*Entry, bool) {
 	e, ok := c.Entries[key]
@@ -70,7 +105,26 @@ func (c *Cache) Set(key string, entry Entry) {
 	c.Entries[key] = entry
 }
 
-// Save는 캐시를 JSON 파일로 저장한다 (0600 권한).
+// OwnerSyncDue는 프로필의 조직을 마지막으로 조회한 지 interval이 지났는지 반환한다.
+// 기록이 없거나 읽을 수 없으면 true다.
+func (c *Cache) OwnerSyncDue(profile string, now time.Time, interval time.Duration) bool {
+	synced, err := time.Parse(time.RFC3339, c.OwnerSyncs[profile])
+	if err != nil {
+		return true
+	}
+	return now.Sub(synced) >= interval || synced.After(now)
+}
+
+// MarkOwnerSync는 프로필의 조직을 조회한 시각을 기록한다.
+func (c *Cache) MarkOwnerSync(profile string, now time.Time) {
+	if c.OwnerSyncs == nil {
+		c.OwnerSyncs = make(map[string]string)
+	}
+	c.OwnerSyncs[profile] = now.Format(time.RFC3339)
+}
+
+// Save는 캐시를 JSON 파일로 원자적으로 저장한다 (0600 권한).
+// 다른 프로세스의 변경을 덮어쓰지 않으려면 Update를 사용한다.
 func (c *Cache) Save(path string) error {
 	data, err := json.MarshalIndent(c, "", "  ")
 	if err != nil {
@@ -79,12 +133,37 @@ func (c *Cache) Save(path string) error {
 	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
 		return fmt.Errorf("cache.Save: %w", err)
 	}
-	if err := os.WriteFile(path, data, 0600); err != nil {
+	if err := fsutil.WriteFileAtomic(path, data, 0600); err != nil {
 		return fmt.Errorf("cache.Save: %w", err)
 	}
 	return nil
 }
 
+// Update는 캐시 파일을 lock한 상태에서 다시 읽어 fn을 적용하고 저장한다.
+// 동시에 실행되는 ctx clone 등이 서로의 항목을 잃지 않게 한다.
+func Update(path string, fn func(*Cache) error) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return fmt.Errorf("cache.Update: %w", err)
+	}
+	lock, err := fsutil.Lock(path)
+	if err != nil {
+		return fmt.Errorf("cache.Update: %w", err)
+	}
+	defer lock.Unlock()
+
+	c, err := Load(path)
+	if err != nil {
+		return fmt.Errorf("cache.Update: %w", err)
+	}
+	if err := fn(c); err != nil {
+		return fmt.Errorf("cache.Update: %w", err)
+	}
+	if err := c.Save(path); err != nil {
+		return fmt.Errorf("cache.Update: %w", err)
+	}
+	return nil
+}
+
 // InvalidateByProfile은 특정 프로필의 모든 캐시 항목을 제거한다.
 func (c *Cache) InvalidateByProfile(profile string) {
 	for key, entry := range c.Entries {
@@ -1,8 +1,11 @@
 package cache_test
 
 import (
+	"errors"
+	"fmt"
 	"os"
 	"path/filepath"
+	"sync"
 	"testing"
 	"time"
 
@@ -44,6 +47,16 @@ func TestLoadCache_InvalidJSON(t *testing.T) {
 	c, err := cache.Load(path)
 	require.NoError(t, err) // graceful degradation
 	assert.Empty(t, c.Entries)
+
+	// 손상된 파일은 버리지 않고 백업한다
+	backups, err := filepath.Glob(path + ".corrupt-*")
+	require.NoError(t, err)
+	require.Len(t, backups, 1)
+	data, err := os.ReadFile(backups[0])
+	require.NoError(t, err)
+	assert.Equal(t, "not json {{{", string(data))
+	_, err = os.Stat(path)
+	assert.True(t, os.IsNotExist(err))
 }
 
 func TestLookup_Hit(t *testing.T) {
@@ -130,3 +143,102 @@ func TestInvalidate_ByProfile(t *testing.T) {
 	assert.Len(t, c.Entries, 1)
 	assert.Contains(t, c.Entries, "user/repo3")
 }
+
+func TestOwnerSyncDue(t *testing.T) {
+	c := cache.New()
+	now := time.Now()
+	assert.True(t, c.OwnerSyncDue("work", now, time.Hour), "기록 없음")
+
+	c.MarkOwnerSync("work", now)
+	assert.False(t, c.OwnerSyncDue("work", now.Add(30*time.Minute), time.Hour))
+	assert.True(t, c.OwnerSyncDue("work", now.Add(time.Hour), time.Hour))
+	assert.True(t, c.OwnerSyncDue("work", now.Add(-time.Hour), time.Hour), "미래 시각 기록은 무시")
+	assert.True(t, c.OwnerSyncDue("personal", now, time.Hour))
+}
+
+func TestUpdate_ConcurrentWritersKeepAllEntries(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cache.json")
+
+	const writers = 20
+	var wg sync.WaitGroup
+	for i := range writers {
+		wg.Go(func() {
+			err := cache.Update(path, func(c *cache.Cache) error {
+				c.Set(fmt.Sprintf("org/repo%d", i), cache.Entry{Profile: "work"})
+				return nil
+			})
+			assert.NoError(t, err)
+		})
+	}
+	wg.Wait()
+
+	c, err := cache.Load(path)
+	require.NoError(t, err)
+	assert.Len(t, c.Entries, writers)
+}
+
+func TestUpdate_FnErrorKeepsFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cache.json")
+	require.NoError(t, cache.Update(path, func(c *cache.Cache) error {
+		c.Set("org/repo", cache.Entry{Profile: "work"})
+		return nil
+	}))
+
+	err := cache.Update(path, func(c *cache.Cache) error {
+		delete(c.Entries, "org/repo")
+		return errors.New("boom")
+	})
+	require.Error(t, err)
+
+	c, err := cache.Load(path)
+	require.NoError(t, err)
+	assert.Contains(t, c.Entries, "org/repo")
+}
+
+func TestLoadCache_MigratesUnversioned(t *testing.T) {
+	path := testutil.TempCacheFile(t, `{"entries": {"org/repo": {"profile": "work"}}}`)
+	c, err := cache.Load(path)
+	require.NoError(t, err)
+	assert.Equal(t, cache.CurrentVersion, c.Version)
+	assert.Equal(t, "work", c.Entries["org/repo"].Profile)
+}
+
+func TestLoadCache_FutureVersion(t *testing.T) {
+	content := `{"version": 99, "entries": {"org/repo": {"profile": "work"}}}`
+	path := testutil.TempCacheFile(t, content)
+
+	_, err := cache.Load(path)
+	require.Error(t, err)
+	assert.True(t, errors.Is(err, cache.ErrUnsupportedVersion))
+
+	// 새 버전 캐시는 덮어쓰지 않는다
+	err = cache.Update(path, func(c *cache.Cache) error {
+		c.Set("org/other", cache.Entry{Profile: "personal"})
+		return nil
+	})
+	require.Error(t, err)
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, content, string(data))
+}
+
+func TestMigrate(t *testing.T) {
+	content := `{"entries": {"org/repo": {"profile": "work"}}}`
+	path := testutil.TempCacheFile(t, content)
+
+	applied, backup, err := cache.Migrate(path)
+	require.NoError(t, err)
+	assert.Len(t, applied, 1)
+
+	data, err := os.ReadFile(backup)
+	require.NoError(t, err)
+	assert.Equal(t, content, string(data))
+
+	data, err = os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), `"version": 1`)
+
+	applied, _, err = cache.Migrate(path)
+	require.NoError(t, err)
+	assert.Empty(t, applied)
+}
@@ -0,0 +1,96 @@
+package cache
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/hbjs97/ctx/internal/fsutil"
+	"github.com/hbjs97/ctx/internal/migrate"
+)
+
+// CurrentVersion은 이 ctx가 읽고 쓰는 cache.json 스키마 버전이다.
+const CurrentVersion = 1
+
+// ErrUnsupportedVersion은 캐시 파일이 이 ctx보다 새로운 버전으로 기록되었음을 나타낸다.
+// 이 경우 Load는 파일을 건드리지 않고, Update도 덮어쓰지 않는다.
+var ErrUnsupportedVersion = errors.New("지원하지 않는 캐시 버전")
+
+// migrations는 cache.json의 버전별 마이그레이션이다.
+var migrations = []migrate.Step{
+	{
+		From:        0,
+		Description: "version 키가 없는 초기 캐시 파일을 version 1로 지정",
+		Apply:       func(map[string]any) error { return nil },
+	},
+}
+
+// migrateDocument는 JSON 문서의 version을 읽어 migrations를 적용한다.
+// 반환값은 config.migrateDocument와 같고, 새로운 버전은 ErrUnsupportedVersion으로 거부한다.
+func migrateDocument(doc map[string]any) ([]string, int, error) {
+	version := 0
+	if raw, ok := doc["version"]; ok {
+		n, ok := raw.(float64)
+		if !ok || n != float64(int(n)) {
+			return nil, 0, errors.New("version은 정수여야 합니다")
+		}
+		version = int(n)
+	}
+	if version > CurrentVersion {
+		return nil, version, fmt.Errorf("캐시 파일 version %d (지원: %d): %w", version, CurrentVersion, ErrUnsupportedVersion)
+	}
+
+	// encoding/json은 숫자를 float64로 디코딩하지만 다시 인코딩하므로 int로 기록해도 된다
+	applied, err := migrate.Run(doc, version, migrations, func(doc map[string]any, v int) { doc["version"] = v })
+	if err != nil {
+		return nil, version, err
+	}
+	return applied, version, nil
+}
+
+// Migrate는 캐시 파일을 CurrentVersion으로 올려 저장한다.
+// 원본은 "<path>.v<이전 버전>.bak"으로 백업하며, 파일이 없거나 이미 최신이면 아무것도 쓰지 않는다.
+func Migrate(path string) (applied []string, backup string, err error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return nil, "", fmt.Errorf("cache.Migrate: %w", err)
+	}
+	lock, err := fsutil.Lock(path)
+	if err != nil {
+		return nil, "", fmt.Errorf("cache.Migrate: %w", err)
+	}
+	defer lock.Unlock()
+
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, "", nil
+	}
+	if err != nil {
+		return nil, "", fmt.Errorf("cache.Migrate: %w", err)
+	}
+	var doc map[string]any
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, "", fmt.Errorf("cache.Migrate: %w", err)
+	}
+	applied, from, err := migrateDocument(doc)
+	if err != nil {
+		return nil, "", fmt.Errorf("cache.Migrate: %w", err)
+	}
+	if len(applied) == 0 {
+		return nil, "", nil
+	}
+
+	c, err := Load(path)
+	if err != nil {
+		return nil, "", fmt.Errorf("cache.Migrate: %w", err)
+	}
+	backup = fmt.Sprintf("%s.v%d.bak", path, from)
+	if err := fsutil.WriteFileAtomic(backup, data, 0600); err != nil {
+		return nil, "", fmt.Errorf("cache.Migrate: 백업 실패: %w", err)
+	}
+	if err := c.Save(path); err != nil {
+		return nil, "", fmt.Errorf("cache.Migrate: %w", err)
+	}
+	return applied, backup, nil
+}
@@ -6,7 +6,6 @@ import (
 	"path/filepath"
 	"strings"
 
-	"github.com/hbjs97/ctx/internal/config"
 	"github.com/hbjs97/ctx/internal/shell"
 	"github.com/spf13/cobra"
 )
@@ -38,7 +37,7 @@ func (a *App) runActivate(shellType string) error {
 		return fmt.Errorf("cli.activate: %w", err)
 	}
 
-	cfg, err := config.Load(a.CfgPath)
+	cfg, err := a.loadConfig()
 	if err != nil {
 		// config 로드 실패 시 deactivate
 		fmt.Print(shell.Deactivate(shellType))
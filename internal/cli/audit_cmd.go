@@ -0,0 +1,293 @@
+package cli
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/hbjs97/ctx/internal/audit"
+	"github.com/hbjs97/ctx/internal/git"
+	"github.com/hbjs97/ctx/internal/guard"
+	"github.com/spf13/cobra"
+)
+
+func (a *App) newAuditCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "audit",
+		Short: "guard 통과/차단/우회와 프로필 판정 감사 로그를 조회한다",
+		Long: `guard 통과/차단/우회와 프로필 판정 감사 로그를 조회한다.
+
+로그 위치: $XDG_STATE_HOME/ctx/audit.log (기본 ~/.local/state/ctx/audit.log)
+이벤트: guard_pass, guard_block, guard_bypass, guard_allow, resolve`,
+	}
+	cmd.AddCommand(a.newAuditListCmd(), a.newAuditTailCmd(), a.newAuditExportCmd())
+	return cmd
+}
+
+// auditFilterFlags는 audit 하위 명령이 공유하는 필터 플래그다.
+type auditFilterFlags struct {
+	event, profile, repo, since string
+}
+
+func (f *auditFilterFlags) register(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&f.event, "event", "", "이벤트 (guard_pass, guard_block, guard_bypass, guard_allow, resolve)")
+	cmd.Flags().StringVar(&f.profile, "profile", "", "프로필 이름")
+	cmd.Flags().StringVar(&f.repo, "repo", "", "owner/repo 또는 경로의 일부")
+	cmd.Flags().StringVar(&f.since, "since", "", "이 시각 이후 (예: 24h, 7d, 2026-01-02)")
+}
+
+func (f *auditFilterFlags) filter(now time.Time) (audit.Filter, error) {
+	filter := audit.Filter{Event: f.event, Profile: f.profile, Repo: f.repo}
+	if f.since != "" {
+		since, err := parseSince(f.since, now)
+		if err != nil {
+			return filter, fmt.Errorf("cli.audit: --since %q: %w", f.since, err)
+		}
+		filter.Since = since
+	}
+	return filter, nil
+}
+
+// parseSince는 "24h" 같은 duration, "7d" 같은 일 수, 날짜(2006-01-02) 또는 RFC3339 시각을 해석한다.
+func parseSince(s string, now time.Time) (time.Time, error) {
+	if days, ok := strings.CutSuffix(s, "d"); ok {
+		if n, err := strconv.Atoi(days); err == nil {
+			return now.AddDate(0, 0, -n), nil
+		}
+	}
+	if d, err := time.ParseDuration(s); err == nil {
+		return now.Add(-d), nil
+	}
+	if t, err := time.ParseInLocation("2006-01-02", s, time.Local); err == nil {
+		return t, nil
+	}
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t, nil
+	}
+	return time.Time{}, fmt.Errorf("duration(24h, 7d), 날짜(2006-01-02) 또는 RFC3339 시각 필요")
+}
+
+func (a *App) newAuditListCmd() *cobra.Command {
+	var flags auditFilterFlags
+	var limit int
+	cmd := &cobra.Command{
+		Use:   "list",
+		Short: "감사 기록을 오래된 순으로 출력한다",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			records, err := a.readAudit(&flags)
+			if err != nil {
+				return err
+			}
+			if limit > 0 && len(records) > limit {
+				records = records[len(records)-limit:]
+			}
+			for _, r := range records {
+				fmt.Fprintln(cmd.OutOrStdout(), formatAuditRecord(r))
+			}
+			return nil
+		},
+	}
+	flags.register(cmd)
+	cmd.Flags().IntVar(&limit, "limit", 0, "최근 N개만 출력 (0이면 전부)")
+	return cmd
+}
+
+func (a *App) newAuditTailCmd() *cobra.Command {
+	var flags auditFilterFlags
+	var lines int
+	var follow bool
+	cmd := &cobra.Command{
+		Use:   "tail",
+		Short: "최근 감사 기록을 출력한다 (-f로 계속 출력)",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			records, err := a.readAudit(&flags)
+			if err != nil {
+				return err
+			}
+			if len(records) > lines {
+				records = records[len(records)-lines:]
+			}
+			for _, r := range records {
+				fmt.Fprintln(cmd.OutOrStdout(), formatAuditRecord(r))
+			}
+			if !follow {
+				return nil
+			}
+			return a.followAudit(cmd.Context(), cmd.OutOrStdout(), &flags, records)
+		},
+	}
+	flags.register(cmd)
+	cmd.Flags().IntVarP(&lines, "lines", "n", 20, "출력할 기록 수")
+	cmd.Flags().BoolVarP(&follow, "follow", "f", false, "새 기록을 계속 출력 (Ctrl-C로 종료)")
+	return cmd
+}
+
+// followAudit는 1초마다 로그를 다시 읽어 seen 이후에 추가된 기록을 출력한다.
+func (a *App) followAudit(ctx context.Context, w io.Writer, flags *auditFilterFlags, seen []audit.Record) error {
+	var last time.Time
+	if len(seen) > 0 {
+		last = seen[len(seen)-1].Time
+	}
+	ticker := time.NewTicker(time.Second)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+		records, err := a.readAudit(flags)
+		if err != nil {
+			return err
+		}
+		for _, r := range records {
+			if r.Time.After(last) {
+				fmt.Fprintln(w, formatAuditRecord(r))
+				last = r.Time
+			}
+		}
+	}
+}
+
+func (a *App) newAuditExportCmd() *cobra.Command {
+	var flags auditFilterFlags
+	var format string
+	cmd := &cobra.Command{
+		Use:   "export",
+		Short: "감사 기록을 JSONL 또는 CSV로 stdout에 내보낸다",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			records, err := a.readAudit(&flags)
+			if err != nil {
+				return err
+			}
+			switch format {
+			case "jsonl":
+				enc := json.NewEncoder(cmd.OutOrStdout())
+				for _, r := range records {
+					if err := enc.Encode(r); err != nil {
+						return fmt.Errorf("cli.audit: %w", err)
+					}
+				}
+				return nil
+			case "csv":
+				return writeAuditCSV(cmd.OutOrStdout(), records)
+			default:
+				return fmt.Errorf("cli.audit: 알 수 없는 형식 %q (jsonl, csv)", format)
+			}
+		},
+	}
+	flags.register(cmd)
+	cmd.Flags().StringVar(&format, "format", "jsonl", "출력 형식 (jsonl, csv)")
+	return cmd
+}
+
+func writeAuditCSV(w io.Writer, records []audit.Record) error {
+	cw := csv.NewWriter(w)
+	_ = cw.Write([]string{"time", "event", "profile", "repo", "dir", "remote", "reason", "violations", "stage"}) // 에러는 Flush 후 cw.Error로 확인
+	for _, r := range records {
+		_ = cw.Write([]string{ // 에러는 Flush 후 cw.Error로 확인
+			r.Time.Format(time.RFC3339), r.Event, r.Profile, r.Repo, r.Dir, r.Remote, r.Reason, formatAuditViolations(r.Violations), r.Stage,
+		})
+	}
+	cw.Flush()
+	if err := cw.Error(); err != nil {
+		return fmt.Errorf("cli.audit: %w", err)
+	}
+	return nil
+}
+
+func (a *App) readAudit(flags *auditFilterFlags) ([]audit.Record, error) {
+	filter, err := flags.filter(time.Now())
+	if err != nil {
+		return nil, err
+	}
+	records, err := audit.New(a.auditPath()).Read(filter)
+	if err != nil {
+		return nil, fmt.Errorf("cli.audit: %w", err)
+	}
+	return records, nil
+}
+
+func formatAuditRecord(r audit.Record) string {
+	repo := r.Repo
+	if repo == "" {
+		repo = r.Dir
+	}
+	event := r.Event
+	if r.Stage == guard.StageCommit {
+		event += "(commit)"
+	}
+	s := fmt.Sprintf("%s  %-12s  %-10s  %s", r.Time.Local().Format("2006-01-02 15:04:05"), event, r.Profile, repo)
+	if r.Reason != "" {
+		s += "  " + r.Reason
+	}
+	if len(r.Violations) > 0 {
+		s += "  " + formatAuditViolations(r.Violations)
+	}
+	return s
+}
+
+func formatAuditViolations(vs []audit.Violation) string {
+	parts := make([]string, len(vs))
+	for i, v := range vs {
+		parts[i] = fmt.Sprintf("[%s] %s: 기대=%s, 실제=%s", v.Severity, v.Field, v.Expected, v.Actual)
+		if v.Rule != "" {
+			parts[i] += " (규칙: " + v.Rule + ")"
+		}
+	}
+	return strings.Join(parts, "; ")
+}
+
+func (a *App) auditPath() string {
+	if a.AuditPath != "" {
+		return a.AuditPath
+	}
+	return audit.DefaultPath()
+}
+
+// recordAudit는 감사 로그에 기록을 남긴다. 토큰은 MaskTokens로 가린다.
+// 기록 실패는 명령을 막지 않고 경고만 출력한다.
+func (a *App) recordAudit(r audit.Record) {
+	r.Repo, r.Dir, r.Remote, r.Reason = MaskTokens(r.Repo), MaskTokens(r.Dir), MaskTokens(r.Remote), MaskTokens(r.Reason)
+	for i := range r.Violations {
+		r.Violations[i].Expected = MaskTokens(r.Violations[i].Expected)
+		r.Violations[i].Actual = MaskTokens(r.Violations[i].Actual)
+	}
+	if err := audit.New(a.auditPath()).Append(r); err != nil {
+		fmt.Fprintf(os.Stderr, "경고: 감사 로그 기록 실패: %v\n", err)
+	}
+}
+
+// recordGuard는 guard 검사 결과를 감사 로그에 남긴다.
+func (a *App) recordGuard(ctx context.Context, repoDir, profile, stage string, result *guard.CheckResult) {
+	r := audit.Record{Dir: repoDir, Profile: profile, Stage: stage, Remote: result.Remote}
+	switch {
+	case result.Skipped:
+		r.Event, r.Reason = audit.EventGuardBypass, "CTX_SKIP_GUARD=1"
+	case result.Allowance != nil:
+		r.Event, r.Reason = audit.EventGuardBypass, "ctx guard allow: "+result.Allowance.Reason
+	case result.Pass:
+		r.Event = audit.EventGuardPass
+	default:
+		r.Event = audit.EventGuardBlock
+	}
+	if r.Remote == "" {
+		r.Remote, _ = git.NewAdapter(a.Commander).GetRemoteURL(ctx, repoDir, "origin") // 우회 시에는 검사하지 않았으므로 기록용으로만 조회
+	}
+	if ref, err := git.ParseRepoURL(r.Remote); err == nil {
+		r.Repo = ref.Owner + "/" + ref.Repo
+	}
+	for _, v := range result.Violations {
+		r.Violations = append(r.Violations, audit.Violation{Field: v.Field, Expected: v.Expected, Actual: v.Actual, Severity: v.Severity, Rule: v.Rule})
+	}
+	a.recordAudit(r)
+}
@@ -43,6 +43,7 @@ func TestMapExitCode(t *testing.T) {
 		{"nil error", nil, cli.ExitSuccess},
 		{"guard block", cli.ErrGuardBlock, cli.ExitGuardBlock},
 		{"wrapped guard", fmt.Errorf("wrap: %w", cli.ErrGuardBlock), cli.ExitGuardBlock},
+		{"commit block", fmt.Errorf("wrap: %w", cli.ErrCommitBlock), cli.ExitGuardBlock},
 		{"ambiguous", cli.ErrAmbiguous, cli.ExitAmbiguous},
 		{"wrapped ambiguous", fmt.Errorf("resolver: %w", cli.ErrAmbiguous), cli.ExitAmbiguous},
 		{"auth fail", cli.ErrAuthFail, cli.ExitAuthFail},
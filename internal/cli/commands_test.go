@@ -2,12 +2,19 @@ package cli_test
 
 import (
 	"bytes"
+	"encoding/json"
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
+	"time"
 
+	"github.com/hbjs97/ctx/internal/audit"
+	"github.com/hbjs97/ctx/internal/cache"
 	"github.com/hbjs97/ctx/internal/cli"
+	"github.com/hbjs97/ctx/internal/cmdexec"
+	"github.com/hbjs97/ctx/internal/config"
 	"github.com/hbjs97/ctx/internal/testutil"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -95,6 +102,13 @@ func newTestApp(t *testing.T, fc *testutil.FakeCommander, cfgPath string) *cli.A
 	return &cli.App{
 		Commander: fc,
 		CfgPath:   cfgPath,
+		// 호스트의 /etc/ctx/policy.toml에 영향받지 않도록 없는 경로를 쓴다
+		PolicyPath: filepath.Join(t.TempDir(), "policy.toml"),
+		AuditPath:  filepath.Join(t.TempDir(), "audit.log"),
+		// 설정 변경 후 동기화가 실제 ~/.ssh/config, ~/.gitconfig를 건드리지 않도록 한다
+		SSHConfigPath: filepath.Join(t.TempDir(), "ssh", "config"),
+		GitConfigPath: filepath.Join(t.TempDir(), "gitconfig"),
+		GitIncludeDir: filepath.Join(t.TempDir(), "git"),
 	}
 }
 
@@ -309,6 +323,12 @@ func TestInitCmd_Success(t *testing.T) {
 	data, err := os.ReadFile(profilePath)
 	require.NoError(t, err)
 	assert.Contains(t, string(data), "work")
+
+	records, err := audit.New(app.AuditPath).Read(audit.Filter{Event: audit.EventResolve})
+	require.NoError(t, err)
+	require.Len(t, records, 1)
+	assert.Equal(t, "myorg/myrepo", records[0].Repo)
+	assert.Equal(t, "work", records[0].Profile)
 }
 
 func TestInitCmd_ExplicitProfile(t *testing.T) {
@@ -1122,3 +1142,837 @@ func TestCloneCmd_HTTPSUrlFormat(t *testing.T) {
 	require.NoError(t, err)
 	assert.True(t, fc.Called("git clone"))
 }
+
+func TestDoctorCmd_CheckByName(t *testing.T) {
+	t.Parallel()
+
+	cfgDir := t.TempDir()
+	cfgPath := writeTestConfig(t, cfgDir)
+
+	fc := testutil.NewFakeCommander()
+	fc.Register("ssh -G", "identitiesonly yes\n", nil)
+
+	app := newTestApp(t, fc, cfgPath)
+	cmd := app.NewRootCmd()
+	cmd.SetArgs([]string{"--config", cfgPath, "doctor", "--check", "identities_only"})
+
+	require.NoError(t, cmd.Execute())
+	assert.True(t, fc.Called("ssh -G gh-work"))
+	assert.True(t, fc.Called("ssh -G gh-personal"))
+	assert.False(t, fc.Called("git --version"), "선택하지 않은 진단은 실행하지 않는다")
+}
+
+func TestDoctorCmd_UnknownCheck(t *testing.T) {
+	t.Parallel()
+
+	cfgDir := t.TempDir()
+	cfgPath := writeTestConfig(t, cfgDir)
+
+	app := newTestApp(t, testutil.NewFakeCommander(), cfgPath)
+	cmd := app.NewRootCmd()
+	cmd.SetArgs([]string{"--config", cfgPath, "doctor", "--check", "bogus"})
+
+	assert.Error(t, cmd.Execute())
+}
+
+func TestDoctorCmd_FixYes(t *testing.T) {
+	t.Parallel()
+
+	cfgDir := t.TempDir()
+	cfgPath := writeTestConfig(t, cfgDir)
+	require.NoError(t, os.Chmod(cfgPath, 0644))
+
+	app := newTestApp(t, testutil.NewFakeCommander(), cfgPath)
+	cmd := app.NewRootCmd()
+	cmd.SetArgs([]string{"--config", cfgPath, "doctor", "--check", "config_file", "--fix", "--yes"})
+
+	require.NoError(t, cmd.Execute())
+	info, err := os.Stat(cfgPath)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+}
+
+func TestDoctorCmd_Repo(t *testing.T) {
+	t.Parallel()
+
+	cfgDir := t.TempDir()
+	cfgPath := writeTestConfig(t, cfgDir)
+	root := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(root, ".git"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(root, ".git", "ctx-profile"), []byte("work\n"), 0600))
+
+	cwd, err := os.Getwd()
+	require.NoError(t, err)
+
+	fc := testutil.NewFakeCommander()
+	fc.Register("git -C", "", fmt.Errorf("exit 1"))
+	fc.Register("git -C "+cwd+" rev-parse --show-toplevel", root+"\n", nil)
+	fc.Register("git -C "+root+" remote get-url origin", "git@gh-work:myorg/api.git\n", nil)
+
+	app := newTestApp(t, fc, cfgPath)
+	cmd := app.NewRootCmd()
+	cmd.SetArgs([]string{"--config", cfgPath, "doctor", "--repo"})
+
+	require.NoError(t, cmd.Execute())
+	assert.True(t, fc.Called("git -C "+root+" config --get core.hooksPath"))
+	assert.True(t, fc.Called("git -C "+root+" config --show-scope --show-origin --get-all user.email"))
+	assert.False(t, fc.Called("ssh -T"), "--repo는 환경/프로필 진단을 실행하지 않는다")
+}
+
+func TestConfigValidateCmd(t *testing.T) {
+	t.Parallel()
+
+	cfgDir := t.TempDir()
+	cfgPath := writeTestConfig(t, cfgDir)
+
+	app := newTestApp(t, testutil.NewFakeCommander(), cfgPath)
+	cmd := app.NewRootCmd()
+	cmd.SetArgs([]string{"--config", cfgPath, "config", "validate"})
+	require.NoError(t, cmd.Execute())
+
+	dupPath := filepath.Join(t.TempDir(), "config.toml")
+	require.NoError(t, os.WriteFile(dupPath, []byte(`version = 1
+[profiles.work]
+gh_config_dir = "/tmp/gh-work"
+ssh_host = "gh-shared"
+git_name = "Work"
+git_email = "w@work.com"
+
+[profiles.personal]
+gh_config_dir = "/tmp/gh-personal"
+ssh_host = "gh-shared"
+git_name = "Me"
+git_email = "me@personal.com"
+`), 0600))
+	cmd = app.NewRootCmd()
+	cmd.SetArgs([]string{"--config", dupPath, "config", "validate"})
+	err := cmd.Execute()
+	require.Error(t, err)
+	assert.Equal(t, cli.ExitConfigError, cli.MapExitCode(err))
+}
+
+func TestConfigSetGetCmd(t *testing.T) {
+	t.Parallel()
+
+	cfgPath := writeTestConfig(t, t.TempDir())
+	app := newTestApp(t, testutil.NewFakeCommander(), cfgPath)
+
+	cmd := app.NewRootCmd()
+	cmd.SetArgs([]string{"--config", cfgPath, "config", "set", "profiles.work.owners", "myorg,otherorg"})
+	require.NoError(t, cmd.Execute())
+	cmd = app.NewRootCmd()
+	cmd.SetArgs([]string{"--config", cfgPath, "config", "set", "cache_ttl_days", "30"})
+	require.NoError(t, cmd.Execute())
+
+	cfg, err := config.Load(cfgPath)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"myorg", "otherorg"}, cfg.Profiles["work"].Owners)
+	assert.Equal(t, 30, cfg.CacheTTLDays)
+
+	info, err := os.Stat(cfgPath)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+
+	cmd = app.NewRootCmd()
+	cmd.SetArgs([]string{"--config", cfgPath, "config", "get", "no_such_key"})
+	err = cmd.Execute()
+	require.Error(t, err)
+	assert.Equal(t, cli.ExitConfigError, cli.MapExitCode(err))
+}
+
+func TestConfigSetCmd_RejectsInvalidResult(t *testing.T) {
+	t.Parallel()
+
+	cfgPath := writeTestConfig(t, t.TempDir())
+	before, err := os.ReadFile(cfgPath)
+	require.NoError(t, err)
+
+	app := newTestApp(t, testutil.NewFakeCommander(), cfgPath)
+	cmd := app.NewRootCmd()
+	// personal과 같은 ssh_host는 error 수준 충돌이다
+	cmd.SetArgs([]string{"--config", cfgPath, "config", "set", "profiles.work.ssh_host", "gh-personal"})
+	err = cmd.Execute()
+	require.Error(t, err)
+	assert.Equal(t, cli.ExitConfigError, cli.MapExitCode(err))
+
+	after, err := os.ReadFile(cfgPath)
+	require.NoError(t, err)
+	assert.Equal(t, string(before), string(after))
+}
+
+func TestConfigUnsetCmd_RemovesProfile(t *testing.T) {
+	t.Parallel()
+
+	cfgPath := writeTestConfig(t, t.TempDir())
+	app := newTestApp(t, testutil.NewFakeCommander(), cfgPath)
+	cmd := app.NewRootCmd()
+	cmd.SetArgs([]string{"--config", cfgPath, "config", "unset", "profiles.personal"})
+	require.NoError(t, cmd.Execute())
+
+	cfg, err := config.Load(cfgPath)
+	require.NoError(t, err)
+	assert.NotContains(t, cfg.Profiles, "personal")
+	assert.Contains(t, cfg.Profiles, "work")
+}
+
+func TestConfigEditCmd(t *testing.T) {
+	dir := t.TempDir()
+	cfgPath := writeTestConfig(t, dir)
+
+	// 편집기 대신 준비된 내용으로 덮어쓰는 스크립트를 사용한다
+	replacement := filepath.Join(dir, "edited.toml")
+	editor := filepath.Join(dir, "editor.sh")
+	require.NoError(t, os.WriteFile(editor, []byte("#!/bin/sh\ncp "+replacement+" \"$1\"\n"), 0755))
+	t.Setenv("VISUAL", "")
+	t.Setenv("EDITOR", editor)
+
+	app := newTestApp(t, nil, cfgPath)
+	app.Commander = &cmdexec.RealCommander{}
+
+	t.Run("valid edit is saved", func(t *testing.T) {
+		require.NoError(t, os.WriteFile(replacement, []byte(`version = 1
+cache_ttl_days = 7
+
+[profiles.work]
+gh_config_dir = "/tmp/gh-work"
+ssh_host = "gh-work"
+git_name = "Test User"
+git_email = "test@work.com"
+owners = ["myorg"]
+`), 0600))
+
+		cmd := app.NewRootCmd()
+		cmd.SetArgs([]string{"--config", cfgPath, "config", "edit"})
+		require.NoError(t, cmd.Execute())
+
+		cfg, err := config.Load(cfgPath)
+		require.NoError(t, err)
+		assert.Equal(t, 7, cfg.CacheTTLDays)
+		assert.NotContains(t, cfg.Profiles, "personal")
+	})
+
+	t.Run("invalid edit is rejected", func(t *testing.T) {
+		before, err := os.ReadFile(cfgPath)
+		require.NoError(t, err)
+		require.NoError(t, os.WriteFile(replacement, []byte(`version = 1
+default_profile = "missing"
+
+[profiles.work]
+gh_config_dir = "/tmp/gh-work"
+ssh_host = "gh-work"
+git_name = "Test User"
+git_email = "test@work.com"
+`), 0600))
+
+		cmd := app.NewRootCmd()
+		cmd.SetArgs([]string{"--config", cfgPath, "config", "edit"})
+		// 다시 편집할지 묻는 프롬프트는 테스트에서 실행할 수 없으므로 에러로 끝난다
+		require.Error(t, cmd.Execute())
+
+		after, err := os.ReadFile(cfgPath)
+		require.NoError(t, err)
+		assert.Equal(t, string(before), string(after))
+
+		leftovers, err := filepath.Glob(filepath.Join(dir, "config.*.toml"))
+		require.NoError(t, err)
+		assert.Empty(t, leftovers, "temp copy must be removed")
+	})
+}
+
+func TestConfigMigrateCmd(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	cfgPath := filepath.Join(dir, "config.toml")
+	require.NoError(t, os.WriteFile(cfgPath, []byte(`[profiles.work]
+gh_config_dir = "/tmp/gh-work"
+ssh_host = "gh-work"
+git_name = "Test User"
+git_email = "test@work.com"
+`), 0600))
+
+	app := newTestApp(t, testutil.NewFakeCommander(), cfgPath)
+	cmd := app.NewRootCmd()
+	cmd.SetArgs([]string{"--config", cfgPath, "config", "migrate"})
+	require.NoError(t, cmd.Execute())
+
+	cfg, err := config.Load(cfgPath)
+	require.NoError(t, err)
+	assert.Equal(t, config.CurrentVersion, cfg.Version)
+	assert.FileExists(t, cfgPath+".v0.bak")
+
+	require.NoError(t, os.WriteFile(cfgPath, []byte("version = 99\n"), 0600))
+	cmd = app.NewRootCmd()
+	cmd.SetArgs([]string{"--config", cfgPath, "config", "migrate"})
+	err = cmd.Execute()
+	require.Error(t, err)
+	assert.Equal(t, cli.ExitConfigError, cli.MapExitCode(err))
+}
+
+func TestConfigSetCmd_RejectsPolicyLockedKey(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	cfgPath := writeTestConfig(t, dir)
+	app := newTestApp(t, testutil.NewFakeCommander(), cfgPath)
+	app.PolicyPath = filepath.Join(dir, "policy.toml")
+	require.NoError(t, os.WriteFile(app.PolicyPath, []byte(`require_push_guard = true
+cache_ttl_days = 30
+locked = ["require_push_guard"]
+`), 0644))
+
+	cmd := app.NewRootCmd()
+	cmd.SetArgs([]string{"--config", cfgPath, "config", "set", "require_push_guard", "false"})
+	err := cmd.Execute()
+	require.Error(t, err)
+	assert.Equal(t, cli.ExitConfigError, cli.MapExitCode(err))
+
+	cmd = app.NewRootCmd()
+	cmd.SetArgs([]string{"--config", cfgPath, "config", "set", "cache_ttl_days", "7"})
+	require.NoError(t, cmd.Execute(), "잠기지 않은 키는 사용자가 덮어쓸 수 있다")
+
+	cmd = app.NewRootCmd()
+	cmd.SetArgs([]string{"--config", cfgPath, "config", "list", "--show-origin"})
+	require.NoError(t, cmd.Execute())
+}
+
+func TestConfigSetCmd_RejectsProfileGuardUnderLockedGuard(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	cfgPath := writeTestConfig(t, dir)
+	app := newTestApp(t, testutil.NewFakeCommander(), cfgPath)
+	app.PolicyPath = filepath.Join(dir, "policy.toml")
+	require.NoError(t, os.WriteFile(app.PolicyPath, []byte(`locked = ["guard"]
+
+[guard]
+user_email = "block"
+`), 0644))
+
+	cmd := app.NewRootCmd()
+	cmd.SetArgs([]string{"--config", cfgPath, "config", "set", "profiles.work.guard.user_email", "off"})
+	err := cmd.Execute()
+	require.Error(t, err, "프로필 guard로 잠긴 검사를 끌 수 없다")
+	assert.Equal(t, cli.ExitConfigError, cli.MapExitCode(err))
+
+	cmd = app.NewRootCmd()
+	cmd.SetArgs([]string{"--config", cfgPath, "config", "set", "profiles.work.git_name", "New Name"})
+	require.NoError(t, cmd.Execute(), "잠기지 않은 프로필 키는 바꿀 수 있다")
+}
+
+func TestGuardCheckCmd_Fail_RepoHintOwner(t *testing.T) {
+	repoDir := testutil.TempGitRepoWithRemote(t, "git@gh-work:myorg/myrepo.git")
+	testutil.WriteCtxProfile(t, repoDir, "work")
+	require.NoError(t, os.WriteFile(filepath.Join(repoDir, config.RepoFileName), []byte("owner = \"otherorg\"\n"), 0644))
+
+	cfgPath := writeTestConfig(t, t.TempDir())
+	t.Chdir(repoDir)
+
+	fc := testutil.NewFakeCommander()
+	fc.Register("git -C "+repoDir+" remote get-url origin", "git@gh-work:myorg/myrepo.git", nil)
+	fc.Register("git -C "+repoDir+" config --local user.email", "test@work.com", nil)
+	fc.Register("git -C "+repoDir+" config --local user.name", "Test User", nil)
+
+	app := newTestApp(t, fc, cfgPath)
+	cmd := app.NewRootCmd()
+	cmd.SetArgs([]string{"--config", cfgPath, "guard", "check"})
+	err := cmd.Execute()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "guard")
+}
+
+func TestGuardCheckCmd_Fail_EmailRule(t *testing.T) {
+	repoDir := testutil.TempGitRepoWithRemote(t, "git@gh-work:myorg/myrepo.git")
+	testutil.WriteCtxProfile(t, repoDir, "work")
+
+	cfgPath := writeTestConfig(t, t.TempDir())
+	f, err := os.OpenFile(cfgPath, os.O_APPEND|os.O_WRONLY, 0600)
+	require.NoError(t, err)
+	_, err = f.WriteString("\n[[email_rules]]\nname = \"myorg-only\"\nowners = [\"myorg\"]\nallow = [\"*@myorg.com\"]\n")
+	require.NoError(t, err)
+	require.NoError(t, f.Close())
+
+	t.Chdir(repoDir)
+
+	fc := testutil.NewFakeCommander()
+	fc.Register("git -C "+repoDir+" remote get-url origin", "git@gh-work:myorg/myrepo.git", nil)
+	fc.Register("git -C "+repoDir+" config --local user.email", "test@work.com", nil)
+	fc.Register("git -C "+repoDir+" config --local user.name", "Test User", nil)
+
+	app := newTestApp(t, fc, cfgPath)
+	cmd := app.NewRootCmd()
+	cmd.SetArgs([]string{"--config", cfgPath, "guard", "check"})
+	err = cmd.Execute()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "guard")
+}
+
+func TestGuardCheckCmd_PrePushProtectedBranch(t *testing.T) {
+	repoDir := testutil.TempGitRepoWithRemote(t, "git@gh-work:myorg/myrepo.git")
+	testutil.WriteCtxProfile(t, repoDir, "work")
+
+	cfgPath := writeTestConfig(t, t.TempDir())
+	t.Chdir(repoDir)
+
+	fc := testutil.NewFakeCommander()
+	fc.Register("git -C "+repoDir+" config --local user.email", "test@work.com", nil)
+	fc.Register("git -C "+repoDir+" config --local user.name", "Test User", nil)
+
+	app := newTestApp(t, fc, cfgPath)
+	cmd := app.NewRootCmd()
+	cmd.SetArgs([]string{"--config", cfgPath, "config", "set", "profiles.work.protected_branches", "main"})
+	require.NoError(t, cmd.Execute())
+
+	const sha = "2222222222222222222222222222222222222222"
+	run := func(remoteRef string) error {
+		cmd := app.NewRootCmd()
+		cmd.SetIn(bytes.NewBufferString("refs/heads/x " + sha + " " + remoteRef + " 0000000000000000000000000000000000000000\n"))
+		cmd.SetArgs([]string{"--config", cfgPath, "guard", "check", "--pre-push", "origin", "git@gh-work:myorg/myrepo.git"})
+		return cmd.Execute()
+	}
+	require.NoError(t, run("refs/heads/feature"))
+
+	err := run("refs/heads/main")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "guard")
+}
+
+// --- Audit command tests ---
+
+func TestGuardCheckCmd_RecordsAudit(t *testing.T) {
+	repoDir := testutil.TempGitRepoWithRemote(t, "git@gh-work:myorg/myrepo.git")
+	testutil.WriteCtxProfile(t, repoDir, "work")
+
+	cfgPath := writeTestConfig(t, t.TempDir())
+	t.Chdir(repoDir)
+
+	fc := testutil.NewFakeCommander()
+	fc.Register("git -C "+repoDir+" remote get-url origin", "git@gh-work:myorg/myrepo.git", nil)
+	fc.Register("git -C "+repoDir+" config --local user.email", "wrong@other.com", nil)
+	fc.Register("git -C "+repoDir+" config --local user.name", "Test User", nil)
+
+	app := newTestApp(t, fc, cfgPath)
+	cmd := app.NewRootCmd()
+	cmd.SetArgs([]string{"--config", cfgPath, "guard", "check"})
+	require.Error(t, cmd.Execute())
+
+	t.Setenv("CTX_SKIP_GUARD", "1")
+	cmd = app.NewRootCmd()
+	cmd.SetIn(bytes.NewBufferString(""))
+	cmd.SetArgs([]string{"--config", cfgPath, "guard", "check", "--pre-push", "origin", "https://ghp_secret123@github.com/myorg/myrepo.git"})
+	require.NoError(t, cmd.Execute())
+
+	records, err := audit.New(app.AuditPath).Read(audit.Filter{})
+	require.NoError(t, err)
+	require.Len(t, records, 2)
+
+	assert.Equal(t, audit.EventGuardBlock, records[0].Event)
+	assert.Equal(t, "myorg/myrepo", records[0].Repo)
+	assert.Equal(t, "work", records[0].Profile)
+	require.NotEmpty(t, records[0].Violations)
+	assert.Equal(t, "user_email", records[0].Violations[0].Field)
+
+	assert.Equal(t, audit.EventGuardBypass, records[1].Event)
+	assert.Equal(t, "CTX_SKIP_GUARD=1", records[1].Reason)
+	assert.NotContains(t, records[1].Remote, "secret123", "토큰은 마스킹해야 함")
+}
+
+func TestAuditCmd_ListAndExport(t *testing.T) {
+	cfgPath := writeTestConfig(t, t.TempDir())
+	app := newTestApp(t, testutil.NewFakeCommander(), cfgPath)
+
+	log := audit.New(app.AuditPath)
+	require.NoError(t, log.Append(audit.Record{Time: time.Now().AddDate(0, 0, -10), Event: audit.EventResolve, Repo: "myorg/old", Profile: "work", Reason: "owner_rule"}))
+	require.NoError(t, log.Append(audit.Record{Event: audit.EventResolve, Repo: "myorg/api", Profile: "work", Reason: "owner_rule"}))
+	require.NoError(t, log.Append(audit.Record{Event: audit.EventGuardPass, Repo: "me/dotfiles", Profile: "personal"}))
+
+	run := func(args ...string) string {
+		t.Helper()
+		out := new(bytes.Buffer)
+		cmd := app.NewRootCmd()
+		cmd.SetOut(out)
+		cmd.SetArgs(append([]string{"--config", cfgPath, "audit"}, args...))
+		require.NoError(t, cmd.Execute())
+		return out.String()
+	}
+
+	out := run("list", "--profile", "work", "--since", "7d")
+	assert.Contains(t, out, "myorg/api")
+	assert.NotContains(t, out, "myorg/old")
+	assert.NotContains(t, out, "me/dotfiles")
+
+	out = run("tail", "-n", "1")
+	assert.Contains(t, out, "me/dotfiles")
+	assert.NotContains(t, out, "myorg/api")
+
+	out = run("export", "--event", "resolve")
+	lines := strings.Split(strings.TrimSpace(out), "\n")
+	require.Len(t, lines, 2)
+	var r audit.Record
+	require.NoError(t, json.Unmarshal([]byte(lines[1]), &r))
+	assert.Equal(t, "myorg/api", r.Repo)
+
+	out = run("export", "--format", "csv", "--repo", "dotfiles")
+	assert.True(t, strings.HasPrefix(out, "time,event,profile,repo"))
+	assert.Contains(t, out, "guard_pass,personal,me/dotfiles")
+
+	cmd := app.NewRootCmd()
+	cmd.SetArgs([]string{"--config", cfgPath, "audit", "list", "--since", "yesterday"})
+	assert.Error(t, cmd.Execute())
+}
+
+func TestGuardAllowCmd(t *testing.T) {
+	repoDir := testutil.TempGitRepoWithRemote(t, "git@gh-work:myorg/myrepo.git")
+	testutil.WriteCtxProfile(t, repoDir, "work")
+
+	cfgPath := writeTestConfig(t, t.TempDir())
+	t.Chdir(repoDir)
+
+	fc := testutil.NewFakeCommander()
+	fc.Register("git -C "+repoDir+" remote get-url origin", "git@gh-work:myorg/myrepo.git", nil)
+	fc.Register("git -C "+repoDir+" config --local user.email", "wrong@other.com", nil)
+	fc.Register("git -C "+repoDir+" config --local user.name", "Test User", nil)
+
+	app := newTestApp(t, fc, cfgPath)
+	run := func(args ...string) error {
+		cmd := app.NewRootCmd()
+		cmd.SetArgs(append([]string{"--config", cfgPath, "guard"}, args...))
+		return cmd.Execute()
+	}
+
+	assert.Error(t, run("allow", "--once"), "사유 없이는 허용하지 않음")
+	assert.Error(t, run("allow", "--reason", "hotfix"), "--once 또는 --for 필요")
+	assert.Error(t, run("allow", "--for", "48h", "--reason", "hotfix"))
+
+	require.NoError(t, run("allow", "--once", "--reason", "INC-42 hotfix"))
+	require.NoError(t, run("check"))
+	assert.Error(t, run("check"), "--once 토큰은 한 번만 허용")
+
+	records, err := audit.New(app.AuditPath).Read(audit.Filter{})
+	require.NoError(t, err)
+	require.Len(t, records, 3)
+	assert.Equal(t, audit.EventGuardAllow, records[0].Event)
+	assert.Equal(t, "INC-42 hotfix", records[0].Reason)
+	assert.Equal(t, "myorg/myrepo", records[0].Repo)
+	assert.Equal(t, audit.EventGuardBypass, records[1].Event)
+	assert.Equal(t, "ctx guard allow: INC-42 hotfix", records[1].Reason)
+	assert.NotEmpty(t, records[1].Violations)
+	assert.Equal(t, audit.EventGuardBlock, records[2].Event)
+}
+
+func TestGuardCheckCmd_PolicyDisablesEnvBypass(t *testing.T) {
+	repoDir := testutil.TempGitRepoWithRemote(t, "git@gh-work:myorg/myrepo.git")
+	testutil.WriteCtxProfile(t, repoDir, "work")
+
+	cfgPath := writeTestConfig(t, t.TempDir())
+	t.Chdir(repoDir)
+	t.Setenv("CTX_SKIP_GUARD", "1")
+
+	fc := testutil.NewFakeCommander()
+	fc.Register("git -C "+repoDir+" remote get-url origin", "git@gh-work:myorg/myrepo.git", nil)
+	fc.Register("git -C "+repoDir+" config --local user.email", "wrong@other.com", nil)
+	fc.Register("git -C "+repoDir+" config --local user.name", "Test User", nil)
+
+	app := newTestApp(t, fc, cfgPath)
+	cmd := app.NewRootCmd()
+	cmd.SetArgs([]string{"--config", cfgPath, "guard", "check"})
+	require.NoError(t, cmd.Execute())
+
+	require.NoError(t, os.WriteFile(app.PolicyPath, []byte("allow_env_bypass = false\nlocked = [\"allow_env_bypass\"]\n"), 0644))
+	cmd = app.NewRootCmd()
+	cmd.SetArgs([]string{"--config", cfgPath, "guard", "check"})
+	err := cmd.Execute()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "guard")
+}
+
+func TestGuardCheckCmd_StageCommit(t *testing.T) {
+	repoDir := testutil.TempGitRepoWithRemote(t, "git@gh-work:myorg/myrepo.git")
+	testutil.WriteCtxProfile(t, repoDir, "work")
+
+	cfgPath := writeTestConfig(t, t.TempDir())
+	t.Chdir(repoDir)
+
+	fc := testutil.NewFakeCommander()
+	fc.Register("git -C "+repoDir+" config user.email", "wrong@other.com", nil)
+	fc.Register("git -C "+repoDir+" config user.name", "Test User", nil)
+
+	app := newTestApp(t, fc, cfgPath)
+	cmd := app.NewRootCmd()
+	cmd.SetArgs([]string{"--config", cfgPath, "guard", "check", "--stage", "commit"})
+	err := cmd.Execute()
+	require.Error(t, err)
+	assert.ErrorIs(t, err, cli.ErrCommitBlock)
+	assert.Equal(t, cli.ExitGuardBlock, cli.MapExitCode(err))
+
+	records, err := audit.New(app.AuditPath).Read(audit.Filter{})
+	require.NoError(t, err)
+	require.Len(t, records, 1)
+	assert.Equal(t, audit.EventGuardBlock, records[0].Event)
+	assert.Equal(t, "commit", records[0].Stage)
+
+	cmd = app.NewRootCmd()
+	cmd.SetArgs([]string{"--config", cfgPath, "guard", "check", "--stage", "commit", "--pre-push"})
+	assert.Error(t, cmd.Execute())
+
+	cmd = app.NewRootCmd()
+	cmd.SetArgs([]string{"--config", cfgPath, "guard", "check", "--stage", "merge"})
+	assert.Error(t, cmd.Execute())
+}
+
+func TestInitCmd_InstallsCommitHook(t *testing.T) {
+	repoDir := testutil.TempGitRepoWithRemote(t, "git@gh-work:myorg/myrepo.git")
+	cfgPath := writeTestConfig(t, t.TempDir())
+	t.Chdir(repoDir)
+
+	fc := testutil.NewFakeCommander()
+	fc.Register("git -C "+repoDir+" remote get-url origin", "git@gh-work:myorg/myrepo.git", nil)
+	fc.Register("git -C "+repoDir+" config --local", "", nil)
+
+	app := newTestApp(t, fc, cfgPath)
+	run := func(args ...string) {
+		cmd := app.NewRootCmd()
+		cmd.SetArgs(append([]string{"--config", cfgPath}, args...))
+		require.NoError(t, cmd.Execute())
+	}
+
+	run("init")
+	assert.NoFileExists(t, filepath.Join(repoDir, ".git", "hooks", "pre-commit"), "기본값은 pre-commit guard 없음")
+
+	run("config", "set", "require_commit_guard", "true")
+	run("init")
+	data, err := os.ReadFile(filepath.Join(repoDir, ".git", "hooks", "pre-commit"))
+	require.NoError(t, err)
+	assert.Contains(t, string(data), "ctx guard check --stage commit")
+
+	run("config", "set", "require_commit_guard", "false")
+	run("init")
+	assert.NoFileExists(t, filepath.Join(repoDir, ".git", "hooks", "pre-commit"), "설정을 끄고 다시 init하면 pre-commit guard 제거")
+}
+
+func TestGuardFixCmd(t *testing.T) {
+	repoDir := testutil.TempGitRepoWithRemote(t, "https://github.com/myorg/myrepo.git")
+	testutil.WriteCtxProfile(t, repoDir, "work")
+
+	cfgPath := writeTestConfig(t, t.TempDir())
+	t.Chdir(repoDir)
+
+	fc := testutil.NewFakeCommander()
+	fc.Register("git -C "+repoDir+" remote get-url origin", "https://github.com/myorg/myrepo.git", nil)
+	fc.Register("git -C "+repoDir+" config --local user.name", "Test User", nil)
+	fc.Register("git -C "+repoDir+" config --local user.email", "wrong@other.com", nil)
+	fc.Register("git -C "+repoDir+" config --local user.email test@work.com", "", nil)
+	fc.Register("git -C "+repoDir+" remote set-url origin", "", nil)
+
+	app := newTestApp(t, fc, cfgPath)
+	cmd := app.NewRootCmd()
+	cmd.SetArgs([]string{"--config", cfgPath, "guard", "fix", "--yes"})
+	require.NoError(t, cmd.Execute())
+
+	assert.Contains(t, fc.Calls, "git -C "+repoDir+" config --local user.email test@work.com")
+	assert.Contains(t, fc.Calls, "git -C "+repoDir+" remote set-url origin git@gh-work:myorg/myrepo.git")
+	assert.NotContains(t, fc.Calls, "git -C "+repoDir+" config --local user.name Test User", "맞는 값은 다시 쓰지 않음")
+}
+
+func TestGuardFixCmd_RewriteRefusesPushedCommits(t *testing.T) {
+	repoDir := testutil.TempGitRepoWithRemote(t, "git@gh-work:myorg/myrepo.git")
+	testutil.WriteCtxProfile(t, repoDir, "work")
+
+	cfgPath := writeTestConfig(t, t.TempDir())
+	t.Chdir(repoDir)
+
+	const sha = "1111111111111111111111111111111111111111"
+	line := sha + "\x1f\x1fMe\x1fme@personal.com\x1fMe\x1fme@personal.com\x1fwip\n"
+	fc := testutil.NewFakeCommander()
+	fc.Register("git -C "+repoDir+" remote get-url origin", "git@gh-work:myorg/myrepo.git", nil)
+	fc.Register("git -C "+repoDir+" config --local user.name", "Test User", nil)
+	fc.Register("git -C "+repoDir+" config --local user.email", "test@work.com", nil)
+	fc.Register("git -C "+repoDir+" rev-parse --abbrev-ref --symbolic-full-name @{upstream}", "origin/main", nil)
+	fc.Register("git -C "+repoDir+" log --reverse --format=%H%x1f%P%x1f%an%x1f%ae%x1f%cn%x1f%ce%x1f%s origin/main..HEAD", line, nil)
+	fc.Register("git -C "+repoDir+" log --reverse --format=%H%x1f%P%x1f%an%x1f%ae%x1f%cn%x1f%ce%x1f%s HEAD --not --remotes", "", nil)
+
+	app := newTestApp(t, fc, cfgPath)
+	cmd := app.NewRootCmd()
+	cmd.SetArgs([]string{"--config", cfgPath, "guard", "fix", "--rewrite", "--yes"})
+	err := cmd.Execute()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "push된 커밋")
+	for _, c := range fc.Calls {
+		assert.NotContains(t, c, "rebase", "거부하면 아무것도 실행하지 않음")
+	}
+}
+
+func TestProfileRefreshCmd(t *testing.T) {
+	cfgPath := writeTestConfig(t, t.TempDir())
+
+	fc := testutil.NewFakeCommander()
+	fc.Register("gh api user --jq .login", "work-me\n", nil)
+
+	app := newTestApp(t, fc, cfgPath)
+	cmd := app.NewRootCmd()
+	cmd.SetArgs([]string{"--config", cfgPath, "profile", "refresh", "work"})
+	require.NoError(t, cmd.Execute())
+
+	cfg, err := config.Load(cfgPath)
+	require.NoError(t, err)
+	assert.Equal(t, "work-me", cfg.Profiles["work"].GitHubUser)
+	assert.Empty(t, cfg.Profiles["personal"].GitHubUser, "이름을 지정하면 그 프로필만 갱신")
+	assert.Equal(t, []string{"work"}, cfg.MatchOwner("work-me"), "github_user는 owner 판정에 쓰임")
+	require.Len(t, fc.EnvCalls, 1)
+	assert.Equal(t, "/tmp/gh-work", fc.EnvCalls[0]["GH_CONFIG_DIR"])
+}
+
+func TestProfileRefreshCmd_OtherAccount(t *testing.T) {
+	cfgPath := writeTestConfig(t, t.TempDir())
+	data, err := os.ReadFile(cfgPath)
+	require.NoError(t, err)
+	data = bytes.Replace(data, []byte(`owners = ["myorg"]`), []byte("owners = [\"myorg\"]\ngithub_user = \"work-me\""), 1)
+	require.NoError(t, os.WriteFile(cfgPath, data, 0600))
+
+	fc := testutil.NewFakeCommander()
+	fc.Register("gh api user --jq .login", "home-me\n", nil)
+
+	app := newTestApp(t, fc, cfgPath)
+	cmd := app.NewRootCmd()
+	cmd.SetArgs([]string{"--config", cfgPath, "profile", "refresh", "work", "--yes"})
+	require.NoError(t, cmd.Execute())
+
+	cfg, err := config.Load(cfgPath)
+	require.NoError(t, err)
+	assert.Equal(t, "home-me", cfg.Profiles["work"].GitHubUser, "--yes면 다른 계정으로도 갱신")
+}
+
+func TestProfileSyncCmd(t *testing.T) {
+	cfgPath := writeTestConfig(t, t.TempDir())
+
+	fc := testutil.NewFakeCommander()
+	fc.Register("gh api user/orgs --jq .[].login --paginate", "neworg\n", nil)
+	fc.Register("gh api user --jq .login", "work-me\n", nil)
+
+	app := newTestApp(t, fc, cfgPath)
+	cmd := app.NewRootCmd()
+	cmd.SetArgs([]string{"--config", cfgPath, "profile", "sync", "work", "--yes"})
+	require.NoError(t, cmd.Execute())
+
+	cfg, err := config.Load(cfgPath)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"myorg", "neworg"}, cfg.Profiles["work"].Owners, "--prune 없이는 제거하지 않음")
+	assert.Equal(t, []string{"myuser"}, cfg.Profiles["personal"].Owners)
+
+	cmd = app.NewRootCmd()
+	cmd.SetArgs([]string{"--config", cfgPath, "profile", "sync", "work", "--yes", "--prune"})
+	require.NoError(t, cmd.Execute())
+
+	cfg, err = config.Load(cfgPath)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"neworg"}, cfg.Profiles["work"].Owners)
+}
+
+func TestProfileSyncCmd_OrgsFailureSkipsProfile(t *testing.T) {
+	cfgPath := writeTestConfig(t, t.TempDir())
+
+	fc := testutil.NewFakeCommander()
+	fc.Register("gh api user/orgs", "", fmt.Errorf("HTTP 401"))
+
+	app := newTestApp(t, fc, cfgPath)
+	cmd := app.NewRootCmd()
+	cmd.SetArgs([]string{"--config", cfgPath, "profile", "sync", "--yes", "--prune"})
+	require.NoError(t, cmd.Execute())
+
+	cfg, err := config.Load(cfgPath)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"myorg"}, cfg.Profiles["work"].Owners, "조회 실패를 빈 조직으로 보지 않음")
+}
+
+func TestInitCmd_ProbeChecksOrgsOncePerInterval(t *testing.T) {
+	repoDir := testutil.TempGitRepoWithRemote(t, "git@gh-work:neworg/myrepo.git")
+	cfgDir := t.TempDir()
+	cfgPath := filepath.Join(cfgDir, "config.toml")
+	require.NoError(t, os.WriteFile(cfgPath, []byte(`version = 1
+
+[profiles.work]
+gh_config_dir = "/tmp/gh-work"
+ssh_host = "gh-work"
+git_name = "Test User"
+git_email = "test@work.com"
+owners = ["myorg"]
+`), 0600))
+	t.Chdir(repoDir)
+
+	fc := testutil.NewFakeCommander()
+	fc.Register("git -C "+repoDir+" remote get-url origin", "git@gh-work:neworg/myrepo.git", nil)
+	fc.Register("git -C "+repoDir+" config --local", "", nil)
+	fc.Register("gh api repos/neworg/myrepo", `{"permissions":{"push":true}}`, nil)
+	fc.Register("gh api user/orgs", "myorg\nneworg\n", nil)
+
+	app := newTestApp(t, fc, cfgPath)
+	for range 2 {
+		cmd := app.NewRootCmd()
+		cmd.SetArgs([]string{"--config", cfgPath, "init", "--no-guard"})
+		require.NoError(t, cmd.Execute())
+		// 다음 init도 probe를 거치도록 판정 캐시만 비운다
+		require.NoError(t, cache.Update(filepath.Join(cfgDir, "cache.json"), func(c *cache.Cache) error {
+			c.Entries = map[string]cache.Entry{}
+			return nil
+		}))
+	}
+
+	assert.Equal(t, 2, fc.CallCount("gh api repos/neworg/myrepo"), "두 번 모두 probe로 판정")
+	assert.Equal(t, 1, fc.CallCount("gh api user/orgs"), "조직 조회는 간격 안에서 한 번만")
+	c, err := cache.Load(filepath.Join(cfgDir, "cache.json"))
+	require.NoError(t, err)
+	assert.Contains(t, c.OwnerSyncs, "work")
+}
+
+func TestConfigUnsetProfile_SyncsSSHIncludeAndIncludeIf(t *testing.T) {
+	dir := t.TempDir()
+	cfgPath := filepath.Join(dir, "config.toml")
+	require.NoError(t, os.WriteFile(cfgPath, []byte(`version = 1
+git_include_if = true
+
+[profiles.work]
+gh_config_dir = "/tmp/gh-work"
+ssh_host = "github.com-work"
+identity_file = "~/.ssh/id_work"
+git_name = "Test User"
+git_email = "test@work.com"
+owners = ["myorg"]
+
+[profiles.personal]
+gh_config_dir = "/tmp/gh-personal"
+ssh_host = "github.com-personal"
+identity_file = "~/.ssh/id_personal"
+git_name = "Personal User"
+git_email = "me@personal.com"
+owners = ["myuser"]
+`), 0600))
+
+	app := newTestApp(t, testutil.NewFakeCommander(), cfgPath)
+	includePath := filepath.Join(filepath.Dir(app.SSHConfigPath), "config.d", "ctx")
+
+	cmd := app.NewRootCmd()
+	cmd.SetArgs([]string{"--config", cfgPath, "config", "set", "profiles.work.git_email", "new@work.com"})
+	require.NoError(t, cmd.Execute())
+
+	include, err := os.ReadFile(includePath)
+	require.NoError(t, err)
+	assert.Contains(t, string(include), "Host github.com-work")
+	workGitconfig, err := os.ReadFile(filepath.Join(app.GitIncludeDir, "work.gitconfig"))
+	require.NoError(t, err)
+	assert.Contains(t, string(workGitconfig), "new@work.com", "수정한 값이 includeIf 파일에 반영됨")
+
+	cmd = app.NewRootCmd()
+	cmd.SetArgs([]string{"--config", cfgPath, "config", "unset", "profiles.work"})
+	require.NoError(t, cmd.Execute())
+
+	include, err = os.ReadFile(includePath)
+	require.NoError(t, err)
+	assert.NotContains(t, string(include), "Host github.com-work", "삭제된 프로필의 Host 블록 제거")
+	assert.Contains(t, string(include), "Host github.com-personal")
+
+	global, err := os.ReadFile(app.GitConfigPath)
+	require.NoError(t, err)
+	assert.NotContains(t, string(global), "work.gitconfig", "삭제된 프로필의 includeIf 항목 제거")
+	assert.Contains(t, string(global), "personal.gitconfig")
+	assert.NoFileExists(t, filepath.Join(app.GitIncludeDir, "work.gitconfig"))
+}
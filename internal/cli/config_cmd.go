@@ -0,0 +1,366 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/hbjs97/ctx/internal/cache"
+	"github.com/hbjs97/ctx/internal/config"
+	"github.com/hbjs97/ctx/internal/git"
+	"github.com/hbjs97/ctx/internal/setup"
+	"github.com/hbjs97/ctx/internal/sshconfig"
+	"github.com/spf13/cobra"
+)
+
+func (a *App) newConfigCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "config",
+		Short: "설정 파일을 검증하고 관리한다",
+		Long: `설정 파일을 검증하고 관리한다.
+
+키는 점으로 구분한다 (예: cache_ttl_days, profiles.work.owners).
+목록 값은 쉼표로 구분해 지정한다.`,
+	}
+	cmd.AddCommand(
+		a.newConfigGetCmd(),
+		a.newConfigSetCmd(),
+		a.newConfigUnsetCmd(),
+		a.newConfigListCmd(),
+		a.newConfigEditCmd(),
+		a.newConfigPathCmd(),
+		a.newConfigValidateCmd(),
+		a.newConfigMigrateCmd(),
+	)
+	return cmd
+}
+
+func (a *App) newConfigGetCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "get <key>",
+		Short: "설정 값을 출력한다",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cfg, _, err := a.readConfig()
+			if err != nil {
+				return err
+			}
+			v, err := cfg.Get(args[0])
+			if err != nil {
+				return fmt.Errorf("cli.config: %w", err)
+			}
+			fmt.Println(v)
+			return nil
+		},
+	}
+}
+
+func (a *App) newConfigSetCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "set <key> <value>",
+		Short: "설정 값을 변경한다 (목록은 쉼표로 구분)",
+		Args:  cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := a.checkNotLocked(args[0]); err != nil {
+				return err
+			}
+			return a.updateConfig(func(cfg *config.Config) error {
+				return cfg.Set(args[0], args[1])
+			})
+		},
+	}
+}
+
+func (a *App) newConfigUnsetCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "unset <key>",
+		Short: "설정 값을 기본값으로 되돌린다 (profiles.<name>이면 프로필 삭제)",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := a.checkNotLocked(args[0]); err != nil {
+				return err
+			}
+			return a.updateConfig(func(cfg *config.Config) error {
+				return cfg.Unset(args[0])
+			})
+		},
+	}
+}
+
+func (a *App) newConfigListCmd() *cobra.Command {
+	var showOrigin bool
+	cmd := &cobra.Command{
+		Use:   "list",
+		Short: "모든 설정 값을 출력한다 (팀 정책 적용 후)",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return a.runConfigList(cmd.Context(), showOrigin)
+		},
+	}
+	cmd.Flags().BoolVar(&showOrigin, "show-origin", false, "각 값이 온 계층(default, policy, user, repo)과 파일을 함께 출력")
+	return cmd
+}
+
+func (a *App) runConfigList(ctx context.Context, showOrigin bool) error {
+	cfg, origins, err := a.readConfig()
+	if err != nil {
+		return err
+	}
+	for _, kv := range cfg.List() {
+		if showOrigin {
+			fmt.Printf("%-40s %s = %s\n", origins[kv.Key], kv.Key, kv.Value)
+		} else {
+			fmt.Printf("%s = %s\n", kv.Key, kv.Value)
+		}
+	}
+	if !showOrigin {
+		return nil
+	}
+
+	// 리포 힌트는 설정을 바꾸지 않지만, 현재 리포에서 guard가 무엇을 기대하는지 함께 보여 준다
+	cwd, err := os.Getwd()
+	if err != nil {
+		return nil
+	}
+	root, err := git.NewAdapter(a.Commander).TopLevel(ctx, cwd)
+	if err != nil {
+		return nil
+	}
+	hints, err := config.LoadRepoHints(root)
+	if err != nil {
+		return fmt.Errorf("cli.config: %w", err)
+	}
+	if hints == nil {
+		return nil
+	}
+	origin := config.Origin{Layer: "repo", Path: hints.Path}
+	for _, kv := range hints.List() {
+		fmt.Printf("%-40s %s = %s\n", origin, kv.Key, kv.Value)
+	}
+	return nil
+}
+
+func (a *App) newConfigEditCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "edit",
+		Short: "$EDITOR로 설정 파일을 편집한다 (저장 전 검증)",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return a.runConfigEdit(cmd.Context(), &setup.HuhFormRunner{})
+		},
+	}
+}
+
+func (a *App) newConfigPathCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "path",
+		Short: "설정 파일 경로를 출력한다",
+		Args:  cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			fmt.Println(a.CfgPath)
+		},
+	}
+}
+
+func (a *App) newConfigMigrateCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "migrate",
+		Short: "설정 파일과 캐시를 현재 스키마 버전으로 변환해 저장한다 (원본 백업)",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return a.runConfigMigrate()
+		},
+	}
+}
+
+func (a *App) runConfigMigrate() error {
+	applied, backup, err := config.Migrate(a.CfgPath)
+	if err != nil {
+		return fmt.Errorf("cli.config: %w", err)
+	}
+	printMigration("설정", a.CfgPath, config.CurrentVersion, applied, backup)
+
+	applied, backup, err = cache.Migrate(a.cachePath())
+	if err != nil {
+		return fmt.Errorf("cli.config: %w", err)
+	}
+	printMigration("캐시", a.cachePath(), cache.CurrentVersion, applied, backup)
+	return nil
+}
+
+func printMigration(label, path string, version int, applied []string, backup string) {
+	if len(applied) == 0 {
+		fmt.Printf("%s: 최신 버전 (version %d): %s\n", label, version, path)
+		return
+	}
+	fmt.Printf("%s: version %d로 변환: %s (백업: %s)\n", label, version, path, backup)
+	for _, m := range applied {
+		fmt.Printf("  %s\n", m)
+	}
+}
+
+// readConfig는 팀 정책을 적용한 설정과 각 키의 출처를 읽는다. 정책 잠금으로 무시된 사용자 값은 경고로 알린다.
+// 설정에 error 수준 문제가 있으면 정책을 빼고 검증 없이 읽는다. 잘못된 설정도 조회할 수 있어야 한다.
+func (a *App) readConfig() (*config.Config, map[string]config.Origin, error) {
+	l, _, err := a.loadLayered()
+	if err == nil {
+		for _, i := range l.Ignored {
+			fmt.Fprintf(os.Stderr, "경고: %s\n", i)
+		}
+		return l.Config, l.Origins, nil
+	}
+	cfg, derr := a.decodeConfig()
+	if derr != nil {
+		return nil, nil, derr
+	}
+	fmt.Fprintf(os.Stderr, "경고: %v\n", err)
+	return cfg, nil, nil
+}
+
+// checkNotLocked는 key가 팀 정책으로 잠겨 있으면 ErrConfig를 반환한다.
+func (a *App) checkNotLocked(key string) error {
+	policy, err := config.LoadPolicy(a.policyPath())
+	if err != nil {
+		return fmt.Errorf("cli.config: %w", err)
+	}
+	if policy.IsLocked(key) {
+		return fmt.Errorf("cli.config: %s는 팀 정책(%s)으로 잠겨 있어 바꿀 수 없습니다: %w", key, policy.Path, config.ErrConfig)
+	}
+	return nil
+}
+
+// decodeConfig는 검증 없이 설정을 읽는다. 잘못된 설정도 조회하고 고칠 수 있어야 한다.
+func (a *App) decodeConfig() (*config.Config, error) {
+	cfg, err := config.Decode(a.CfgPath)
+	if err != nil {
+		return nil, fmt.Errorf("cli.config: %w: %w", err, config.ErrConfig)
+	}
+	return cfg, nil
+}
+
+// updateConfig는 설정을 lock한 상태에서 읽어 mutate를 적용한 뒤 저장하고,
+// SSH include와 includeIf 파일을 저장된 설정에 맞춘다.
+// 결과 설정에 error 수준 문제가 있으면 저장을 거부하고 파일은 바뀌지 않는다.
+func (a *App) updateConfig(mutate func(*config.Config) error) error {
+	if err := config.Update(a.CfgPath, mutate); err != nil {
+		return fmt.Errorf("cli.config: %w", err)
+	}
+	return a.syncProfiles()
+}
+
+// runConfigEdit는 설정 파일의 임시 사본을 편집기로 연다.
+// 편집 결과가 검증을 통과해야 저장하며, 실패하면 다시 편집할지 묻는다.
+func (a *App) runConfigEdit(ctx context.Context, form setup.FormRunner) error {
+	orig, err := os.ReadFile(a.CfgPath)
+	if err != nil {
+		return fmt.Errorf("cli.config: %w", err)
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(a.CfgPath), "config.*.toml")
+	if err != nil {
+		return fmt.Errorf("cli.config: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	_, err = tmp.Write(orig)
+	if cerr := tmp.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		return fmt.Errorf("cli.config: %w", err)
+	}
+
+	editor := editorCommand()
+	for {
+		args := append(editor[1:], tmp.Name())
+		if err := a.Commander.RunInteractiveWithEnv(ctx, nil, editor[0], args...); err != nil {
+			return fmt.Errorf("cli.config: 편집기 실행 실패: %w", err)
+		}
+
+		edited, err := os.ReadFile(tmp.Name())
+		if err != nil {
+			return fmt.Errorf("cli.config: %w", err)
+		}
+		if bytes.Equal(edited, orig) {
+			fmt.Println("변경 없음")
+			return nil
+		}
+
+		// 편집한 문서를 그대로 저장해 사용자가 쓴 주석과 순서를 남긴다
+		saveErr := config.SaveDocument(a.CfgPath, edited)
+		if saveErr == nil {
+			fmt.Printf("저장됨: %s\n", a.CfgPath)
+			return a.syncProfiles()
+		}
+
+		fmt.Printf("설정 오류: %v\n", saveErr)
+		again, err := form.RunConfirm("다시 편집할까요? (아니오를 선택하면 변경을 버립니다)")
+		if err != nil {
+			return fmt.Errorf("cli.config: %w", err)
+		}
+		if !again {
+			return fmt.Errorf("cli.config: 편집 취소: %w", saveErr)
+		}
+	}
+}
+
+// editorCommand는 $VISUAL, $EDITOR 순으로 편집기 명령을 찾는다. 둘 다 없으면 vi를 사용한다.
+func editorCommand() []string {
+	for _, env := range []string{"VISUAL", "EDITOR"} {
+		if fields := strings.Fields(os.Getenv(env)); len(fields) > 0 {
+			return fields
+		}
+	}
+	return []string{"vi"}
+}
+
+func (a *App) newConfigValidateCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "validate",
+		Short: "프로필 간 충돌과 누락된 설정을 검사한다",
+		Long: `설정 파일을 검사해 error와 warning을 출력한다.
+
+error (ctx가 설정 로드와 저장을 거부):
+  필수 필드 누락, 존재하지 않는 default_profile,
+  프로필 간 ssh_host / gh_config_dir 중복 (기존 설정은 경고만 하고 읽음)
+warning:
+  프로필 간 git_email / owners 중복, SSH config에 없는 ssh_host alias`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return a.runConfigValidate()
+		},
+	}
+}
+
+func (a *App) runConfigValidate() error {
+	cfg, err := config.Decode(a.CfgPath)
+	if err != nil {
+		return fmt.Errorf("cli.config: %w: %w", err, config.ErrConfig)
+	}
+
+	var hasSSHHost func(string) bool
+	if sshCfg, err := sshconfig.Load(setup.DefaultSSHConfigPath()); err == nil {
+		hasSSHHost = sshCfg.HasHost
+	}
+	issues := cfg.Validate(hasSSHHost)
+	policy, err := config.LoadPolicy(a.policyPath())
+	if err != nil {
+		return fmt.Errorf("cli.config: %w", err)
+	}
+	if policy != nil {
+		if l, err := config.LoadLayered(a.CfgPath, policy); err == nil {
+			issues = append(issues, l.Ignored...)
+		}
+	}
+	if len(issues) == 0 {
+		fmt.Printf("문제 없음: %s\n", a.CfgPath)
+		return nil
+	}
+	for _, i := range issues {
+		fmt.Printf("[%s] %s\n", i.Severity, i)
+	}
+	if err := config.IssuesError(issues); err != nil {
+		return fmt.Errorf("cli.config: %w", err)
+	}
+	return nil
+}
@@ -3,40 +3,169 @@ package cli
 import (
 	"context"
 	"fmt"
+	"os"
+	"strings"
+	"time"
 
 	"github.com/hbjs97/ctx/internal/config"
 	"github.com/hbjs97/ctx/internal/doctor"
+	"github.com/hbjs97/ctx/internal/setup"
 	"github.com/spf13/cobra"
 )
 
+// doctorOptions는 ctx doctor 플래그다.
+type doctorOptions struct {
+	checks  []string
+	repo    bool
+	fix     bool
+	yes     bool
+	timeout time.Duration
+	jobs    int
+}
+
 func (a *App) newDoctorCmd() *cobra.Command {
-	return &cobra.Command{
+	var opts doctorOptions
+	cmd := &cobra.Command{
 		Use:   "doctor",
 		Short: "환경 설정을 진단한다",
+		Long:  doctorLongHelp(),
 		RunE: func(cmd *cobra.Command, args []string) error {
-			return a.runDoctor(cmd.Context())
+			return a.runDoctor(cmd.Context(), opts)
 		},
 	}
+	cmd.Flags().StringSliceVar(&opts.checks, "check", nil, "지정한 진단 항목만 실행 (예: --check identities_only)")
+	cmd.Flags().BoolVar(&opts.repo, "repo", false, "현재 리포 checkout을 진단 (프로필, guard hook, remote, identity, 캐시)")
+	cmd.Flags().BoolVar(&opts.fix, "fix", false, "자동 수정 가능한 문제를 조치")
+	cmd.Flags().BoolVarP(&opts.yes, "yes", "y", false, "--fix 시 확인 없이 모든 조치를 실행")
+	cmd.Flags().DurationVar(&opts.timeout, "timeout", doctor.DefaultTimeout, "진단 항목별 제한 시간")
+	cmd.Flags().IntVar(&opts.jobs, "jobs", doctor.DefaultWorkers, "동시에 실행할 진단 항목 수")
+	return cmd
+}
+
+// doctorLongHelp는 진단 항목 목록을 포함한 도움말을 만든다.
+func doctorLongHelp() string {
+	var b strings.Builder
+	b.WriteString("환경 설정을 진단한다.\n\n진단 항목:\n")
+	for _, c := range doctor.Checks() {
+		fmt.Fprintf(&b, "  %-18s %s\n", c.Name, c.Description)
+	}
+	return b.String()
 }
 
-func (a *App) runDoctor(ctx context.Context) error {
-	cfg, err := config.Load(a.CfgPath)
+func (a *App) runDoctor(ctx context.Context, opts doctorOptions) error {
+	selected, err := doctor.Select(opts.checks)
 	if err != nil {
-		fmt.Printf("[FAIL] config: %v\n", err)
-		fmt.Println("      Fix: ctx setup 실행 또는 설정 파일 확인")
+		return err
+	}
+	// --check로 지정하지 않으면 --repo 여부에 따라 리포 진단 또는 환경 진단만 실행한다.
+	var checks []doctor.Check
+	needRepo := false
+	for _, c := range selected {
+		if len(opts.checks) == 0 && c.Repo != opts.repo {
+			continue
+		}
+		checks = append(checks, c)
+		needRepo = needRepo || c.Repo
+	}
+
+	var cfg *config.Config
+	var ignored []config.Issue
+	l, _, cfgErr := a.loadLayered()
+	if cfgErr == nil {
+		cfg, ignored = l.Config, l.Ignored
+	}
+	shellType := setup.DetectShell()
+	env := &doctor.Env{
+		Commander:     a.Commander,
+		Config:        cfg,
+		ConfigPath:    a.CfgPath,
+		ConfigErr:     cfgErr,
+		ConfigIgnored: ignored,
+		ShellType:     shellType,
+		ShellRCPath:   setup.ShellRCPath(shellType),
+		CachePath:     a.cachePath(),
+		SSHConfigPath: setup.DefaultSSHConfigPath(),
+	}
+	if cwd, err := os.Getwd(); err == nil {
+		env.RepoDir = cwd
+	}
+	if needRepo {
+		if env.Repo, err = doctor.LoadRepoInfo(ctx, a.Commander, env.RepoDir); err != nil {
+			return fmt.Errorf("cli.doctor: %w", err)
+		}
 	}
 
-	// Run diagnostics per profile if config loaded
-	if cfg != nil {
-		for name, profile := range cfg.Profiles {
-			fmt.Printf("\n--- 프로필: %s ---\n", name)
-			results := doctor.RunAll(ctx, a.Commander, profile.GHConfigDir, profile.SSHHost)
-			printDiagResults(results)
+	taskResults := doctor.RunTasks(ctx, env, doctor.Plan(checks, cfg), doctor.RunOptions{
+		Workers: opts.jobs,
+		Timeout: opts.timeout,
+	})
+
+	var fixable []doctor.DiagResult
+	section := ""
+	for _, tr := range taskResults {
+		if s := doctorSection(tr.Task, env); s != section {
+			section = s
+			fmt.Printf("\n--- %s ---\n", section)
 		}
-	} else {
-		// Run basic binary checks without config
-		results := doctor.CheckBinaries(ctx, a.Commander)
-		printDiagResults(results)
+		printDiagResults(tr.Results)
+		for _, r := range tr.Results {
+			if r.Remedy != nil {
+				fixable = append(fixable, r)
+			}
+		}
+	}
+
+	if !opts.fix {
+		if len(fixable) > 0 {
+			fmt.Printf("\n자동 수정 가능한 항목 %d개: ctx doctor --fix\n", len(fixable))
+		}
+		return nil
+	}
+	return applyRemedies(ctx, fixable, opts.yes, &setup.HuhFormRunner{})
+}
+
+// doctorSection은 진단 결과를 묶어 출력할 구역 이름을 반환한다.
+func doctorSection(t doctor.Task, env *doctor.Env) string {
+	switch {
+	case t.Check.Repo:
+		return "리포: " + env.Repo.Root
+	case t.Profile != "":
+		return "프로필: " + t.Profile
+	default:
+		return "환경"
+	}
+}
+
+// applyRemedies는 진단 결과에 붙은 자동 조치를 순서대로 실행한다.
+// yes가 false면 조치마다 확인을 받는다. 일부 조치가 실패해도 나머지를 계속 실행한다.
+func applyRemedies(ctx context.Context, results []doctor.DiagResult, yes bool, form setup.FormRunner) error {
+	if len(results) == 0 {
+		fmt.Println("\n자동 수정할 항목이 없습니다.")
+		return nil
+	}
+
+	fmt.Println("\n--- 자동 수정 ---")
+	var failed int
+	for _, r := range results {
+		if !yes {
+			ok, err := form.RunConfirm(fmt.Sprintf("%s: %s", r.Name, r.Remedy.Description))
+			if err != nil {
+				return fmt.Errorf("cli.doctor: %w", err)
+			}
+			if !ok {
+				fmt.Printf("  [--] %s: 건너뜀\n", r.Name)
+				continue
+			}
+		}
+		if err := r.Remedy.Apply(ctx); err != nil {
+			failed++
+			fmt.Printf("  [FAIL] %s: %v\n", r.Name, err)
+			continue
+		}
+		fmt.Printf("  [OK] %s: %s\n", r.Name, r.Remedy.Description)
+	}
+	if failed > 0 {
+		return fmt.Errorf("cli.doctor: 자동 수정 %d건 실패", failed)
 	}
 	return nil
 }
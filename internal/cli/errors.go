@@ -10,6 +10,8 @@ import (
 var (
 	// ErrGuardBlock는 guard 검사 실패로 push가 차단될 때의 sentinel error다.
 	ErrGuardBlock = guard.ErrGuardBlock
+	// ErrCommitBlock는 pre-commit guard 검사 실패로 커밋이 차단될 때의 sentinel error다.
+	ErrCommitBlock = guard.ErrCommitBlock
 	// ErrAmbiguous는 복수 프로필이 매칭되어 자동 판정이 불가능할 때의 sentinel error다.
 	ErrAmbiguous = resolver.ErrAmbiguous
 	// ErrAuthFail는 접근 가능한 프로필이 없을 때의 sentinel error다.
@@ -28,7 +28,7 @@ func MapExitCode(err error) ExitCode {
 		return ExitSuccess
 	}
 	switch {
-	case errors.Is(err, ErrGuardBlock):
+	case errors.Is(err, ErrGuardBlock), errors.Is(err, ErrCommitBlock):
 		return ExitGuardBlock
 	case errors.Is(err, ErrAmbiguous):
 		return ExitAmbiguous
@@ -6,32 +6,70 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
+	"time"
 
+	"github.com/hbjs97/ctx/internal/audit"
 	"github.com/hbjs97/ctx/internal/config"
+	"github.com/hbjs97/ctx/internal/git"
 	"github.com/hbjs97/ctx/internal/guard"
+	"github.com/hbjs97/ctx/internal/setup"
+	"github.com/hbjs97/ctx/internal/sshconfig"
 	"github.com/spf13/cobra"
 )
 
 func (a *App) newGuardCmd() *cobra.Command {
 	cmd := &cobra.Command{
 		Use:   "guard",
-		Short: "pre-push guard 관리",
+		Short: "pre-push/pre-commit guard 관리",
 	}
-	cmd.AddCommand(a.newGuardCheckCmd())
+	cmd.AddCommand(a.newGuardCheckCmd(), a.newGuardAllowCmd(), a.newGuardFixCmd())
 	return cmd
 }
 
 func (a *App) newGuardCheckCmd() *cobra.Command {
-	return &cobra.Command{
-		Use:   "check",
+	var prePush bool
+	var stage string
+	cmd := &cobra.Command{
+		Use:   "check [--pre-push <remote> <url> | --stage commit]",
 		Short: "현재 리포의 컨텍스트 무결성을 검사한다",
+		Long: `현재 리포의 컨텍스트 무결성을 검사한다.
+
+--pre-push는 pre-push hook이 사용한다. hook 인자(remote 이름, URL)와
+stdin의 push ref 목록으로 protected_branch, force_push도 검사한다.
+
+--stage commit은 pre-commit hook이 사용한다. remote 검사 없이
+커밋 작성자(user.email, user.name)와 이메일 규칙만 검사한다.`,
+		Args: cobra.MaximumNArgs(2),
 		RunE: func(cmd *cobra.Command, args []string) error {
-			return a.runGuardCheck(cmd.Context())
+			switch {
+			case stage != guard.StagePush && stage != guard.StageCommit:
+				return fmt.Errorf("cli.guard: 알 수 없는 --stage %q (push, commit)", stage)
+			case stage == guard.StageCommit && (prePush || len(args) > 0):
+				return fmt.Errorf("cli.guard: --stage commit은 --pre-push와 함께 쓸 수 없음")
+			}
+			var push *guard.Push
+			if prePush {
+				updates, err := guard.ParsePushUpdates(cmd.InOrStdin())
+				if err != nil {
+					return fmt.Errorf("cli.guard: %w", err)
+				}
+				push = &guard.Push{Updates: updates}
+				if len(args) > 0 {
+					push.Remote = args[0]
+				}
+				if len(args) > 1 {
+					push.URL = args[1]
+				}
+			}
+			return a.runGuardCheck(cmd.Context(), stage, push)
 		},
 	}
+	cmd.Flags().BoolVar(&prePush, "pre-push", false, "pre-push hook 인자와 stdin의 push 정보를 함께 검사")
+	cmd.Flags().StringVar(&stage, "stage", guard.StagePush, "검사 단계 (push, commit)")
+	return cmd
 }
 
-func (a *App) runGuardCheck(ctx context.Context) error {
+func (a *App) runGuardCheck(ctx context.Context, stage string, push *guard.Push) error {
 	cwd, err := os.Getwd()
 	if err != nil {
 		return fmt.Errorf("cli.guard: %w", err)
@@ -44,7 +82,7 @@ func (a *App) runGuardCheck(ctx context.Context) error {
 	}
 	profileName := strings.TrimSpace(string(data))
 
-	cfg, err := config.Load(a.CfgPath)
+	cfg, err := a.loadConfig()
 	if err != nil {
 		return err
 	}
@@ -54,14 +92,30 @@ func (a *App) runGuardCheck(ctx context.Context) error {
 		return err
 	}
 
-	result, err := guard.Check(ctx, cwd, profile, a.Commander)
+	envBypass := os.Getenv("CTX_SKIP_GUARD") == "1" && cfg.IsEnvBypassAllowed()
+	opts, err := a.guardOptions(cwd, cfg, profileName, profile)
+	if err != nil && !envBypass { // 우회 시에는 .ctx.toml 오류도 건너뛴다
+		return err
+	}
+	opts.Push = push
+	opts.Stage = stage
+	opts.DisableEnvBypass = !cfg.IsEnvBypassAllowed()
+	result, err := guard.CheckWithOptions(ctx, cwd, profile, a.Commander, opts)
 	if err != nil {
 		return err
 	}
+	if result.Skipped && push != nil {
+		result.Remote = push.URL
+	}
+	a.recordGuard(ctx, cwd, profileName, stage, result)
 
 	if !result.Pass {
 		for _, v := range result.Violations {
-			fmt.Printf("[%s] %s: 기대=%s, 실제=%s\n", v.Severity, v.Field, v.Expected, v.Actual)
+			fmt.Printf("[%s] %s\n", v.Severity, formatViolation(v))
+		}
+		fmt.Println("수정 방법: ctx guard fix (push되지 않은 커밋의 작성자까지 고치려면 --rewrite)")
+		if stage == guard.StageCommit {
+			return fmt.Errorf("cli.guard: %w", guard.ErrCommitBlock)
 		}
 		return fmt.Errorf("cli.guard: %w", guard.ErrGuardBlock)
 	}
@@ -72,11 +126,120 @@ func (a *App) runGuardCheck(ctx context.Context) error {
 	}
 
 	for _, v := range result.Violations {
-		if v.Severity == "warning" {
-			fmt.Fprintf(os.Stderr, "[경고] %s: 기대=%s, 실제=%s\n", v.Field, v.Expected, v.Actual)
+		switch {
+		case v.Severity == "warning":
+			fmt.Fprintf(os.Stderr, "[경고] %s\n", formatViolation(v))
+		case result.Allowance != nil:
+			fmt.Fprintf(os.Stderr, "[허용] %s\n", formatViolation(v))
 		}
 	}
+	if result.Allowance != nil {
+		fmt.Fprintf(os.Stderr, "guard 차단을 허용 토큰으로 통과 (사유: %s)\n", result.Allowance.Reason)
+	}
 
 	fmt.Println("guard 검사 통과")
 	return nil
 }
+
+func (a *App) newGuardAllowCmd() *cobra.Command {
+	var once bool
+	var duration time.Duration
+	var reason string
+	cmd := &cobra.Command{
+		Use:   "allow (--once | --for <duration>) --reason <사유>",
+		Short: "현재 리포의 guard 차단을 잠시 허용한다",
+		Long: `현재 리포의 guard 차단을 잠시 허용한다.
+
+.git/ctx-guard-allow에 허용 토큰을 쓰며, guard 검사가 차단할 때만 소비된다.
+--once는 한 번 쓰면 지워지고 (기본 15분 안에 사용), --for는 기간 동안 계속 쓸 수 있다.
+토큰 생성과 사용은 사유와 함께 감사 로그에 남는다.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return a.runGuardAllow(cmd.Context(), once, duration, reason)
+		},
+	}
+	cmd.Flags().BoolVar(&once, "once", false, "다음 차단 한 번만 허용")
+	cmd.Flags().DurationVar(&duration, "for", 0, "허용 기간 (예: 10m, 최대 24h)")
+	cmd.Flags().StringVar(&reason, "reason", "", "우회 사유 (필수)")
+	return cmd
+}
+
+func (a *App) runGuardAllow(ctx context.Context, once bool, duration time.Duration, reason string) error {
+	reason = strings.TrimSpace(reason)
+	switch {
+	case reason == "":
+		return fmt.Errorf("cli.guard: --reason 필요")
+	case !once && duration == 0:
+		return fmt.Errorf("cli.guard: --once 또는 --for 필요")
+	case duration < 0 || duration > guard.MaxAllowanceTTL:
+		return fmt.Errorf("cli.guard: --for는 0보다 크고 %s 이하여야 함", guard.MaxAllowanceTTL)
+	}
+	if duration == 0 {
+		duration = guard.DefaultAllowanceTTL
+	}
+
+	cwd, err := os.Getwd()
+	if err != nil {
+		return fmt.Errorf("cli.guard: %w", err)
+	}
+	if _, err := os.Stat(filepath.Join(cwd, ".git")); err != nil {
+		return fmt.Errorf("cli.guard: 리포 최상위에서 실행해야 함: %w", err)
+	}
+
+	now := time.Now()
+	allowance := guard.Allowance{Reason: reason, Once: once, Created: now, Expires: now.Add(duration)}
+	if err := guard.WriteAllowance(cwd, allowance); err != nil {
+		return err
+	}
+
+	profileName, _ := os.ReadFile(filepath.Join(cwd, ".git", "ctx-profile")) // 감사 기록용. 없어도 허용은 유효
+	r := audit.Record{Event: audit.EventGuardAllow, Dir: cwd, Profile: strings.TrimSpace(string(profileName)), Reason: reason}
+	r.Remote, _ = git.NewAdapter(a.Commander).GetRemoteURL(ctx, cwd, "origin") // 기록용으로만 조회
+	if ref, err := git.ParseRepoURL(r.Remote); err == nil {
+		r.Repo = ref.Owner + "/" + ref.Repo
+	}
+	a.recordAudit(r)
+
+	uses := "기간 내 계속"
+	if once {
+		uses = "1회"
+	}
+	fmt.Printf("guard 차단 허용: %s, %s까지 (사유: %s)\n", uses, allowance.Expires.Format("2006-01-02 15:04:05"), reason)
+	return nil
+}
+
+// guardOptions는 리포의 .ctx.toml과 설정의 email_rules, 검사 수준으로 guard 옵션을 만든다.
+// .ctx.toml이 잘못되어 있으면 검사하지 못한 것이므로 push를 차단한다.
+func (a *App) guardOptions(repoDir string, cfg *config.Config, profileName string, profile *config.Profile) (guard.Options, error) {
+	opts := guard.Options{
+		HostName:   profile.SSHHost,
+		EmailRules: cfg.EmailRules,
+		Checks:     cfg.GuardChecksFor(profileName, nil),
+		Identity: guard.Identity{
+			CachePath:  filepath.Join(filepath.Dir(a.cachePath()), "ssh-identity.json"),
+			TTL:        cfg.SSHIdentityTTL(),
+			GitHubUser: profile.GitHubUser,
+		},
+	}
+	hints, err := config.LoadRepoHints(repoDir)
+	if err != nil {
+		return opts, fmt.Errorf("cli.guard: %w", err)
+	}
+	opts.Hints = hints
+	opts.Checks = cfg.GuardChecksFor(profileName, hints)
+	if hints != nil && hints.Host != "" {
+		if sshCfg, err := sshconfig.Load(setup.DefaultSSHConfigPath()); err == nil {
+			opts.HostName = sshCfg.HostName(profile.SSHHost)
+		}
+	}
+	return opts, nil
+}
+
+// formatViolation은 위반 항목을 한 줄로 표시한다. email_rules 위반이면 규칙 이름을 덧붙인다.
+func formatViolation(v guard.Violation) string {
+	s := fmt.Sprintf("%s: 기대=%s, 실제=%s", v.Field, v.Expected, v.Actual)
+	if v.Rule != "" {
+		s += fmt.Sprintf(" (규칙: %s)", v.Rule)
+	}
+	return s
+}
@@ -0,0 +1,156 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/hbjs97/ctx/internal/git"
+	"github.com/hbjs97/ctx/internal/guard"
+	"github.com/hbjs97/ctx/internal/setup"
+	"github.com/spf13/cobra"
+)
+
+func (a *App) newGuardFixCmd() *cobra.Command {
+	var rewrite, yes bool
+	cmd := &cobra.Command{
+		Use:   "fix [--rewrite] [--yes]",
+		Short: "guard 차단 원인을 프로필에 맞게 고친다",
+		Long: `guard 차단 원인을 프로필에 맞게 고친다.
+
+.git/ctx-profile의 프로필로 user.name, user.email과 origin remote URL을 다시 적용한다.
+--rewrite면 push되지 않은 커밋 중 작성자나 커미터가 프로필과 다른 커밋을
+git rebase --exec "git commit --amend --reset-author"로 다시 쓴다.
+실행 전에 계획을 보여주며, 다시 쓸 범위에 이미 push된 커밋이 있으면 거부한다.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return a.runGuardFix(cmd.Context(), rewrite, yes, &setup.HuhFormRunner{})
+		},
+	}
+	cmd.Flags().BoolVar(&rewrite, "rewrite", false, "push되지 않은 커밋의 작성자/커미터도 다시 쓰기")
+	cmd.Flags().BoolVarP(&yes, "yes", "y", false, "확인 없이 실행")
+	return cmd
+}
+
+// fixStep은 ctx guard fix가 실행할 조치 하나다.
+type fixStep struct {
+	description string
+	apply       func(ctx context.Context) error
+}
+
+func (a *App) runGuardFix(ctx context.Context, rewrite, yes bool, form setup.FormRunner) error {
+	cwd, err := os.Getwd()
+	if err != nil {
+		return fmt.Errorf("cli.guard: %w", err)
+	}
+	data, err := os.ReadFile(filepath.Join(cwd, ".git", "ctx-profile"))
+	if err != nil {
+		return fmt.Errorf("cli.guard: ctx-profile 읽기 실패 (ctx init으로 프로필 지정): %w", err)
+	}
+	profileName := strings.TrimSpace(string(data))
+
+	cfg, err := a.loadConfig()
+	if err != nil {
+		return err
+	}
+	profile, err := cfg.GetProfile(profileName)
+	if err != nil {
+		return err
+	}
+
+	gitAdapter := git.NewAdapter(a.Commander)
+	var steps []fixStep
+	for _, kv := range []struct{ key, want string }{
+		{"user.name", profile.GitName},
+		{"user.email", profile.GitEmail},
+	} {
+		out, _ := a.Commander.Run(ctx, "git", "-C", cwd, "config", "--local", kv.key) // 설정되지 않은 키는 exit 1이므로 빈 값으로 본다
+		if current := strings.TrimSpace(string(out)); current != kv.want {
+			steps = append(steps, fixStep{
+				description: fmt.Sprintf("%s: %q → %q", kv.key, current, kv.want),
+				apply: func(ctx context.Context) error {
+					return gitAdapter.SetLocalConfig(ctx, cwd, kv.key, kv.want)
+				},
+			})
+		}
+	}
+
+	remoteURL, err := gitAdapter.GetRemoteURL(ctx, cwd, "origin")
+	if err == nil {
+		ref, parseErr := git.ParseRepoURL(remoteURL)
+		keepHTTPS := git.IsHTTPSRemote(remoteURL) && cfg.AllowHTTPSManagedRepo
+		if parseErr == nil && !keepHTTPS && git.SSHHost(remoteURL) != profile.SSHHost {
+			newURL := git.BuildSSHRemoteURL(profile.SSHHost, ref.Owner, ref.Repo)
+			steps = append(steps, fixStep{
+				description: fmt.Sprintf("remote origin: %s → %s", MaskTokens(remoteURL), newURL),
+				apply: func(ctx context.Context) error {
+					return gitAdapter.SetRemoteURL(ctx, cwd, "origin", newURL)
+				},
+			})
+		}
+	}
+
+	var plan *guard.RewritePlan
+	if rewrite {
+		plan, err = guard.PlanRewrite(ctx, a.Commander, cwd, profile)
+		if errors.Is(err, guard.ErrPushedCommits) {
+			return fmt.Errorf("cli.guard: %w — push된 커밋은 force push 없이 고칠 수 없으므로 직접 처리해야 함", err)
+		}
+		if err != nil {
+			return err
+		}
+		if len(plan.Wrong) > 0 {
+			steps = append(steps, fixStep{
+				description: rewriteDescription(plan, profile.GitName, profile.GitEmail),
+				apply: func(ctx context.Context) error {
+					return guard.Rewrite(ctx, a.Commander, cwd, plan, profile)
+				},
+			})
+		}
+	}
+
+	if len(steps) == 0 {
+		fmt.Println("수정할 항목이 없습니다.")
+		return nil
+	}
+
+	fmt.Printf("수정 계획 (프로필: %s):\n", profileName)
+	for i, s := range steps {
+		fmt.Printf("  %d. %s\n", i+1, s.description)
+	}
+	if !yes {
+		ok, err := form.RunConfirm("위 계획을 실행하시겠습니까?")
+		if err != nil {
+			return fmt.Errorf("cli.guard: %w", err)
+		}
+		if !ok {
+			fmt.Println("취소했습니다.")
+			return nil
+		}
+	}
+
+	for _, s := range steps {
+		if err := s.apply(ctx); err != nil {
+			return fmt.Errorf("cli.guard: %w", err)
+		}
+	}
+	fmt.Println("수정 완료 — ctx guard check로 확인하세요")
+	return nil
+}
+
+// rewriteDescription은 rewrite 계획을 범위와 다시 쓸 커밋 목록으로 표시한다.
+func rewriteDescription(plan *guard.RewritePlan, name, email string) string {
+	var b strings.Builder
+	base := "루트 커밋"
+	if plan.Base != "" {
+		base = plan.Base[:min(7, len(plan.Base))]
+	}
+	fmt.Fprintf(&b, "커밋 %d개 다시 쓰기 (%s 이후 ~ HEAD, 작성자·커미터 → %s <%s>)", len(plan.Commits), base, name, email)
+	for _, c := range plan.Wrong {
+		fmt.Fprintf(&b, "\n       %s  %s <%s>  %s", c.Short(), c.AuthorName, c.AuthorEmail, c.Subject)
+	}
+	return b.String()
+}
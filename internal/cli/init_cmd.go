@@ -7,8 +7,8 @@ import (
 	"path/filepath"
 	"time"
 
+	"github.com/hbjs97/ctx/internal/audit"
 	"github.com/hbjs97/ctx/internal/cache"
-	"github.com/hbjs97/ctx/internal/config"
 	"github.com/hbjs97/ctx/internal/gh"
 	"github.com/hbjs97/ctx/internal/git"
 	"github.com/hbjs97/ctx/internal/guard"
@@ -28,7 +28,7 @@ func (a *App) newInitCmd() *cobra.Command {
 		},
 	}
 	cmd.Flags().StringVarP(&profileFlag, "profile", "p", "", "사용할 프로필 이름")
-	cmd.Flags().BoolVar(&noGuard, "no-guard", false, "pre-push guard 설치 생략")
+	cmd.Flags().BoolVar(&noGuard, "no-guard", false, "pre-push/pre-commit guard 설치 생략")
 	return cmd
 }
 
@@ -38,7 +38,7 @@ func (a *App) runInit(ctx context.Context, profileFlag string, noGuard bool) err
 		return fmt.Errorf("cli.init: %w", err)
 	}
 
-	cfg, err := config.Load(a.CfgPath)
+	cfg, err := a.loadConfig()
 	if err != nil {
 		return err
 	}
@@ -67,6 +67,10 @@ func (a *App) runInit(ctx context.Context, profileFlag string, noGuard bool) err
 	if err != nil {
 		return err
 	}
+	a.recordAudit(audit.Record{Event: audit.EventResolve, Repo: ownerRepo, Dir: cwd, Remote: remoteURL, Profile: result.Profile, Reason: result.Reason})
+	if result.Reason == "probe" {
+		a.suggestOwnerSync(ctx, cfg, result.Profile, ref.Owner)
+	}
 
 	profile, _ := cfg.GetProfile(result.Profile) // Resolve 성공이면 프로필 존재 보장
 
@@ -88,14 +92,25 @@ func (a *App) runInit(ctx context.Context, profileFlag string, noGuard bool) err
 	if !noGuard && cfg.IsRequirePushGuard() {
 		_ = guard.InstallHook(cwd) // guard 설치 실패는 치명적이지 않음
 	}
+	// require_commit_guard를 끈 뒤 다시 init하면 이전에 설치한 pre-commit guard를 제거한다
+	if !noGuard && cfg.RequireCommitGuard {
+		_ = guard.InstallCommitHook(cwd) // guard 설치 실패는 치명적이지 않음
+	} else if !noGuard {
+		_ = guard.UninstallCommitHook(cwd) // guard 제거 실패는 치명적이지 않음
+	}
 
-	c.Set(ownerRepo, cache.Entry{
+	entry := cache.Entry{
 		Profile:    result.Profile,
 		Reason:     result.Reason,
 		ResolvedAt: time.Now().Format(time.RFC3339),
 		ConfigHash: cfg.ConfigHash(),
+	}
+	// 동시에 실행된 다른 ctx가 추가한 항목을 잃지 않도록 lock 후 다시 읽어 갱신한다.
+	// 캐시 저장 실패는 치명적이지 않으므로 에러는 무시한다.
+	_ = cache.Update(a.cachePath(), func(c *cache.Cache) error {
+		c.Set(ownerRepo, entry)
+		return nil
 	})
-	_ = c.Save(a.cachePath()) // 캐시 저장 실패는 치명적이지 않음
 
 	fmt.Printf("초기화 완료: %s → 프로필: %s (판정: %s)\n", ownerRepo, result.Profile, result.Reason)
 	return nil
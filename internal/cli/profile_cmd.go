@@ -0,0 +1,299 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/hbjs97/ctx/internal/cache"
+	"github.com/hbjs97/ctx/internal/config"
+	"github.com/hbjs97/ctx/internal/gh"
+	"github.com/hbjs97/ctx/internal/setup"
+	"github.com/spf13/cobra"
+)
+
+func (a *App) newProfileCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "profile",
+		Short: "프로필 관리 (비대화형)",
+	}
+	cmd.AddCommand(a.newProfileAddCmd(), a.newProfileRefreshCmd(), a.newProfileSyncCmd())
+	return cmd
+}
+
+func (a *App) newProfileAddCmd() *cobra.Command {
+	var spec setup.ProfileSpec
+	var yes bool
+
+	cmd := &cobra.Command{
+		Use:   "add",
+		Short: "플래그로 프로필을 비대화형 추가한다",
+		Example: `  ctx profile add --name work --git-name "HBJS" --email hbjs@company.com \
+    --ssh-key existing:~/.ssh/id_work --owners acme`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return a.runHeadlessSetup(cmd.Context(), []setup.ProfileSpec{spec}, false, false, false, yes)
+		},
+	}
+	cmd.Flags().StringVar(&spec.Name, "name", "", "프로필 이름")
+	cmd.Flags().StringVar(&spec.GitName, "git-name", "", "git user.name")
+	cmd.Flags().StringVar(&spec.GitEmail, "email", "", "git user.email")
+	cmd.Flags().StringVar(&spec.SSHKey, "ssh-key", "", "SSH 키 (existing:<경로> | generate | skip)")
+	cmd.Flags().StringVar(&spec.SSHHost, "ssh-host", "", "기존 SSH Host alias (--ssh-key skip일 때)")
+	cmd.Flags().StringSliceVar(&spec.Owners, "owners", nil, "GitHub 조직/사용자 (콤마 구분, 생략 시 자동 감지)")
+	cmd.Flags().BoolVar(&yes, "yes", false, "확인 프롬프트 자동 승인")
+	_ = cmd.MarkFlagRequired("name")     // 플래그 정의 직후이므로 실패하지 않음
+	_ = cmd.MarkFlagRequired("git-name") // 플래그 정의 직후이므로 실패하지 않음
+	_ = cmd.MarkFlagRequired("email")    // 플래그 정의 직후이므로 실패하지 않음
+	return cmd
+}
+
+func (a *App) newProfileRefreshCmd() *cobra.Command {
+	var yes bool
+	cmd := &cobra.Command{
+		Use:   "refresh [name]",
+		Short: "gh 로그인을 조회해 프로필의 github_user를 갱신한다",
+		Long: `gh 로그인을 조회해 프로필의 github_user를 갱신한다.
+
+이름을 생략하면 모든 프로필을 갱신한다. github_user가 비어 있으면 조회한
+로그인을 기록하고, 이미 기록된 값과 다르면 gh 설정 디렉토리가 다른 계정으로
+로그인된 것일 수 있으므로 경고한 뒤 확인을 받아 바꾼다.`,
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			var name string
+			if len(args) > 0 {
+				name = args[0]
+			}
+			return a.runProfileRefresh(cmd.Context(), name, yes, &setup.HuhFormRunner{})
+		},
+	}
+	cmd.Flags().BoolVar(&yes, "yes", false, "다른 계정으로 바뀐 github_user도 확인 없이 갱신")
+	return cmd
+}
+
+func (a *App) runProfileRefresh(ctx context.Context, name string, yes bool, form setup.FormRunner) error {
+	cfg, err := a.loadConfig()
+	if err != nil {
+		return err
+	}
+	names, err := profileNames(cfg, name)
+	if err != nil {
+		return err
+	}
+
+	adapter := gh.NewAdapter(a.Commander)
+	updates := make(map[string]string)
+	for _, n := range names {
+		p := cfg.Profiles[n]
+		login, err := adapter.Login(ctx, p.GHConfigDir)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "경고: 프로필 %s의 gh 로그인 조회 실패: %v\n", n, err)
+			continue
+		}
+		switch {
+		case p.GitHubUser == "":
+			fmt.Printf("%s: github_user = %s\n", n, login)
+			updates[n] = login
+		case strings.EqualFold(p.GitHubUser, login):
+			fmt.Printf("%s: github_user = %s (변경 없음)\n", n, p.GitHubUser)
+		default:
+			fmt.Fprintf(os.Stderr, "경고: %s가 %s로 로그인되어 있지만 프로필 %s의 github_user는 %s\n", p.GHConfigDir, login, n, p.GitHubUser)
+			ok := yes
+			if !ok {
+				ok, err = form.RunConfirm(fmt.Sprintf("프로필 %s의 github_user를 %s로 바꾸시겠습니까? (다른 계정이면 GH_CONFIG_DIR=%s gh auth login)", n, login, p.GHConfigDir))
+				if err != nil {
+					return err
+				}
+			}
+			if ok {
+				fmt.Printf("%s: github_user = %s → %s\n", n, p.GitHubUser, login)
+				updates[n] = login
+			}
+		}
+	}
+	if len(updates) == 0 {
+		return nil
+	}
+
+	return a.updateConfig(func(cfg *config.Config) error {
+		for n, login := range updates {
+			p, ok := cfg.Profiles[n]
+			if !ok {
+				continue // 조회하는 동안 삭제된 프로필
+			}
+			p.GitHubUser = login
+			cfg.Profiles[n] = p
+		}
+		return nil
+	})
+}
+
+// ownerSyncInterval은 probe 판정 후 조직을 다시 조회해 owners 갱신을 제안하는 최소 간격이다.
+const ownerSyncInterval = 24 * time.Hour
+
+func (a *App) newProfileSyncCmd() *cobra.Command {
+	var yes, prune bool
+	cmd := &cobra.Command{
+		Use:   "sync [name]",
+		Short: "소속 조직을 다시 조회해 프로필의 owners 갱신을 제안한다",
+		Long: `소속 조직을 다시 조회해 프로필의 owners 갱신을 제안한다.
+
+이름을 생략하면 모든 프로필을 확인한다. gh api user/orgs 결과에 있지만
+owners에 없는 조직은 추가를, owners에 있지만 더 이상 소속되지 않은 항목은
+--prune일 때 제거를 확인받아 적용한다. github_user는 owner 매칭에 자동
+포함되므로 비교에서 제외한다. 조직 조회에 실패한 프로필은 건너뛴다.`,
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			var name string
+			if len(args) > 0 {
+				name = args[0]
+			}
+			return a.runProfileSync(cmd.Context(), name, yes, prune, &setup.HuhFormRunner{})
+		},
+	}
+	cmd.Flags().BoolVar(&yes, "yes", false, "확인 프롬프트 자동 승인")
+	cmd.Flags().BoolVar(&prune, "prune", false, "소속되지 않은 owners 제거도 제안")
+	return cmd
+}
+
+func (a *App) runProfileSync(ctx context.Context, name string, yes, prune bool, form setup.FormRunner) error {
+	cfg, err := a.loadConfig()
+	if err != nil {
+		return err
+	}
+	names, err := profileNames(cfg, name)
+	if err != nil {
+		return err
+	}
+
+	confirm := func(msg string) (bool, error) {
+		if yes {
+			return true, nil
+		}
+		return form.RunConfirm(msg)
+	}
+
+	adapter := gh.NewAdapter(a.Commander)
+	updates := make(map[string][]string)
+	var synced []string
+	for _, n := range names {
+		p := cfg.Profiles[n]
+		orgs, err := adapter.Orgs(ctx, p.GHConfigDir)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "경고: 프로필 %s의 조직 조회 실패: %v\n", n, err)
+			continue
+		}
+		synced = append(synced, n)
+		login := p.GitHubUser
+		if login == "" {
+			login, _ = adapter.Login(ctx, p.GHConfigDir) // 자기 계정을 제거 후보로 오인하지 않기 위한 조회
+		}
+
+		add, remove := setup.DiffOwners(p.Owners, login, orgs)
+		if len(add) == 0 && (len(remove) == 0 || !prune) {
+			fmt.Printf("%s: owners 변경 없음\n", n)
+			if len(remove) > 0 {
+				fmt.Printf("  소속 조직에 없는 owners: %s (제거하려면 --prune)\n", strings.Join(remove, ", "))
+			}
+			continue
+		}
+
+		owners := slices.Clone(p.Owners)
+		if len(add) > 0 {
+			fmt.Printf("%s: 새 조직 %s\n", n, strings.Join(add, ", "))
+			ok, err := confirm(fmt.Sprintf("프로필 %s의 owners에 %s를 추가하시겠습니까?", n, strings.Join(add, ", ")))
+			if err != nil {
+				return err
+			}
+			if ok {
+				owners = append(owners, add...)
+			}
+		}
+		if len(remove) > 0 && prune {
+			fmt.Printf("%s: 소속 조직에 없는 owners %s\n", n, strings.Join(remove, ", "))
+			ok, err := confirm(fmt.Sprintf("프로필 %s의 owners에서 %s를 제거하시겠습니까?", n, strings.Join(remove, ", ")))
+			if err != nil {
+				return err
+			}
+			if ok {
+				owners = slices.DeleteFunc(owners, func(o string) bool { return slices.Contains(remove, o) })
+			}
+		}
+		if !slices.Equal(owners, p.Owners) {
+			fmt.Printf("%s: owners = %s\n", n, strings.Join(owners, ", "))
+			updates[n] = owners
+		}
+	}
+
+	now := time.Now()
+	// 조회 시각 기록 실패는 다음 probe에서 다시 제안할 뿐이므로 무시한다.
+	_ = cache.Update(a.cachePath(), func(c *cache.Cache) error {
+		for _, n := range synced {
+			c.MarkOwnerSync(n, now)
+		}
+		return nil
+	})
+
+	if len(updates) == 0 {
+		return nil
+	}
+	return a.updateConfig(func(cfg *config.Config) error {
+		for n, owners := range updates {
+			p, ok := cfg.Profiles[n]
+			if !ok {
+				continue // 조회하는 동안 삭제된 프로필
+			}
+			p.Owners = owners
+			cfg.Profiles[n] = p
+		}
+		return nil
+	})
+}
+
+// suggestOwnerSync는 권한 probe로 판정된 owner가 판정된 프로필의 소속 조직인데
+// owners에 없으면 ctx profile sync를 제안한다. 조직 조회는 프로필마다
+// ownerSyncInterval에 한 번만 하며, 실패해도 명령을 막지 않는다.
+func (a *App) suggestOwnerSync(ctx context.Context, cfg *config.Config, profileName, owner string) {
+	now := time.Now()
+	var due bool
+	err := cache.Update(a.cachePath(), func(c *cache.Cache) error {
+		if due = c.OwnerSyncDue(profileName, now, ownerSyncInterval); due {
+			c.MarkOwnerSync(profileName, now)
+		}
+		return nil
+	})
+	if err != nil || !due {
+		return
+	}
+
+	p := cfg.Profiles[profileName]
+	orgs, err := gh.NewAdapter(a.Commander).Orgs(ctx, p.GHConfigDir)
+	if err != nil {
+		return
+	}
+	add, _ := setup.DiffOwners(p.Owners, p.GitHubUser, orgs)
+	if !slices.ContainsFunc(add, func(o string) bool { return strings.EqualFold(o, owner) }) {
+		return // 소속 조직이 아니거나 이미 owners에 있음 (여러 프로필에 걸친 owner)
+	}
+	fmt.Printf("참고: %s는 프로필 %s의 소속 조직이지만 owners에 없어 권한 probe로 판정했습니다.\n", owner, profileName)
+	fmt.Printf("  'ctx profile sync %s'로 owners에 추가하면 다음부터 probe 없이 판정합니다 (새 조직: %s)\n", profileName, strings.Join(add, ", "))
+}
+
+// profileNames는 name이 있으면 그 프로필만, 없으면 모든 프로필 이름을 정렬해 반환한다.
+func profileNames(cfg *config.Config, name string) ([]string, error) {
+	if name != "" {
+		if _, err := cfg.GetProfile(name); err != nil {
+			return nil, err
+		}
+		return []string{name}, nil
+	}
+	names := make([]string, 0, len(cfg.Profiles))
+	for n := range cfg.Profiles {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+	return names, nil
+}
@@ -6,6 +6,7 @@ import (
 	"path/filepath"
 
 	"github.com/hbjs97/ctx/internal/cmdexec"
+	"github.com/hbjs97/ctx/internal/config"
 	"github.com/spf13/cobra"
 )
 
@@ -14,6 +15,14 @@ type App struct {
 	Commander cmdexec.Commander
 	CfgPath   string
 	Verbose   bool
+	// PolicyPath는 팀 정책 파일 경로다. 비어 있으면 config.PolicyPath()를 사용한다.
+	PolicyPath string
+	// AuditPath는 감사 로그 경로다. 비어 있으면 audit.DefaultPath()를 사용한다.
+	AuditPath string
+
+	SSHConfigPath string // 테스트용. 비어있으면 ~/.ssh/config.
+	GitConfigPath string // 테스트용. 비어있으면 ~/.gitconfig.
+	GitIncludeDir string // 테스트용. 비어있으면 ~/.config/ctx/git.
 }
 
 // NewApp creates an App with default production dependencies.
@@ -48,6 +57,9 @@ func (a *App) NewRootCmd() *cobra.Command {
 		a.newGuardCmd(),
 		a.newActivateCmd(),
 		a.newSetupCmd(),
+		a.newProfileCmd(),
+		a.newConfigCmd(),
+		a.newAuditCmd(),
 	)
 	return cmd
 }
@@ -81,3 +93,32 @@ func (a *App) cachePath() string {
 	}
 	return filepath.Join(homeDir(), ".config", "ctx", "cache.json")
 }
+
+func (a *App) policyPath() string {
+	if a.PolicyPath != "" {
+		return a.PolicyPath
+	}
+	return config.PolicyPath()
+}
+
+// loadLayered는 팀 정책을 적용한 설정을 읽는다.
+func (a *App) loadLayered() (*config.Layered, *config.Policy, error) {
+	policy, err := config.LoadPolicy(a.policyPath())
+	if err != nil {
+		return nil, nil, err
+	}
+	l, err := config.LoadLayered(a.CfgPath, policy)
+	if err != nil {
+		return nil, nil, err
+	}
+	return l, policy, nil
+}
+
+// loadConfig는 팀 정책을 적용한 설정을 읽는다. 명령은 config.Load 대신 이것을 사용한다.
+func (a *App) loadConfig() (*config.Config, error) {
+	l, _, err := a.loadLayered()
+	if err != nil {
+		return nil, err
+	}
+	return l.Config, nil
+}
@@ -11,32 +11,105 @@ import (
 
 func (a *App) newSetupCmd() *cobra.Command {
 	var force bool
+	var includeIf bool
+	var importExisting bool
+	var specPath string
+	var shellHook bool
+	var yes bool
 
 	cmd := &cobra.Command{
 		Use:   "setup",
 		Short: "ctx 초기 설정을 시작한다",
 		RunE: func(cmd *cobra.Command, args []string) error {
-			return a.runSetup(cmd.Context(), force)
+			if specPath != "" {
+				spec, err := setup.LoadSpec(specPath)
+				if err != nil {
+					return err
+				}
+				return a.runHeadlessSetup(cmd.Context(), spec.Profiles, force, includeIf, shellHook, yes)
+			}
+			return a.runSetup(cmd.Context(), force, includeIf, importExisting)
 		},
 	}
 
 	cmd.Flags().BoolVar(&force, "force", false, "기존 설정을 무시하고 재설정")
+	cmd.Flags().BoolVar(&importExisting, "import", false, "기존 includeIf, gh hosts.yml, SSH config에서 프로필 가져오기")
+	cmd.Flags().StringVar(&specPath, "from", "", "선언적 프로필 파일(TOML)로 비대화형 설정")
+	cmd.Flags().BoolVar(&yes, "yes", false, "비대화형 설정 시 확인 프롬프트 자동 승인")
+	cmd.Flags().BoolVar(&shellHook, "shell-hook", false, "비대화형 설정 시 셸 hook을 RC 파일에 설치")
+	cmd.Flags().BoolVar(&includeIf, "include-if", false, "프로필별 gitconfig 파일과 ~/.gitconfig includeIf 항목 생성")
 	return cmd
 }
 
 // runSetup는 interactive setup wizard를 실행한다.
-func (a *App) runSetup(ctx context.Context, force bool) error {
+func (a *App) runSetup(ctx context.Context, force, includeIf, importExisting bool) error {
 	if force {
 		if err := os.Remove(a.CfgPath); err != nil && !os.IsNotExist(err) {
 			return fmt.Errorf("cli.setup: 기존 설정 파일 제거 실패: %w", err)
 		}
 	}
 
-	r := &setup.Runner{
-		CfgPath:    a.CfgPath,
-		Commander:  a.Commander,
-		FormRunner: &setup.HuhFormRunner{},
-	}
+	r := a.setupRunner(&setup.HuhFormRunner{})
+	r.IncludeIf = includeIf
+	r.Import = importExisting
 
 	return r.Run(ctx)
 }
+
+// runHeadlessSetup는 프로필 선언 목록으로 TUI 없이 setup을 실행한다.
+// 입력이 부족하면 프롬프트 대신 setup.ErrNonInteractive로 실패한다.
+// 셸 RC 파일은 shellHook이 true일 때만 수정한다.
+func (a *App) runHeadlessSetup(ctx context.Context, specs []setup.ProfileSpec, force, includeIf, shellHook, yes bool) error {
+	if force {
+		if err := os.Remove(a.CfgPath); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("cli.setup: 기존 설정 파일 제거 실패: %w", err)
+		}
+	}
+
+	h := &setup.HeadlessFormRunner{Specs: specs, Yes: yes}
+	r := a.setupRunner(h)
+	r.IncludeIf = includeIf
+	r.NonInteractive = true
+	r.ShellHook = shellHook
+
+	// 첫 실행은 모든 선언을 한 번에 처리하고, 기존 설정이 있으면 선언마다 프로필 추가를 반복한다.
+	// Run마다 선언을 하나 이상 소비해야 하므로 실행 횟수는 선언 수를 넘지 않는다.
+	for range specs {
+		left := h.Remaining()
+		if left == 0 {
+			return nil
+		}
+		if err := r.Run(ctx); err != nil {
+			return err
+		}
+		if h.Remaining() >= left {
+			return fmt.Errorf("cli.setup: 프로필 선언 %d개가 처리되지 않음", left)
+		}
+	}
+	return nil
+}
+
+// setupRunner는 App의 경로 설정을 반영한 setup.Runner를 만든다.
+func (a *App) setupRunner(form setup.FormRunner) *setup.Runner {
+	return &setup.Runner{
+		CfgPath:       a.CfgPath,
+		Commander:     a.Commander,
+		FormRunner:    form,
+		SSHConfigPath: a.SSHConfigPath,
+		GitConfigPath: a.GitConfigPath,
+		GitIncludeDir: a.GitIncludeDir,
+	}
+}
+
+// syncProfiles는 저장된 설정으로 ctx SSH include 파일과 includeIf 파일을 다시 맞춘다.
+// ctx config set/unset/edit처럼 setup을 거치지 않고 설정을 바꾼 뒤 호출한다.
+func (a *App) syncProfiles() error {
+	cfg, err := a.loadConfig()
+	if err != nil {
+		return err
+	}
+	if err := a.setupRunner(nil).SyncProfiles(cfg); err != nil {
+		return fmt.Errorf("cli.config: 설정은 저장됨: %w", err)
+	}
+	return nil
+}
@@ -57,3 +57,72 @@ func TestSetupCmd_ForceRemovesExistingConfig(t *testing.T) {
 	_, err = os.Stat(cfgPath)
 	assert.True(t, os.IsNotExist(err), "config file should have been removed by --force")
 }
+
+func TestSetupCmd_FromSpec_LeavesShellRCUntouched(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("SHELL", "/bin/bash")
+	dir := t.TempDir()
+	cfgPath := filepath.Join(dir, "config.toml")
+	specPath := filepath.Join(dir, "profiles.toml")
+	require.NoError(t, os.WriteFile(specPath, []byte(`
+[[profiles]]
+name = "personal"
+git_name = "Me"
+git_email = "me@example.com"
+ssh_host = "github.com-personal"
+`), 0600))
+
+	fc := testutil.NewFakeCommander()
+	fc.Register("gh api user/orgs --jq .[].login", "", nil)
+	fc.Register("gh api user --jq .login", "me\n", nil)
+	fc.DefaultResponse = &testutil.Response{Output: []byte("ok")}
+
+	app := &cli.App{Commander: fc, CfgPath: cfgPath, SSHConfigPath: filepath.Join(dir, "ssh_config")}
+	cmd := app.NewRootCmd()
+	cmd.SetArgs([]string{"--config", cfgPath, "setup", "--from", specPath})
+	require.NoError(t, cmd.Execute())
+
+	_, err := os.Stat(cfgPath)
+	require.NoError(t, err)
+	_, err = os.Stat(filepath.Join(home, ".bashrc"))
+	assert.True(t, os.IsNotExist(err), "--shell-hook 없이는 RC 파일을 수정하지 않는다")
+}
+
+func TestProfileAddCmd_NonInteractive(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("SHELL", "")
+	dir := t.TempDir()
+	cfgPath := writeTestConfig(t, dir)
+
+	fc := testutil.NewFakeCommander()
+	fc.Register("gh auth status", "Logged in", nil)
+	fc.Register("gh api user/orgs --jq .[].login", "", nil)
+	fc.Register("gh api user --jq .login", "free\n", nil)
+	fc.DefaultResponse = &testutil.Response{Output: []byte("ok")}
+
+	app := &cli.App{Commander: fc, CfgPath: cfgPath}
+	cmd := app.NewRootCmd()
+	cmd.SetArgs([]string{"--config", cfgPath, "profile", "add",
+		"--name", "freelance", "--git-name", "Free", "--email", "free@example.com",
+		"--ssh-key", "skip", "--ssh-host", "github.com-free", "--owners", "client-a,client-b"})
+
+	require.NoError(t, cmd.Execute())
+
+	data, err := os.ReadFile(cfgPath)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), "[profiles.freelance]")
+	assert.Contains(t, string(data), "github.com-free")
+	assert.Contains(t, string(data), "client-b")
+}
+
+func TestProfileAddCmd_MissingRequiredFlag(t *testing.T) {
+	dir := t.TempDir()
+	cfgPath := writeTestConfig(t, dir)
+
+	app := &cli.App{Commander: testutil.NewFakeCommander(), CfgPath: cfgPath}
+	cmd := app.NewRootCmd()
+	cmd.SetArgs([]string{"--config", cfgPath, "profile", "add", "--name", "x"})
+
+	assert.Error(t, cmd.Execute())
+}
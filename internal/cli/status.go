@@ -7,7 +7,6 @@ import (
 	"path/filepath"
 	"strings"
 
-	"github.com/hbjs97/ctx/internal/config"
 	"github.com/hbjs97/ctx/internal/git"
 	"github.com/spf13/cobra"
 )
@@ -37,7 +36,7 @@ func (a *App) runStatus(ctx context.Context) error {
 	}
 	profileName := strings.TrimSpace(string(data))
 
-	cfg, err := config.Load(a.CfgPath)
+	cfg, err := a.loadConfig()
 	if err != nil {
 		return err
 	}
@@ -53,6 +52,9 @@ func (a *App) runStatus(ctx context.Context) error {
 	fmt.Printf("  git name:  %s\n", profile.GitName)
 	fmt.Printf("  git email: %s\n", profile.GitEmail)
 	fmt.Printf("  SSH host:  %s\n", profile.SSHHost)
+	if profile.GitHubUser != "" {
+		fmt.Printf("  GitHub:    %s\n", profile.GitHubUser)
+	}
 
 	// Show current remote URL
 	if remote, err := gitAdapter.GetRemoteURL(ctx, cwd, "origin"); err == nil {
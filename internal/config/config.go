@@ -1,74 +1,205 @@
 package config
 
 import (
-	"bytes"
 	"crypto/sha256"
 	"errors"
 	"fmt"
 	"os"
+	"path"
 	"path/filepath"
+	"slices"
 	"sort"
+	"strings"
+	"time"
 
-	"github.com/BurntSushi/toml"
+	"github.com/hbjs97/ctx/internal/fsutil"
 )
 
 // ErrConfig는 설정 파일 오류를 나타내는 sentinel error다.
 var ErrConfig = errors.New("설정 오류")
 
+// defaultCacheTTLDays는 cache_ttl_days가 없을 때 쓰는 캐시 유효 기간이다.
+const defaultCacheTTLDays = 90
+
+// defaultSSHIdentityTTLMinutes는 ssh_identity_ttl_minutes가 없을 때 guard ssh_identity 검사 결과를 재사용하는 기간이다.
+const defaultSSHIdentityTTLMinutes = 60
+
 // Config는 ctx 설정 파일의 최상위 구조체다.
 type Config struct {
 	Version               int                `toml:"version"`
 	DefaultProfile        string             `toml:"default_profile"`
 	PromptOnAmbiguous     *bool              `toml:"prompt_on_ambiguous"`
 	RequirePushGuard      *bool              `toml:"require_push_guard"`
+	AllowEnvBypass        *bool              `toml:"allow_env_bypass"`
+	RequireCommitGuard    bool               `toml:"require_commit_guard"`
 	AllowHTTPSManagedRepo bool               `toml:"allow_https_managed_repo"`
 	CacheTTLDays          int                `toml:"cache_ttl_days"`
+	SSHIdentityTTLMinutes int                `toml:"ssh_identity_ttl_minutes"`
+	GitIncludeIf          bool               `toml:"git_include_if"`
+	SSHIncludePath        string             `toml:"ssh_include_path"`
+	Guard                 GuardChecks        `toml:"guard"`
+	EmailRules            []EmailRule        `toml:"email_rules"`
 	Profiles              map[string]Profile `toml:"profiles"`
 }
 
 // Profile은 하나의 GitHub 계정 프로필이다.
 type Profile struct {
-	GHConfigDir string   `toml:"gh_config_dir"`
-	SSHHost     string   `toml:"ssh_host"`
-	GitName     string   `toml:"git_name"`
-	GitEmail    string   `toml:"git_email"`
-	Owners []string `toml:"owners"`
+	GHConfigDir  string   `toml:"gh_config_dir"`
+	SSHHost      string   `toml:"ssh_host"`
+	IdentityFile string   `toml:"identity_file"`
+	GitName      string   `toml:"git_name"`
+	GitEmail     string   `toml:"git_email"`
+	Owners       []string `toml:"owners"`
+	GitDirs      []string `toml:"git_dirs"`
+	SigningKey   string   `toml:"signing_key"`
+	// GitHubUser는 gh_config_dir이 인증된 GitHub 로그인이다. setup과 ctx profile refresh가 기록한다.
+	GitHubUser string `toml:"github_user"`
+	// ProtectedBranches는 이 프로필로 직접 push할 수 없는 브랜치 패턴이다 (예: "main", "release/*").
+	ProtectedBranches []string    `toml:"protected_branches"`
+	Guard             GuardChecks `toml:"guard"`
 }
 
-// Load는 config.toml을 파싱하여 Config를 반환한다.
-func Load(path string) (*Config, error) {
-	if err := ValidateFilePermissions(path); err != nil {
-		fmt.Fprintf(os.Stderr, "경고: %v\n", err)
+// EmailRule은 guard가 프로필과 무관하게 커밋 이메일에 적용하는 규칙이다.
+// Owners가 비어 있으면 모든 리포에 적용된다. 패턴은 path.Match 형식이며 "@"로 시작하면 "*@..."와 같다.
+//
+//	[[email_rules]]
+//	name = "acme-only"
+//	owners = ["acme"]
+//	allow = ["*@acme.com"]
+//	require_signing = true
+type EmailRule struct {
+	Name           string   `toml:"name"`
+	Owners         []string `toml:"owners"`
+	Allow          []string `toml:"allow"`
+	Deny           []string `toml:"deny"`
+	RequireSigning bool     `toml:"require_signing"`
+}
+
+// Label은 guard 출력에 쓸 규칙 이름을 반환한다. name이 없으면 "email_rules[i]"다.
+func (r EmailRule) Label(i int) string {
+	if r.Name != "" {
+		return r.Name
+	}
+	return fmt.Sprintf("email_rules[%d]", i)
+}
+
+// AppliesTo는 규칙이 owner의 리포에 적용되는지 반환한다. owner는 대소문자를 구분하지 않는다.
+func (r EmailRule) AppliesTo(owner string) bool {
+	if len(r.Owners) == 0 {
+		return true
 	}
+	return slices.ContainsFunc(r.Owners, func(o string) bool { return strings.EqualFold(o, owner) })
+}
 
-	var cfg Config
-	if _, err := toml.DecodeFile(path, &cfg); err != nil {
-		return nil, fmt.Errorf("config.Load: %w", err)
+// MatchEmail은 email이 pattern에 맞는지 반환한다. 대소문자를 구분하지 않는다.
+func MatchEmail(pattern, email string) bool {
+	if strings.HasPrefix(pattern, "@") {
+		pattern = "*" + pattern
 	}
-	cfg.applyDefaults()
-	if err := cfg.validate(); err != nil {
+	ok, _ := path.Match(strings.ToLower(pattern), strings.ToLower(email)) // 잘못된 패턴은 Validate가 error로 보고한다
+	return ok
+}
+
+// Load는 config.toml을 파싱하여 Config를 반환한다.
+// 이전 버전 설정은 메모리에서만 마이그레이션하며, 지원하지 않는 새 버전은 거부한다.
+// 팀 정책을 함께 적용하려면 LoadLayered를 사용한다.
+func Load(path string) (*Config, error) {
+	l, err := LoadLayered(path, nil)
+	if err != nil {
 		return nil, err
 	}
-	return &cfg, nil
+	return l.Config, nil
 }
 
 // Save는 Config를 TOML 형식으로 파일에 저장한다.
+// 파일이 이미 있으면 바뀐 키만 고쳐 써서 주석, 빈 줄, 키 순서와 알 수 없는 키를 보존한다.
+// 저장 후 Load가 실패하는 설정(error 수준 문제가 있는 설정)은 쓰지 않는다.
+// 쓰기는 lock을 잡고 임시 파일 rename으로 원자적으로 한다.
 func Save(path string, cfg *Config) error {
-	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+	lock, err := lockConfig(path)
+	if err != nil {
 		return fmt.Errorf("config.Save: %w", err)
 	}
+	defer lock.Unlock()
 
-	var buf bytes.Buffer
-	if err := toml.NewEncoder(&buf).Encode(cfg); err != nil {
+	if err := save(path, cfg); err != nil {
 		return fmt.Errorf("config.Save: %w", err)
 	}
+	return nil
+}
 
-	if err := os.WriteFile(path, buf.Bytes(), 0600); err != nil {
-		return fmt.Errorf("config.Save: %w", err)
+// Update는 설정 파일을 lock한 상태에서 검증 없이 다시 읽어 fn을 적용하고 저장한다.
+// 읽기와 쓰기 사이에 다른 ctx 프로세스가 쓴 변경을 덮어쓰지 않는다.
+func Update(path string, fn func(*Config) error) error {
+	lock, err := lockConfig(path)
+	if err != nil {
+		return fmt.Errorf("config.Update: %w", err)
+	}
+	defer lock.Unlock()
+
+	cfg, err := Decode(path)
+	if err != nil {
+		return fmt.Errorf("config.Update: %w: %w", err, ErrConfig)
+	}
+	if err := fn(cfg); err != nil {
+		return fmt.Errorf("config.Update: %w", err)
+	}
+	if err := save(path, cfg); err != nil {
+		return fmt.Errorf("config.Update: %w", err)
 	}
 	return nil
 }
 
+// SaveDocument는 사용자가 직접 편집한 TOML 문서를 검증한 뒤 내용 그대로 저장한다.
+func SaveDocument(path string, doc []byte) error {
+	cfg, _, err := decodeDocument(doc)
+	if err != nil {
+		return fmt.Errorf("config.SaveDocument: %w: %w", err, ErrConfig)
+	}
+	if err := IssuesError(cfg.Validate(nil)); err != nil {
+		return fmt.Errorf("config.SaveDocument: %w", err)
+	}
+
+	lock, err := lockConfig(path)
+	if err != nil {
+		return fmt.Errorf("config.SaveDocument: %w", err)
+	}
+	defer lock.Unlock()
+
+	if err := fsutil.WriteFileAtomic(path, doc, 0600); err != nil {
+		return fmt.Errorf("config.SaveDocument: %w", err)
+	}
+	return nil
+}
+
+// lockConfig는 설정 디렉토리를 만들고 설정 파일 lock을 잡는다.
+func lockConfig(path string) (*fsutil.FileLock, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return nil, err
+	}
+	return fsutil.Lock(path)
+}
+
+// save는 lock을 잡은 상태에서 기존 문서를 읽어 고쳐 쓴다.
+// 기존 문서가 이전 버전이면 고쳐 쓰기 전에 Migrate와 같은 경로로 백업한다.
+func save(path string, cfg *Config) error {
+	if err := IssuesError(cfg.Validate(nil)); err != nil {
+		return err
+	}
+	doc, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	if err := backupOlderVersion(path, doc); err != nil {
+		return err
+	}
+	out, err := render(doc, cfg)
+	if err != nil {
+		return err
+	}
+	return fsutil.WriteFileAtomic(path, out, 0600)
+}
+
 // IsPromptOnAmbiguous는 prompt_on_ambiguous 설정값을 반환한다.
 func (c *Config) IsPromptOnAmbiguous() bool {
 	if c.PromptOnAmbiguous == nil {
@@ -85,6 +216,23 @@ func (c *Config) IsRequirePushGuard() bool {
 	return *c.RequirePushGuard
 }
 
+// IsEnvBypassAllowed는 allow_env_bypass 설정값을 반환한다.
+// false면 CTX_SKIP_GUARD=1로 guard를 우회할 수 없고 ctx guard allow만 쓸 수 있다.
+func (c *Config) IsEnvBypassAllowed() bool {
+	if c.AllowEnvBypass == nil {
+		return true
+	}
+	return *c.AllowEnvBypass
+}
+
+// SSHIdentityTTL은 guard ssh_identity 검사 결과를 재사용하는 기간이다.
+func (c *Config) SSHIdentityTTL() time.Duration {
+	if c.SSHIdentityTTLMinutes <= 0 {
+		return defaultSSHIdentityTTLMinutes * time.Minute
+	}
+	return time.Duration(c.SSHIdentityTTLMinutes) * time.Minute
+}
+
 // ValidateFilePermissions는 파일 권한이 0600보다 넓으면 에러를 반환한다.
 func ValidateFilePermissions(path string) error {
 	info, err := os.Stat(path)
@@ -98,11 +246,20 @@ func ValidateFilePermissions(path string) error {
 	return nil
 }
 
-// MatchOwner는 owner가 포함된 프로필 이름 목록을 반환한다.
+// EffectiveOwners는 owners에 github_user를 더한 owner 목록이다.
+// 자기 계정의 리포는 owners에 적지 않아도 이 프로필의 owner로 본다.
+func (p Profile) EffectiveOwners() []string {
+	if p.GitHubUser == "" || slices.ContainsFunc(p.Owners, func(o string) bool { return strings.EqualFold(o, p.GitHubUser) }) {
+		return p.Owners
+	}
+	return append(slices.Clone(p.Owners), p.GitHubUser)
+}
+
+// MatchOwner는 owner가 포함된 프로필 이름 목록을 반환한다. github_user도 owner로 본다.
 func (c *Config) MatchOwner(owner string) []string {
 	var matches []string
 	for name, p := range c.Profiles {
-		for _, o := range p.Owners {
+		for _, o := range p.EffectiveOwners() {
 			if o == owner {
 				matches = append(matches, name)
 				break
@@ -133,6 +290,9 @@ func (c *Config) ConfigHash() string {
 	for _, k := range keys {
 		p := c.Profiles[k]
 		fmt.Fprintf(h, "%s:%s:%s:%s:%s:%v", k, p.GHConfigDir, p.SSHHost, p.GitEmail, p.GitName, p.Owners)
+		if p.GitHubUser != "" { // github_user가 없던 설정의 해시는 그대로 유지한다
+			fmt.Fprintf(h, ":%s", p.GitHubUser)
+		}
 	}
 	return fmt.Sprintf("%x", h.Sum(nil))[:8]
 }
@@ -146,28 +306,51 @@ func (c *Config) applyDefaults() {
 		t := true
 		c.RequirePushGuard = &t
 	}
+	if c.AllowEnvBypass == nil {
+		t := true
+		c.AllowEnvBypass = &t
+	}
 	if c.CacheTTLDays == 0 {
-		c.CacheTTLDays = 90
+		c.CacheTTLDays = defaultCacheTTLDays
+	}
+	if c.SSHIdentityTTLMinutes == 0 {
+		c.SSHIdentityTTLMinutes = defaultSSHIdentityTTLMinutes
 	}
 }
 
-func (c *Config) validate() error {
-	if len(c.Profiles) == 0 {
-		return fmt.Errorf("config.Load: 프로필이 정의되지 않았습니다: %w", ErrConfig)
+// omitDefaults는 기존 문서(old)에 없던 키가 기본값과 같으면 비운다.
+// Load가 채운 기본값을 Save가 파일에 새로 쓰지 않게 한다.
+func (c *Config) omitDefaults(old *Config) {
+	if old.PromptOnAmbiguous == nil && c.IsPromptOnAmbiguous() {
+		c.PromptOnAmbiguous = nil
 	}
-	for name, p := range c.Profiles {
-		if p.GHConfigDir == "" {
-			return fmt.Errorf("config.Load: profiles.%s.gh_config_dir 필수: %w", name, ErrConfig)
-		}
-		if p.SSHHost == "" {
-			return fmt.Errorf("config.Load: profiles.%s.ssh_host 필수: %w", name, ErrConfig)
-		}
-		if p.GitName == "" {
-			return fmt.Errorf("config.Load: profiles.%s.git_name 필수: %w", name, ErrConfig)
-		}
-		if p.GitEmail == "" {
-			return fmt.Errorf("config.Load: profiles.%s.git_email 필수: %w", name, ErrConfig)
+	if old.RequirePushGuard == nil && c.IsRequirePushGuard() {
+		c.RequirePushGuard = nil
+	}
+	if old.AllowEnvBypass == nil && c.IsEnvBypassAllowed() {
+		c.AllowEnvBypass = nil
+	}
+	if old.CacheTTLDays == 0 && c.CacheTTLDays == defaultCacheTTLDays {
+		c.CacheTTLDays = 0
+	}
+	if old.SSHIdentityTTLMinutes == 0 && c.SSHIdentityTTLMinutes == defaultSSHIdentityTTLMinutes {
+		c.SSHIdentityTTLMinutes = 0
+	}
+}
+
+// validate는 Load가 설정을 거부할 문제를 검사한다.
+// 프로필 간 중복(Conflict)은 저장할 때만 거부하고 읽을 때는 경고로 알린다.
+func (c *Config) validate() error {
+	var blocking []Issue
+	for _, i := range c.Validate(nil) {
+		if i.Conflict && i.Severity == SeverityError {
+			fmt.Fprintf(os.Stderr, "경고: %s. ctx config validate로 확인하세요\n", i)
+			continue
 		}
+		blocking = append(blocking, i)
+	}
+	if err := IssuesError(blocking); err != nil {
+		return fmt.Errorf("config.Load: %w", err)
 	}
 	return nil
 }
@@ -3,6 +3,7 @@ package config_test
 import (
 	"os"
 	"testing"
+	"time"
 
 	"github.com/hbjs97/ctx/internal/config"
 	"github.com/hbjs97/ctx/internal/testutil"
@@ -114,13 +115,18 @@ owners = ["org"]`
 	assert.True(t, cfg.IsRequirePushGuard())
 	assert.False(t, cfg.AllowHTTPSManagedRepo)
 	assert.Equal(t, 90, cfg.CacheTTLDays)
+	assert.True(t, cfg.IsEnvBypassAllowed())
+	assert.False(t, cfg.RequireCommitGuard)
+	assert.Equal(t, time.Hour, cfg.SSHIdentityTTL())
 }
 
 func TestLoadConfig_ExplicitFalse(t *testing.T) {
 	content := `version = 1
 prompt_on_ambiguous = false
 require_push_guard = false
+allow_env_bypass = false
 cache_ttl_days = 30
+ssh_identity_ttl_minutes = 5
 [profiles.work]
 gh_config_dir = "/tmp/gh"
 ssh_host = "h"
@@ -134,7 +140,9 @@ owners = ["o"]`
 	require.NoError(t, err)
 	assert.False(t, cfg.IsPromptOnAmbiguous())
 	assert.False(t, cfg.IsRequirePushGuard())
+	assert.False(t, cfg.IsEnvBypassAllowed())
 	assert.Equal(t, 30, cfg.CacheTTLDays)
+	assert.Equal(t, 5*time.Minute, cfg.SSHIdentityTTL())
 }
 
 func TestValidateFilePermissions(t *testing.T) {
@@ -182,6 +190,17 @@ func TestMatchOwner_MultipleMatch(t *testing.T) {
 	assert.Len(t, matches, 2)
 }
 
+func TestMatchOwner_GitHubUser(t *testing.T) {
+	cfg := &config.Config{
+		Profiles: map[string]config.Profile{
+			"work":     {Owners: []string{"company-org"}, GitHubUser: "hbjs-work"},
+			"personal": {Owners: []string{"hbjs97"}, GitHubUser: "hbjs97"},
+		},
+	}
+	assert.Equal(t, []string{"work"}, cfg.MatchOwner("hbjs-work"), "자기 계정은 owners에 없어도 매칭")
+	assert.Equal(t, []string{"hbjs97"}, cfg.Profiles["personal"].EffectiveOwners(), "이미 있으면 중복 추가하지 않음")
+}
+
 func TestGetProfile_Exists(t *testing.T) {
 	cfg := &config.Config{
 		Profiles: map[string]config.Profile{
@@ -217,4 +236,10 @@ func TestConfigHash(t *testing.T) {
 	cfg.Profiles["work"] = config.Profile{GHConfigDir: "/tmp2", GitEmail: "c@d.com"}
 	hash3 := cfg.ConfigHash()
 	assert.NotEqual(t, hash1, hash3)
+
+	// github_user 추가 → 해시 변경
+	p := cfg.Profiles["work"]
+	p.GitHubUser = "hbjs97"
+	cfg.Profiles["work"] = p
+	assert.NotEqual(t, hash3, cfg.ConfigHash())
 }
@@ -0,0 +1,100 @@
+package config
+
+import "reflect"
+
+// guard 검사 수준.
+const (
+	GuardOff   = "off"   // 검사하지 않음
+	GuardWarn  = "warn"  // 경고만 출력
+	GuardBlock = "block" // push 차단
+)
+
+// GuardChecks는 guard 검사 항목별 수준(GuardOff, GuardWarn, GuardBlock)이다.
+// 빈 값은 상위 계층의 값이나 기본값을 따른다.
+//
+//	[guard]
+//	user_name = "block"
+//	force_push = "warn"
+type GuardChecks struct {
+	RemoteHost      string `toml:"remote_host"`      // origin SSH host ≠ 프로필 ssh_host
+	UserEmail       string `toml:"user_email"`       // user.email ≠ 프로필 git_email
+	UserName        string `toml:"user_name"`        // user.name ≠ 프로필 git_name
+	HTTPSRemote     string `toml:"https_remote"`     // push 대상이 HTTPS remote
+	RemoteOwner     string `toml:"remote_owner"`     // remote owner가 프로필 owners에 없음
+	ProtectedBranch string `toml:"protected_branch"` // 프로필 protected_branches로 직접 push
+	ForcePush       string `toml:"force_push"`       // 원격 커밋을 버리는 push
+	SSHIdentity     string `toml:"ssh_identity"`     // remote SSH alias의 실제 인증 계정 ≠ 프로필 gh 로그인
+}
+
+// DefaultGuardChecks는 아무 설정이 없을 때의 검사 수준을 반환한다.
+// protected_branch는 프로필에 protected_branches가 있을 때만 효과가 있다.
+func DefaultGuardChecks() GuardChecks {
+	return GuardChecks{
+		RemoteHost:      GuardBlock,
+		UserEmail:       GuardBlock,
+		UserName:        GuardWarn,
+		HTTPSRemote:     GuardOff,
+		RemoteOwner:     GuardOff,
+		ProtectedBranch: GuardBlock,
+		ForcePush:       GuardOff,
+		SSHIdentity:     GuardOff,
+	}
+}
+
+// Merge는 over에 값이 있는 항목을 덮어쓴 결과를 반환한다.
+func (g GuardChecks) Merge(over GuardChecks) GuardChecks {
+	gv, ov := reflect.ValueOf(&g).Elem(), reflect.ValueOf(over)
+	for i := range gv.NumField() {
+		if s := ov.Field(i).String(); s != "" {
+			gv.Field(i).SetString(s)
+		}
+	}
+	return g
+}
+
+// Tighten은 t가 더 엄격한 항목만 반영한 결과를 반환한다.
+// 리포에 커밋된 .ctx.toml이 사용자 검사를 끄지 못하게 한다.
+func (g GuardChecks) Tighten(t GuardChecks) GuardChecks {
+	gv, tv := reflect.ValueOf(&g).Elem(), reflect.ValueOf(t)
+	for i := range gv.NumField() {
+		if guardRank(tv.Field(i).String()) > guardRank(gv.Field(i).String()) {
+			gv.Field(i).SetString(tv.Field(i).String())
+		}
+	}
+	return g
+}
+
+// GuardChecksFor는 기본값 ← [guard] ← [profiles.<name>.guard] 순으로 덮어쓴 뒤
+// 리포 .ctx.toml의 [guard]로 더 엄격하게만 바꾼 검사 수준을 반환한다.
+func (c *Config) GuardChecksFor(profile string, hints *RepoHints) GuardChecks {
+	g := DefaultGuardChecks().Merge(c.Guard).Merge(c.Profiles[profile].Guard)
+	if hints != nil {
+		g = g.Tighten(hints.Guard)
+	}
+	return g
+}
+
+func guardRank(level string) int {
+	switch level {
+	case GuardWarn:
+		return 1
+	case GuardBlock:
+		return 2
+	default:
+		return 0
+	}
+}
+
+// invalidLevels는 허용되지 않는 수준이 쓰인 항목의 키 이름과 값을 반환한다.
+func (g GuardChecks) invalidLevels() []KeyValue {
+	var invalid []KeyValue
+	gv := reflect.ValueOf(g)
+	for i, name := range tomlFields(gv.Type()) {
+		switch s := gv.Field(i).String(); s {
+		case "", GuardOff, GuardWarn, GuardBlock:
+		default:
+			invalid = append(invalid, KeyValue{Key: name, Value: s})
+		}
+	}
+	return invalid
+}
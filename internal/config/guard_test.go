@@ -0,0 +1,114 @@
+package config_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/hbjs97/ctx/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGuardChecksFor(t *testing.T) {
+	cfg := &config.Config{
+		Guard: config.GuardChecks{UserName: config.GuardBlock, ForcePush: config.GuardWarn},
+		Profiles: map[string]config.Profile{
+			"oss": {Guard: config.GuardChecks{UserName: config.GuardOff}},
+		},
+	}
+
+	g := cfg.GuardChecksFor("work", nil)
+	assert.Equal(t, config.GuardBlock, g.UserName, "전역 설정이 기본값을 덮어쓴다")
+	assert.Equal(t, config.GuardWarn, g.ForcePush)
+	assert.Equal(t, config.GuardBlock, g.RemoteHost, "정하지 않은 항목은 기본값")
+
+	g = cfg.GuardChecksFor("oss", nil)
+	assert.Equal(t, config.GuardOff, g.UserName, "프로필 설정이 전역 설정을 덮어쓴다")
+
+	hints := &config.RepoHints{Guard: config.GuardChecks{ForcePush: config.GuardBlock, RemoteHost: config.GuardOff}}
+	g = cfg.GuardChecksFor("oss", hints)
+	assert.Equal(t, config.GuardBlock, g.ForcePush, "리포 힌트는 수준을 높일 수 있다")
+	assert.Equal(t, config.GuardBlock, g.RemoteHost, "리포 힌트는 검사를 끌 수 없다")
+}
+
+func TestValidate_GuardLevels(t *testing.T) {
+	work := validProfile("github.com-work", "/gh/work", "w@acme.com", "acme")
+	work.Guard.ForcePush = "deny"
+	work.ProtectedBranches = []string{"main", "release/["}
+	cfg := &config.Config{
+		Guard:    config.GuardChecks{UserName: "strict"},
+		Profiles: map[string]config.Profile{"work": work},
+	}
+
+	assert.Equal(t, []string{
+		"guard.user_name",
+		"profiles.work.guard.force_push",
+		"profiles.work.protected_branches",
+	}, issueFields(cfg.Validate(nil), config.SeverityError))
+}
+
+func TestGuardKeys(t *testing.T) {
+	path := writeAnnotated(t)
+	got := saveAndRead(t, path, func(cfg *config.Config) {
+		require.NoError(t, cfg.Set("guard.user_name", "block"))
+		require.NoError(t, cfg.Set("profiles.work.guard.force_push", "warn"))
+		require.NoError(t, cfg.Set("profiles.work.protected_branches", "main,release/*"))
+	})
+	assert.Contains(t, got, "\n[guard]\nuser_name = \"block\"\n")
+	assert.Contains(t, got, "\n[profiles.work.guard]\nforce_push = \"warn\"\n")
+	assert.Contains(t, got, "# 회사 계정\n[profiles.work]", "기존 내용은 그대로 남는다")
+
+	cfg, err := config.Load(path)
+	require.NoError(t, err)
+	v, err := cfg.Get("profiles.work.guard.force_push")
+	require.NoError(t, err)
+	assert.Equal(t, "warn", v)
+	assert.Contains(t, cfg.List(), config.KeyValue{Key: "guard.user_name", Value: "block"})
+
+	_, err = cfg.Get("guard")
+	assert.Error(t, err, "테이블 자체는 키가 아니다")
+
+	got = saveAndRead(t, path, func(cfg *config.Config) {
+		require.NoError(t, cfg.Unset("guard.user_name"))
+	})
+	assert.NotContains(t, got, "[guard]", "값이 없는 테이블은 지운다")
+}
+
+func TestLoadLayered_PolicyGuardTable(t *testing.T) {
+	policyPath := writeFile(t, "policy.toml", `locked = ["guard.remote_host"]
+
+[guard]
+remote_host = "block"
+force_push = "warn"
+`)
+	policy, err := config.LoadPolicy(policyPath)
+	require.NoError(t, err)
+	assert.True(t, policy.IsLocked("guard.remote_host"))
+	assert.False(t, policy.IsLocked("guard.force_push"))
+
+	cfgPath := writeFile(t, "config.toml", layeredUserConfig+`
+[guard]
+remote_host = "off"
+user_name = "block"
+`)
+	l, err := config.LoadLayered(cfgPath, policy)
+	require.NoError(t, err)
+	assert.Equal(t, config.GuardChecks{RemoteHost: "block", UserName: "block", ForcePush: "warn"}, l.Config.Guard)
+	require.Len(t, l.Ignored, 1)
+	assert.Equal(t, "guard.remote_host", l.Ignored[0].Field)
+	assert.Equal(t, config.Origin{Layer: "policy", Path: policyPath, Locked: true}, l.Origins["guard.remote_host"])
+	assert.Equal(t, config.Origin{Layer: "user", Path: cfgPath}, l.Origins["guard.user_name"])
+	assert.Equal(t, config.Origin{Layer: "policy", Path: policyPath}, l.Origins["guard.force_push"])
+
+	_, err = config.LoadPolicy(writeFile(t, "policy.toml", "[guard]\nno_such_check = \"block\"\n"))
+	assert.ErrorIs(t, err, config.ErrConfig)
+}
+
+func TestLoadRepoHints_GuardLevels(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, config.RepoFileName), []byte("[guard]\nforce_push = \"never\"\n"), 0644))
+
+	_, err := config.LoadRepoHints(dir)
+	assert.ErrorIs(t, err, config.ErrConfig)
+}
@@ -0,0 +1,204 @@
+package config
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// typeOfConfig는 최상위 설정 키를 찾을 때 쓰는 Config 타입이다.
+var typeOfConfig = reflect.TypeOf(Config{})
+
+// KeyValue는 dotted key와 그 값의 문자열 표현이다.
+type KeyValue struct {
+	Key   string
+	Value string
+}
+
+// Get은 dotted key(예: "cache_ttl_days", "profiles.work.owners")의 값을 문자열로 반환한다.
+// 목록 값은 쉼표로 구분한다.
+func (c *Config) Get(key string) (string, error) {
+	v, _, err := c.field(key)
+	if err != nil {
+		return "", fmt.Errorf("config.Get: %w", err)
+	}
+	return formatValue(v), nil
+}
+
+// Set은 dotted key에 값을 쓴다. 값은 필드 타입에 맞게 변환되며, 목록은 쉼표로 구분한다.
+// 프로필 필드는 이미 있는 프로필에만 쓸 수 있다.
+func (c *Config) Set(key, value string) error {
+	v, commit, err := c.field(key)
+	if err != nil {
+		return fmt.Errorf("config.Set: %w", err)
+	}
+	if err := parseValue(v, value); err != nil {
+		return fmt.Errorf("config.Set: %s: %w", key, err)
+	}
+	commit()
+	return nil
+}
+
+// Unset은 dotted key를 기본값으로 되돌린다. "profiles.<name>"이면 프로필을 삭제한다.
+func (c *Config) Unset(key string) error {
+	if name, ok := strings.CutPrefix(key, "profiles."); ok && !strings.Contains(name, ".") {
+		if _, exists := c.Profiles[name]; !exists {
+			return fmt.Errorf("config.Unset: 프로필 %q 없음: %w", name, ErrConfig)
+		}
+		delete(c.Profiles, name)
+		return nil
+	}
+	v, commit, err := c.field(key)
+	if err != nil {
+		return fmt.Errorf("config.Unset: %w", err)
+	}
+	v.Set(reflect.Zero(v.Type()))
+	commit()
+	return nil
+}
+
+// List는 모든 설정 키와 값을 반환한다. 최상위 키가 먼저, 프로필 키가 프로필 이름순으로 뒤따른다.
+// [guard] 같은 하위 테이블은 "guard.user_name"처럼 펼친다.
+// 배열 테이블인 email_rules는 키 하나로 나타낼 수 없어 제외한다.
+func (c *Config) List() []KeyValue {
+	kvs := appendFields(nil, "", reflect.ValueOf(c).Elem())
+
+	names := make([]string, 0, len(c.Profiles))
+	for name := range c.Profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		kvs = appendFields(kvs, "profiles."+name+".", reflect.ValueOf(c.Profiles[name]))
+	}
+	return kvs
+}
+
+// appendFields는 구조체 v의 필드를 prefix를 붙인 dotted key로 펼쳐 kvs에 덧붙인다.
+func appendFields(kvs []KeyValue, prefix string, v reflect.Value) []KeyValue {
+	for i, name := range tomlFields(v.Type()) {
+		f := v.Field(i)
+		switch {
+		case name == "-" || name == "profiles" || name == "email_rules":
+		case f.Kind() == reflect.Struct:
+			kvs = appendFields(kvs, prefix+name+".", f)
+		default:
+			kvs = append(kvs, KeyValue{Key: prefix + name, Value: formatValue(f)})
+		}
+	}
+	return kvs
+}
+
+// field는 dotted key가 가리키는 설정 가능한 필드를 찾는다.
+// 프로필은 map 값이라 복사본을 수정하므로, 변경 후 commit을 호출해야 Config에 반영된다.
+func (c *Config) field(key string) (reflect.Value, func(), error) {
+	parts := strings.Split(key, ".")
+	switch {
+	case parts[0] == "profiles" && len(parts) >= 3:
+		p, ok := c.Profiles[parts[1]]
+		if !ok {
+			return reflect.Value{}, nil, fmt.Errorf("프로필 %q 없음 (ctx profile add로 추가): %w", parts[1], ErrConfig)
+		}
+		if v, ok := lookupField(reflect.ValueOf(&p).Elem(), parts[2:]); ok {
+			return v, func() { c.Profiles[parts[1]] = p }, nil
+		}
+	case parts[0] == "email_rules":
+		return reflect.Value{}, nil, fmt.Errorf("%s는 ctx config edit로 편집: %w", parts[0], ErrConfig)
+	case parts[0] != "profiles":
+		if v, ok := lookupField(reflect.ValueOf(c).Elem(), parts); ok {
+			return v, func() {}, nil
+		}
+	}
+	return reflect.Value{}, nil, fmt.Errorf("알 수 없는 키 %q: %w", key, ErrConfig)
+}
+
+// lookupField는 구조체 v에서 toml 이름 경로를 따라 값 필드를 찾는다. 하위 테이블 자체는 찾지 않는다.
+func lookupField(v reflect.Value, path []string) (reflect.Value, bool) {
+	for _, name := range path {
+		if v.Kind() != reflect.Struct {
+			return reflect.Value{}, false
+		}
+		i := fieldIndex(v.Type(), name)
+		if i < 0 {
+			return reflect.Value{}, false
+		}
+		v = v.Field(i)
+	}
+	return v, v.Kind() != reflect.Struct
+}
+
+// tomlFields는 구조체 필드 순서대로 toml 태그 이름을 반환한다.
+func tomlFields(t reflect.Type) []string {
+	names := make([]string, t.NumField())
+	for i := range t.NumField() {
+		names[i], _, _ = strings.Cut(t.Field(i).Tag.Get("toml"), ",")
+	}
+	return names
+}
+
+func fieldIndex(t reflect.Type, name string) int {
+	for i, n := range tomlFields(t) {
+		if n == name {
+			return i
+		}
+	}
+	return -1
+}
+
+func formatValue(v reflect.Value) string {
+	switch v.Kind() {
+	case reflect.Pointer:
+		if v.IsNil() {
+			return ""
+		}
+		return formatValue(v.Elem())
+	case reflect.Slice:
+		items := make([]string, v.Len())
+		for i := range v.Len() {
+			items[i] = formatValue(v.Index(i))
+		}
+		return strings.Join(items, ",")
+	case reflect.Bool:
+		return strconv.FormatBool(v.Bool())
+	case reflect.Int:
+		return strconv.FormatInt(v.Int(), 10)
+	default:
+		return v.String()
+	}
+}
+
+func parseValue(v reflect.Value, s string) error {
+	switch v.Kind() {
+	case reflect.Pointer:
+		elem := reflect.New(v.Type().Elem())
+		if err := parseValue(elem.Elem(), s); err != nil {
+			return err
+		}
+		v.Set(elem)
+	case reflect.Slice:
+		var items []string
+		for item := range strings.SplitSeq(s, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				items = append(items, item)
+			}
+		}
+		v.Set(reflect.ValueOf(items))
+	case reflect.Bool:
+		b, err := strconv.ParseBool(s)
+		if err != nil {
+			return fmt.Errorf("bool 값 필요 (true/false): %w", ErrConfig)
+		}
+		v.SetBool(b)
+	case reflect.Int:
+		n, err := strconv.Atoi(s)
+		if err != nil {
+			return fmt.Errorf("정수 값 필요: %w", ErrConfig)
+		}
+		v.SetInt(int64(n))
+	default:
+		v.SetString(s)
+	}
+	return nil
+}
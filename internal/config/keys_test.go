@@ -0,0 +1,93 @@
+package config_test
+
+import (
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/hbjs97/ctx/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func keysTestConfig() *config.Config {
+	return &config.Config{
+		Version:      1,
+		CacheTTLDays: 90,
+		Profiles: map[string]config.Profile{
+			"work": validProfile("github.com-work", "/gh/work", "w@acme.com", "acme", "acme-labs"),
+		},
+	}
+}
+
+func TestGet(t *testing.T) {
+	cfg := keysTestConfig()
+
+	v, err := cfg.Get("cache_ttl_days")
+	require.NoError(t, err)
+	assert.Equal(t, "90", v)
+
+	v, err = cfg.Get("profiles.work.owners")
+	require.NoError(t, err)
+	assert.Equal(t, "acme,acme-labs", v)
+
+	v, err = cfg.Get("prompt_on_ambiguous")
+	require.NoError(t, err)
+	assert.Equal(t, "", v)
+}
+
+func TestGet_UnknownKey(t *testing.T) {
+	cfg := keysTestConfig()
+	for _, key := range []string{"nope", "profiles", "profiles.work", "profiles.missing.ssh_host", "profiles.work.nope"} {
+		_, err := cfg.Get(key)
+		assert.True(t, errors.Is(err, config.ErrConfig), key)
+	}
+}
+
+func TestSet(t *testing.T) {
+	cfg := keysTestConfig()
+
+	require.NoError(t, cfg.Set("cache_ttl_days", "30"))
+	require.NoError(t, cfg.Set("prompt_on_ambiguous", "false"))
+	require.NoError(t, cfg.Set("profiles.work.owners", "acme, other ,"))
+	require.NoError(t, cfg.Set("profiles.work.git_name", "Work User"))
+
+	assert.Equal(t, 30, cfg.CacheTTLDays)
+	assert.False(t, cfg.IsPromptOnAmbiguous())
+	assert.Equal(t, []string{"acme", "other"}, cfg.Profiles["work"].Owners)
+	assert.Equal(t, "Work User", cfg.Profiles["work"].GitName)
+}
+
+func TestSet_InvalidValue(t *testing.T) {
+	cfg := keysTestConfig()
+	assert.True(t, errors.Is(cfg.Set("cache_ttl_days", "soon"), config.ErrConfig))
+	assert.True(t, errors.Is(cfg.Set("require_push_guard", "maybe"), config.ErrConfig))
+	assert.Equal(t, 90, cfg.CacheTTLDays)
+}
+
+func TestUnset(t *testing.T) {
+	cfg := keysTestConfig()
+
+	require.NoError(t, cfg.Unset("profiles.work.owners"))
+	assert.Empty(t, cfg.Profiles["work"].Owners)
+
+	require.NoError(t, cfg.Unset("profiles.work"))
+	assert.NotContains(t, cfg.Profiles, "work")
+
+	assert.True(t, errors.Is(cfg.Unset("profiles.work"), config.ErrConfig))
+}
+
+func TestList(t *testing.T) {
+	cfg := keysTestConfig()
+	cfg.Profiles["personal"] = validProfile("github.com-personal", "/gh/personal", "me@example.com")
+
+	kvs := cfg.List()
+	keys := make([]string, len(kvs))
+	for i, kv := range kvs {
+		keys[i] = kv.Key
+	}
+	assert.Equal(t, "version", keys[0])
+	assert.Contains(t, kvs, config.KeyValue{Key: "profiles.work.owners", Value: "acme,acme-labs"})
+	assert.Less(t, slices.Index(keys, "profiles.personal.ssh_host"), slices.Index(keys, "profiles.work.ssh_host"))
+	assert.NotContains(t, keys, "profiles")
+}
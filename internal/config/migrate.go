@@ -0,0 +1,134 @@
+package config
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+
+	"github.com/BurntSushi/toml"
+	"github.com/hbjs97/ctx/internal/fsutil"
+	"github.com/hbjs97/ctx/internal/migrate"
+)
+
+// CurrentVersion은 이 ctx가 읽고 쓰는 config.toml 스키마 버전이다.
+const CurrentVersion = 1
+
+// migrations는 config.toml의 버전별 마이그레이션이다.
+var migrations = []migrate.Step{
+	{
+		From:        0,
+		Description: "version 키가 없는 초기 설정 파일을 version 1로 지정",
+		Apply:       func(map[string]any) error { return nil },
+	},
+}
+
+// migrateDocument는 TOML 문서의 version을 읽어 CurrentVersion까지 올리고, 적용한 단계 설명과 원래 버전을 반환한다.
+// 이 ctx보다 새로운 버전은 ErrConfig로 거부한다.
+func migrateDocument(doc map[string]any) ([]string, int, error) {
+	version := 0
+	if raw, ok := doc["version"]; ok {
+		n, ok := raw.(int64)
+		if !ok {
+			return nil, 0, fmt.Errorf("version은 정수여야 합니다: %w", ErrConfig)
+		}
+		version = int(n)
+	}
+	if version > CurrentVersion {
+		return nil, version, fmt.Errorf("설정 파일 version %d은 이 ctx가 지원하는 버전(%d)보다 새롭습니다. ctx를 업데이트하세요: %w", version, CurrentVersion, ErrConfig)
+	}
+
+	// BurntSushi/toml은 정수를 int64로 디코딩한다
+	applied, err := migrate.Run(doc, version, migrations, func(doc map[string]any, v int) { doc["version"] = int64(v) })
+	if err != nil {
+		return nil, version, err
+	}
+	return applied, version, nil
+}
+
+// decodeDocument는 TOML 문서를 마이그레이션한 뒤 Config로 디코딩하고 기본값을 채운다.
+func decodeDocument(data []byte) (*Config, []string, error) {
+	var doc map[string]any
+	if _, err := toml.Decode(string(data), &doc); err != nil {
+		return nil, nil, err
+	}
+	applied, _, err := migrateDocument(doc)
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(applied) > 0 {
+		var buf bytes.Buffer
+		if err := toml.NewEncoder(&buf).Encode(doc); err != nil {
+			return nil, nil, err
+		}
+		data = buf.Bytes()
+	}
+
+	var cfg Config
+	if _, err := toml.Decode(string(data), &cfg); err != nil {
+		return nil, nil, err
+	}
+	cfg.applyDefaults()
+	return &cfg, applied, nil
+}
+
+// Migrate는 설정 파일을 CurrentVersion으로 올려 저장한다.
+// 원본은 "<path>.v<이전 버전>.bak"으로 백업하며, 이미 최신이면 아무것도 쓰지 않고 nil을 반환한다.
+func Migrate(path string) (applied []string, backup string, err error) {
+	lock, err := lockConfig(path)
+	if err != nil {
+		return nil, "", fmt.Errorf("config.Migrate: %w", err)
+	}
+	defer lock.Unlock()
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, "", fmt.Errorf("config.Migrate: %w", err)
+	}
+	var doc map[string]any
+	if _, err := toml.Decode(string(data), &doc); err != nil {
+		return nil, "", fmt.Errorf("config.Migrate: %w: %w", err, ErrConfig)
+	}
+	applied, from, err := migrateDocument(doc)
+	if err != nil {
+		return nil, "", fmt.Errorf("config.Migrate: %w", err)
+	}
+	if len(applied) == 0 {
+		return nil, "", nil
+	}
+
+	cfg, _, err := decodeDocument(data)
+	if err != nil {
+		return nil, "", fmt.Errorf("config.Migrate: %w", err)
+	}
+	// save가 이전 버전 원본을 백업한다
+	if err := save(path, cfg); err != nil {
+		return nil, "", fmt.Errorf("config.Migrate: %w", err)
+	}
+	return applied, backupPath(path, from), nil
+}
+
+// backupPath는 version 버전 원본의 백업 경로 "<path>.v<version>.bak"이다.
+func backupPath(path string, version int) string {
+	return fmt.Sprintf("%s.v%d.bak", path, version)
+}
+
+// backupOlderVersion은 디스크의 문서 data가 CurrentVersion보다 오래되었으면 백업한다.
+// 설정을 고쳐 쓰는 모든 경로가 Migrate와 같은 백업을 남기도록 save가 쓰기 전에 호출한다.
+// 해석할 수 없는 문서는 백업하지 않는다.
+func backupOlderVersion(path string, data []byte) error {
+	if len(data) == 0 {
+		return nil
+	}
+	var doc map[string]any
+	if _, err := toml.Decode(string(data), &doc); err != nil {
+		return nil
+	}
+	applied, from, err := migrateDocument(doc)
+	if err != nil || len(applied) == 0 {
+		return nil
+	}
+	if err := fsutil.WriteFileAtomic(backupPath(path, from), data, 0600); err != nil {
+		return fmt.Errorf("백업 실패: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,92 @@
+package config_test
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/hbjs97/ctx/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const unversionedConfig = `# 버전 키가 없던 초기 설정
+default_profile = "work"
+
+[profiles.work]
+gh_config_dir = "/gh/work"
+ssh_host = "github.com-work"
+git_name = "Work"
+git_email = "w@acme.com"
+`
+
+func TestLoad_MigratesOldVersionInMemory(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.toml")
+	require.NoError(t, os.WriteFile(path, []byte(unversionedConfig), 0600))
+
+	cfg, err := config.Load(path)
+	require.NoError(t, err)
+	assert.Equal(t, config.CurrentVersion, cfg.Version)
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, unversionedConfig, string(data), "Load는 파일을 고치지 않는다")
+}
+
+func TestLoad_RejectsFutureVersion(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.toml")
+	require.NoError(t, os.WriteFile(path, []byte("version = 99\n"+strings.TrimPrefix(unversionedConfig, "# 버전 키가 없던 초기 설정\n")), 0600))
+
+	_, err := config.Load(path)
+	require.Error(t, err)
+	assert.True(t, errors.Is(err, config.ErrConfig))
+	assert.Contains(t, err.Error(), "version 99")
+
+	_, err = config.Decode(path)
+	assert.True(t, errors.Is(err, config.ErrConfig))
+}
+
+func TestMigrate(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.toml")
+	require.NoError(t, os.WriteFile(path, []byte(unversionedConfig), 0600))
+
+	applied, backup, err := config.Migrate(path)
+	require.NoError(t, err)
+	assert.Len(t, applied, 1)
+	assert.Equal(t, path+".v0.bak", backup)
+
+	data, err := os.ReadFile(backup)
+	require.NoError(t, err)
+	assert.Equal(t, unversionedConfig, string(data))
+
+	data, err = os.ReadFile(path)
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(string(data), "# 버전 키가 없던 초기 설정\n"), "주석이 보존되어야 한다")
+	assert.Contains(t, string(data), "version = 1")
+
+	// 이미 최신이면 아무것도 하지 않는다
+	applied, backup, err = config.Migrate(path)
+	require.NoError(t, err)
+	assert.Empty(t, applied)
+	assert.Empty(t, backup)
+}
+
+func TestSave_BacksUpOlderVersion(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.toml")
+	require.NoError(t, os.WriteFile(path, []byte(unversionedConfig), 0600))
+
+	cfg, err := config.Load(path)
+	require.NoError(t, err)
+	cfg.DefaultProfile = ""
+	require.NoError(t, config.Save(path, cfg))
+
+	data, err := os.ReadFile(path + ".v0.bak")
+	require.NoError(t, err, "setup처럼 Load 후 Save로 버전을 올려도 백업을 남긴다")
+	assert.Equal(t, unversionedConfig, string(data))
+
+	require.NoError(t, os.Remove(path+".v0.bak"))
+	require.NoError(t, config.Save(path, cfg))
+	assert.NoFileExists(t, path+".v0.bak", "이미 최신이면 백업하지 않는다")
+}
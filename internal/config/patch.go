@@ -0,0 +1,446 @@
+package config
+
+import (
+	"bytes"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+)
+
+// render는 기존 문서 doc에서 바뀐 키만 고쳐 쓴 cfg의 TOML 문서를 반환한다.
+// 주석, 빈 줄, 키 순서와 Config에 없는 키는 그대로 남고, 기본값은 새로 쓰지 않는다.
+// 고쳐 쓴 문서가 cfg와 다르게 해석되면(손으로 쓴 inline table 등) 전체를 다시 인코딩한다.
+func render(doc []byte, cfg *Config) ([]byte, error) {
+	var old Config
+	if _, err := toml.Decode(string(doc), &old); err == nil {
+		want := *cfg
+		want.omitDefaults(&old)
+		out := patchDocument(doc, &old, &want)
+
+		expected := *cfg
+		expected.applyDefaults()
+		var got Config
+		if _, err := toml.Decode(string(out), &got); err == nil {
+			got.applyDefaults()
+			// email_rules 배열 테이블은 고쳐 쓰지 않으므로, 바뀌었으면 전체를 다시 인코딩한다
+			if reflect.DeepEqual(configTables(&got), configTables(&expected)) && reflect.DeepEqual(got.EmailRules, expected.EmailRules) {
+				return out, nil
+			}
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := toml.NewEncoder(&buf).Encode(cfg); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// tomlValue는 테이블 안의 키와 TOML로 인코딩한 값이다.
+type tomlValue struct {
+	key     string
+	literal string
+}
+
+// configTable은 문서의 테이블 하나에 들어갈 키 목록이다.
+type configTable struct {
+	header string // 문서에 새로 추가할 때 쓰는 헤더 (예: "[profiles.work.guard]")
+	values []tomlValue
+}
+
+// configTables는 Config를 테이블 이름("" = 루트, "profiles.<name>", "guard" 등)별 키 목록으로 펼친다.
+// 값이 비어 있는 키와 하위 테이블은 문서에 없는 것으로 본다.
+func configTables(c *Config) map[string]configTable {
+	tables := make(map[string]configTable)
+	addTables(tables, nil, reflect.ValueOf(c).Elem(), true)
+	for name, p := range c.Profiles {
+		addTables(tables, []string{"profiles", name}, reflect.ValueOf(p), true)
+	}
+	return tables
+}
+
+// addTables는 구조체 v의 값을 path 테이블에 넣고, 구조체 필드는 하위 테이블로 넣는다.
+// keepEmpty가 false면 값이 없는 테이블은 넣지 않는다.
+func addTables(tables map[string]configTable, path []string, v reflect.Value, keepEmpty bool) {
+	values := []tomlValue{}
+	for i, key := range tomlFields(v.Type()) {
+		f := v.Field(i)
+		switch {
+		case key == "profiles" || key == "email_rules" || f.IsZero() || (f.Kind() == reflect.Slice && f.Len() == 0):
+		case f.Kind() == reflect.Struct:
+			addTables(tables, append(path[:len(path):len(path)], key), f, false)
+		case f.Kind() == reflect.Pointer:
+			values = append(values, tomlValue{key: key, literal: tomlLiteral(f.Elem())})
+		default:
+			values = append(values, tomlValue{key: key, literal: tomlLiteral(f)})
+		}
+	}
+	if len(values) == 0 && !keepEmpty {
+		return
+	}
+	keys := make([]string, len(path))
+	for i, k := range path {
+		keys[i] = formatKey(k)
+	}
+	tables[strings.Join(path, ".")] = configTable{header: "[" + strings.Join(keys, ".") + "]", values: values}
+}
+
+func tomlLiteral(v reflect.Value) string {
+	var buf bytes.Buffer
+	_ = toml.NewEncoder(&buf).Encode(map[string]any{"v": v.Interface()}) // 문자열·정수·bool·문자열 목록만 인코딩하므로 실패하지 않는다
+	return strings.TrimSpace(strings.TrimPrefix(buf.String(), "v = "))
+}
+
+func lookupValue(values []tomlValue, key string) (string, bool) {
+	for _, v := range values {
+		if v.key == key {
+			return v.literal, true
+		}
+	}
+	return "", false
+}
+
+// docEntry는 문서에서 key = value 한 개가 차지하는 줄 범위다.
+type docEntry struct {
+	key        string
+	start, end int // [start, end) 줄
+	valueCol   int // start 줄에서 값이 시작하는 위치
+	tailCol    int // end-1 줄에서 값이 끝나는 위치. 뒤따르는 공백과 주석은 보존한다.
+}
+
+// docTable은 문서의 테이블 하나다. 루트 테이블의 header는 -1이다.
+type docTable struct {
+	name    string
+	header  int
+	entries []docEntry
+	end     int // 다음 테이블 헤더(와 그 위에 붙은 주석)가 시작하는 줄
+}
+
+func (t *docTable) entry(key string) *docEntry {
+	for i := range t.entries {
+		if t.entries[i].key == key {
+			return &t.entries[i]
+		}
+	}
+	return nil
+}
+
+// insertAt은 테이블에 새 키를 넣을 줄 위치를 반환한다.
+func (t *docTable) insertAt() int {
+	if n := len(t.entries); n > 0 {
+		return t.entries[n-1].end
+	}
+	if t.header >= 0 {
+		return t.header + 1
+	}
+	return t.end
+}
+
+// edit는 [start, end) 줄을 lines로 바꾸는 문서 수정이다.
+type edit struct {
+	start, end int
+	lines      []string
+}
+
+// patchDocument는 old와 cfg가 다른 키만 doc에 반영한다.
+// 바뀐 값은 제자리에서 교체하고, 새 키는 테이블 끝에, 새 테이블(프로필, [guard] 등)은 문서 끝에 추가하며,
+// 사라진 키와 프로필(위에 붙은 주석 포함)은 삭제한다.
+func patchDocument(doc []byte, old, cfg *Config) []byte {
+	lines := splitLines(doc)
+	tables := parseDocument(lines)
+	find := func(name string) *docTable {
+		for i := len(tables) - 1; i >= 0; i-- {
+			if tables[i].name == name {
+				return tables[i]
+			}
+		}
+		return nil
+	}
+
+	had, want := configTables(old), configTables(cfg)
+	names := make([]string, 0, len(had)+len(want))
+	for name := range had {
+		names = append(names, name)
+	}
+	for name := range want {
+		if _, ok := had[name]; !ok {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names) // 루트("")가 먼저 온다
+
+	var edits []edit
+	var appended []string
+	for _, name := range names {
+		tb := find(name)
+		wantTable, keep := want[name]
+		wantValues := wantTable.values
+		if !keep {
+			if tb != nil && tb.header >= 0 {
+				edits = append(edits, edit{start: attachedStart(lines, tb.header), end: tb.end})
+			}
+			continue
+		}
+		if tb == nil {
+			appended = append(appended, "", wantTable.header)
+			for _, v := range wantValues {
+				appended = append(appended, v.key+" = "+v.literal)
+			}
+			continue
+		}
+
+		var inserts []string
+		for _, v := range wantValues {
+			if prev, ok := lookupValue(had[name].values, v.key); ok && prev == v.literal {
+				continue
+			}
+			if e := tb.entry(v.key); e != nil {
+				line := lines[e.start][:e.valueCol] + v.literal + lines[e.end-1][e.tailCol:]
+				edits = append(edits, edit{start: e.start, end: e.end, lines: []string{line}})
+			} else {
+				inserts = append(inserts, v.key+" = "+v.literal)
+			}
+		}
+		for _, v := range had[name].values {
+			if _, ok := lookupValue(wantValues, v.key); ok {
+				continue
+			}
+			if e := tb.entry(v.key); e != nil {
+				edits = append(edits, edit{start: e.start, end: e.end})
+			}
+		}
+		if len(inserts) > 0 {
+			at := tb.insertAt()
+			if tb.header < 0 && len(tb.entries) == 0 && at < len(lines) {
+				inserts = append(inserts, "")
+			}
+			edits = append(edits, edit{start: at, end: at, lines: inserts})
+		}
+	}
+
+	// 뒤에서부터 적용해 앞쪽 줄 번호가 유지되게 한다. 같은 위치에서는 삭제·교체가 삽입보다 먼저다.
+	sort.Slice(edits, func(i, j int) bool {
+		if edits[i].start != edits[j].start {
+			return edits[i].start > edits[j].start
+		}
+		return edits[i].end > edits[j].end
+	})
+	origLen := len(lines)
+	for _, e := range edits {
+		lines = append(lines[:e.start], append(append([]string{}, e.lines...), lines[e.end:]...)...)
+		if e.end == origLen && len(e.lines) == 0 {
+			// 문서 끝의 테이블을 지웠으면 남은 빈 줄도 정리한다
+			for len(lines) > 0 && strings.TrimSpace(lines[len(lines)-1]) == "" {
+				lines = lines[:len(lines)-1]
+			}
+		}
+	}
+	if len(appended) > 0 && (len(lines) == 0 || strings.TrimSpace(lines[len(lines)-1]) == "") {
+		appended = appended[1:]
+	}
+	lines = append(lines, appended...)
+
+	if len(lines) == 0 {
+		return nil
+	}
+	return []byte(strings.Join(lines, "\n") + "\n")
+}
+
+func splitLines(doc []byte) []string {
+	s := strings.TrimSuffix(string(doc), "\n")
+	if s == "" {
+		return nil
+	}
+	return strings.Split(s, "\n")
+}
+
+// parseDocument는 TOML 문서를 테이블과 키 위치로 나눈다.
+// 값은 해석하지 않고 범위만 찾는다. 알 수 없는 줄은 건너뛴다.
+func parseDocument(lines []string) []*docTable {
+	root := &docTable{header: -1}
+	tables := []*docTable{root}
+	cur := root
+	for i := 0; i < len(lines); {
+		trimmed := strings.TrimSpace(lines[i])
+		switch {
+		case trimmed == "" || trimmed[0] == '#':
+			i++
+		case trimmed[0] == '[':
+			name := "\x00" // 배열 테이블 등 ctx가 쓰지 않는 헤더
+			if !strings.HasPrefix(trimmed, "[[") {
+				if path, _, ok := parseKeyPath(lines[i], strings.IndexByte(lines[i], '[')+1, ']'); ok {
+					name = strings.Join(path, ".")
+				}
+			}
+			cur = &docTable{name: name, header: i}
+			tables = append(tables, cur)
+			i++
+		default:
+			path, col, ok := parseKeyPath(lines[i], 0, '=')
+			if !ok {
+				i++
+				continue
+			}
+			for col < len(lines[i]) && (lines[i][col] == ' ' || lines[i][col] == '\t') {
+				col++
+			}
+			end, tail := scanValue(lines, i, col)
+			cur.entries = append(cur.entries, docEntry{key: strings.Join(path, "."), start: i, end: end, valueCol: col, tailCol: tail})
+			i = end
+		}
+	}
+	for k, tb := range tables {
+		tb.end = len(lines)
+		if k+1 < len(tables) {
+			tb.end = attachedStart(lines, tables[k+1].header)
+		}
+	}
+	return tables
+}
+
+// attachedStart는 header 줄 바로 위에 빈 줄 없이 붙은 주석의 시작 줄을 반환한다.
+func attachedStart(lines []string, header int) int {
+	i := header
+	for i > 0 && strings.HasPrefix(strings.TrimSpace(lines[i-1]), "#") {
+		i--
+	}
+	return i
+}
+
+// parseKeyPath는 s[pos:]에서 점으로 구분된 키를 읽고 term 문자 다음 위치를 반환한다.
+func parseKeyPath(s string, pos int, term byte) ([]string, int, bool) {
+	var path []string
+	i := pos
+	skipSpace := func() {
+		for i < len(s) && (s[i] == ' ' || s[i] == '\t') {
+			i++
+		}
+	}
+	for {
+		skipSpace()
+		if i >= len(s) {
+			return nil, 0, false
+		}
+		switch s[i] {
+		case '"':
+			end := closeQuote(s, i)
+			key, err := strconv.Unquote(s[i:end])
+			if err != nil {
+				return nil, 0, false
+			}
+			path = append(path, key)
+			i = end
+		case '\'':
+			end := closeQuote(s, i)
+			if end < i+2 || s[end-1] != '\'' {
+				return nil, 0, false
+			}
+			path = append(path, s[i+1:end-1])
+			i = end
+		default:
+			start := i
+			for i < len(s) && isBareKeyChar(s[i]) {
+				i++
+			}
+			if i == start {
+				return nil, 0, false
+			}
+			path = append(path, s[start:i])
+		}
+		skipSpace()
+		if i >= len(s) {
+			return nil, 0, false
+		}
+		switch s[i] {
+		case '.':
+			i++
+		case term:
+			return path, i + 1, true
+		default:
+			return nil, 0, false
+		}
+	}
+}
+
+// scanValue는 (ln, col)에서 시작하는 값이 끝나는 줄(exclusive)과 마지막 줄에서 값이 끝나는 위치를 반환한다.
+// 여러 줄 배열과 여러 줄 문자열을 처리한다.
+func scanValue(lines []string, ln, col int) (end, tail int) {
+	depth := 0
+	tail = col
+	for ln < len(lines) {
+		s := lines[ln]
+	line:
+		for c := col; c < len(s); {
+			switch ch := s[c]; {
+			case ch == '#':
+				break line
+			case strings.HasPrefix(s[c:], `"""`) || strings.HasPrefix(s[c:], `'''`):
+				delim := s[c : c+3]
+				c += 3
+				for {
+					if i := strings.Index(s[c:], delim); i >= 0 {
+						c += i + 3
+						break
+					}
+					if ln++; ln >= len(lines) {
+						return len(lines), 0
+					}
+					s, c = lines[ln], 0
+				}
+				tail = c
+			case ch == '"' || ch == '\'':
+				c = closeQuote(s, c)
+				tail = c
+			default:
+				switch ch {
+				case '[', '{':
+					depth++
+				case ']', '}':
+					depth--
+				}
+				if ch != ' ' && ch != '\t' && ch != '\r' {
+					tail = c + 1
+				}
+				c++
+			}
+		}
+		if depth <= 0 {
+			return ln + 1, tail
+		}
+		ln, col, tail = ln+1, 0, 0
+	}
+	return len(lines), 0
+}
+
+// closeQuote는 s[start]의 따옴표로 시작하는 한 줄 문자열이 끝난 다음 위치를 반환한다.
+func closeQuote(s string, start int) int {
+	q := s[start]
+	for i := start + 1; i < len(s); i++ {
+		if q == '"' && s[i] == '\\' {
+			i++
+			continue
+		}
+		if s[i] == q {
+			return i + 1
+		}
+	}
+	return len(s)
+}
+
+func isBareKeyChar(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c == '_' || c == '-'
+}
+
+var bareKey = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+
+// formatKey는 bare key로 쓸 수 없는 이름을 따옴표로 감싼다.
+func formatKey(k string) string {
+	if bareKey.MatchString(k) {
+		return k
+	}
+	return strconv.Quote(k)
+}
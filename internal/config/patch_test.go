@@ -0,0 +1,194 @@
+package config_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/hbjs97/ctx/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const annotatedConfig = `# 팀 공용 ctx 설정
+version = 1
+default_profile = "work"
+cache_ttl_days = 30 # 한 달
+team_note = "ctx가 모르는 키"
+
+# 회사 계정
+[profiles.work]
+gh_config_dir = "/gh/work"
+ssh_host = "github.com-work"
+git_name = "Work"
+git_email = "w@acme.com"
+owners = [
+  "acme",   # 본 조직
+  "acme-labs",
+]
+
+# 개인 계정
+[profiles.personal]
+gh_config_dir = "/gh/personal"
+ssh_host = "github.com-personal"
+git_name = "Me"
+git_email = "me@example.com"
+`
+
+func writeAnnotated(t *testing.T) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "config.toml")
+	require.NoError(t, os.WriteFile(path, []byte(annotatedConfig), 0600))
+	return path
+}
+
+func saveAndRead(t *testing.T, path string, mutate func(*config.Config)) string {
+	t.Helper()
+	cfg, err := config.Load(path)
+	require.NoError(t, err)
+	mutate(cfg)
+	require.NoError(t, config.Save(path, cfg))
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	return string(data)
+}
+
+func TestSave_UnchangedConfigKeepsDocument(t *testing.T) {
+	path := writeAnnotated(t)
+	got := saveAndRead(t, path, func(*config.Config) {})
+	assert.Equal(t, annotatedConfig, got, "기본값(prompt_on_ambiguous 등)을 새로 쓰지 않아야 한다")
+}
+
+func TestSave_PatchesChangedValuesInPlace(t *testing.T) {
+	path := writeAnnotated(t)
+	got := saveAndRead(t, path, func(cfg *config.Config) {
+		require.NoError(t, cfg.Set("cache_ttl_days", "7"))
+		require.NoError(t, cfg.Set("profiles.work.owners", "acme,acme-labs,acme-ops"))
+		require.NoError(t, cfg.Set("profiles.personal.signing_key", "ABCD"))
+		require.NoError(t, cfg.Set("prompt_on_ambiguous", "false"))
+	})
+
+	assert.Equal(t, `# 팀 공용 ctx 설정
+version = 1
+default_profile = "work"
+cache_ttl_days = 7 # 한 달
+team_note = "ctx가 모르는 키"
+prompt_on_ambiguous = false
+
+# 회사 계정
+[profiles.work]
+gh_config_dir = "/gh/work"
+ssh_host = "github.com-work"
+git_name = "Work"
+git_email = "w@acme.com"
+owners = ["acme", "acme-labs", "acme-ops"]
+
+# 개인 계정
+[profiles.personal]
+gh_config_dir = "/gh/personal"
+ssh_host = "github.com-personal"
+git_name = "Me"
+git_email = "me@example.com"
+signing_key = "ABCD"
+`, got)
+}
+
+func TestSave_AddsAndRemovesProfiles(t *testing.T) {
+	path := writeAnnotated(t)
+	got := saveAndRead(t, path, func(cfg *config.Config) {
+		delete(cfg.Profiles, "personal")
+		cfg.Profiles["oss work"] = config.Profile{
+			GHConfigDir: "/gh/oss",
+			SSHHost:     "github.com-oss",
+			GitName:     "OSS",
+			GitEmail:    "oss@example.com",
+			Owners:      []string{"oss-org"},
+		}
+	})
+
+	assert.Contains(t, got, "# 회사 계정\n[profiles.work]")
+	assert.NotContains(t, got, "개인 계정")
+	assert.NotContains(t, got, "profiles.personal")
+	assert.Contains(t, got, `  "acme",   # 본 조직`)
+	assert.Contains(t, got, "]\n\n[profiles.\"oss work\"]\ngh_config_dir = \"/gh/oss\"\nssh_host = \"github.com-oss\"\n")
+
+	cfg, err := config.Load(path)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"oss-org"}, cfg.Profiles["oss work"].Owners)
+}
+
+func TestSave_UnsetRemovesKey(t *testing.T) {
+	path := writeAnnotated(t)
+	got := saveAndRead(t, path, func(cfg *config.Config) {
+		require.NoError(t, cfg.Unset("cache_ttl_days"))
+		require.NoError(t, cfg.Unset("profiles.work.owners"))
+	})
+
+	assert.NotContains(t, got, "cache_ttl_days")
+	assert.NotContains(t, got, "owners")
+	assert.NotContains(t, got, "acme-labs")
+	assert.Contains(t, got, "team_note")
+}
+
+func TestSave_NewFileOmitsDefaults(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.toml")
+	cfg := &config.Config{
+		Version:      1,
+		CacheTTLDays: 90,
+		Profiles: map[string]config.Profile{
+			"work": {GHConfigDir: "/gh/work", SSHHost: "github.com-work", GitName: "Work", GitEmail: "w@acme.com"},
+		},
+	}
+	require.NoError(t, config.Save(path, cfg))
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, `version = 1
+
+[profiles.work]
+gh_config_dir = "/gh/work"
+ssh_host = "github.com-work"
+git_name = "Work"
+git_email = "w@acme.com"
+`, string(data))
+}
+
+func TestSaveDocument(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.toml")
+	require.NoError(t, config.SaveDocument(path, []byte(annotatedConfig)))
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, annotatedConfig, string(data))
+
+	err = config.SaveDocument(path, []byte("version = 1\n"))
+	require.Error(t, err)
+	data, err = os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, annotatedConfig, string(data), "invalid document must not be written")
+}
+
+func TestSave_KeepsEmailRules(t *testing.T) {
+	const withRules = annotatedConfig + `
+# 보안팀 규칙
+[[email_rules]]
+name = "acme-only"
+owners = ["acme"]
+allow = ["*@acme.com"]
+`
+	path := filepath.Join(t.TempDir(), "config.toml")
+	require.NoError(t, os.WriteFile(path, []byte(withRules), 0600))
+
+	got := saveAndRead(t, path, func(cfg *config.Config) {
+		require.NoError(t, cfg.Set("cache_ttl_days", "7"))
+	})
+	assert.Contains(t, got, "# 보안팀 규칙\n[[email_rules]]\nname = \"acme-only\"")
+	assert.Contains(t, got, "cache_ttl_days = 7 # 한 달")
+
+	got = saveAndRead(t, path, func(cfg *config.Config) {
+		cfg.EmailRules = append(cfg.EmailRules, config.EmailRule{Name: "no-gmail", Deny: []string{"*@gmail.com"}})
+	})
+	cfg, err := config.Load(path)
+	require.NoError(t, err)
+	require.Len(t, cfg.EmailRules, 2, got)
+	assert.Equal(t, "no-gmail", cfg.EmailRules[1].Name)
+}
@@ -0,0 +1,312 @@
+package config
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"reflect"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+)
+
+// DefaultPolicyPath는 팀 정책 파일의 기본 경로다. CTX_POLICY 환경변수로 바꿀 수 있다.
+const DefaultPolicyPath = "/etc/ctx/policy.toml"
+
+// PolicyPath는 CTX_POLICY가 설정되어 있으면 그 경로를, 아니면 DefaultPolicyPath를 반환한다.
+func PolicyPath() string {
+	if p := os.Getenv("CTX_POLICY"); p != "" {
+		return p
+	}
+	return DefaultPolicyPath
+}
+
+// Policy는 팀이 배포하는 읽기 전용 정책 파일이다.
+// 최상위 설정 키의 기본값을 정하며, Locked에 나열한 키는 사용자 설정이 덮어쓸 수 없다.
+//
+//	require_push_guard = true
+//	cache_ttl_days = 30
+//	locked = ["require_push_guard"]
+type Policy struct {
+	Path   string
+	Values map[string]any
+	Locked []string
+}
+
+// LoadPolicy는 정책 파일을 읽는다. 파일이 없으면 nil, nil을 반환한다.
+// 프로필 등 최상위 설정이 아닌 키나 모르는 키가 있으면 ErrConfig를 반환한다.
+func LoadPolicy(path string) (*Policy, error) {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("config.LoadPolicy: %w", err)
+	}
+
+	var doc map[string]any
+	if _, err := toml.Decode(string(data), &doc); err != nil {
+		return nil, fmt.Errorf("config.LoadPolicy: %s: %w: %w", path, err, ErrConfig)
+	}
+	p := &Policy{Path: path, Values: make(map[string]any)}
+	for key, v := range doc {
+		switch key {
+		case "locked":
+			locked, ok := v.([]any)
+			if !ok {
+				return nil, fmt.Errorf("config.LoadPolicy: %s: locked는 문자열 목록이어야 합니다: %w", path, ErrConfig)
+			}
+			for _, k := range locked {
+				s, ok := k.(string)
+				if !ok || !isPolicyKey(s) {
+					return nil, fmt.Errorf("config.LoadPolicy: %s: 잠글 수 없는 키 %v: %w", path, k, ErrConfig)
+				}
+				p.Locked = append(p.Locked, s)
+			}
+		case "version":
+			// 정책 파일의 version은 설정 스키마 버전과 무관하다
+		default:
+			p.Values[key] = v
+		}
+	}
+	values := flattenDocument(p.Values)
+	for key := range values {
+		if !isPolicyKey(key) {
+			return nil, fmt.Errorf("config.LoadPolicy: %s: 정책으로 정할 수 없는 키 %q: %w", path, key, ErrConfig)
+		}
+	}
+	hasValue := func(prefix string) bool {
+		for key := range values {
+			if coveredBy(key, prefix) {
+				return true
+			}
+		}
+		return false
+	}
+	for _, k := range p.Locked {
+		if !hasValue(k) {
+			return nil, fmt.Errorf("config.LoadPolicy: %s: 잠긴 키 %q에 값이 없습니다: %w", path, k, ErrConfig)
+		}
+	}
+	sort.Strings(p.Locked)
+	return p, nil
+}
+
+// IsLocked는 key가 정책으로 잠겨 있는지 반환한다. "guard"를 잠그면 "guard.user_name"도 잠긴다.
+// 프로필별 검사 수준 profiles.<name>.guard.<check>는 guard.<check>와 같이 잠긴다.
+// nil Policy는 아무것도 잠그지 않는다.
+func (p *Policy) IsLocked(key string) bool {
+	key = lockTarget(key)
+	return p != nil && slices.ContainsFunc(p.Locked, func(l string) bool { return coveredBy(key, l) })
+}
+
+// lockTarget은 profiles.<name>.guard 아래 키를 대응하는 [guard] 키로 바꾼다. 그 밖의 키는 그대로 반환한다.
+func lockTarget(key string) string {
+	parts := strings.SplitN(key, ".", 3)
+	if len(parts) == 3 && parts[0] == "profiles" && coveredBy(parts[2], "guard") {
+		return parts[2]
+	}
+	return key
+}
+
+// coveredBy는 key가 prefix 자신이거나 그 하위 키인지 반환한다.
+func coveredBy(key, prefix string) bool {
+	return key == prefix || strings.HasPrefix(key, prefix+".")
+}
+
+// isPolicyKey는 정책이 정할 수 있는 설정 키(최상위 키 또는 [guard] 같은 하위 테이블과 그 키)인지 반환한다.
+func isPolicyKey(key string) bool {
+	parts := strings.Split(key, ".")
+	if parts[0] == "profiles" || parts[0] == "version" {
+		return false
+	}
+	t := typeOfConfig
+	for _, name := range parts {
+		if t.Kind() != reflect.Struct {
+			return false
+		}
+		i := fieldIndex(t, name)
+		if i < 0 {
+			return false
+		}
+		t = t.Field(i).Type
+	}
+	return true
+}
+
+// Origin은 설정 값이 어느 계층에서 왔는지 나타낸다.
+type Origin struct {
+	Layer  string // "default", "policy", "user", "repo"
+	Path   string
+	Locked bool
+}
+
+func (o Origin) String() string {
+	switch {
+	case o.Path == "":
+		return o.Layer
+	case o.Locked:
+		return fmt.Sprintf("%s:%s (locked)", o.Layer, o.Path)
+	default:
+		return fmt.Sprintf("%s:%s", o.Layer, o.Path)
+	}
+}
+
+// Layered는 정책과 사용자 설정을 합친 결과다.
+type Layered struct {
+	Config  *Config
+	Origins map[string]Origin // List()의 키 → 출처
+	Ignored []Issue           // 정책 잠금 때문에 무시된 사용자 값 (warning)
+}
+
+// LoadLayered는 정책 기본값 → 사용자 설정 → 정책 잠금 순으로 합친 설정을 읽는다.
+// policy가 nil이면 Load와 같다. 잠긴 키에 다른 사용자 값이 있으면 정책 값을 쓰고 Ignored에 기록한다.
+func LoadLayered(path string, policy *Policy) (*Layered, error) {
+	if err := ValidateFilePermissions(path); err != nil {
+		fmt.Fprintf(os.Stderr, "경고: %v\n", err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("config.Load: %w", err)
+	}
+	var doc map[string]any
+	if _, err := toml.Decode(string(data), &doc); err != nil {
+		return nil, fmt.Errorf("config.Load: %w", err)
+	}
+	userKeys := documentKeys(doc)
+
+	l := &Layered{Origins: make(map[string]Origin)}
+	var policyKeys map[string]bool
+	if policy != nil {
+		l.Ignored = mergePolicy(doc, policy.Values, "", policy)
+		l.Ignored = append(l.Ignored, dropLockedProfileGuards(doc, policy)...)
+		sort.Slice(l.Ignored, func(i, j int) bool { return l.Ignored[i].Field < l.Ignored[j].Field })
+		policyKeys = make(map[string]bool)
+		for key := range flattenDocument(policy.Values) {
+			policyKeys[key] = true
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := toml.NewEncoder(&buf).Encode(doc); err != nil {
+		return nil, fmt.Errorf("config.Load: %w", err)
+	}
+	cfg, applied, err := decodeDocument(buf.Bytes())
+	if err != nil {
+		if policy != nil {
+			return nil, fmt.Errorf("config.Load: 정책 %s 적용 후: %w", policy.Path, err)
+		}
+		return nil, fmt.Errorf("config.Load: %w", err)
+	}
+	if len(applied) > 0 {
+		fmt.Fprintf(os.Stderr, "경고: %s 설정을 version %d로 변환해 읽었습니다. 파일에 반영하려면 ctx config migrate\n", path, CurrentVersion)
+	}
+	if err := cfg.validate(); err != nil {
+		return nil, err
+	}
+	l.Config = cfg
+
+	for _, kv := range cfg.List() {
+		switch {
+		case policy.IsLocked(kv.Key):
+			l.Origins[kv.Key] = Origin{Layer: "policy", Path: policy.Path, Locked: true}
+		case userKeys[kv.Key]:
+			l.Origins[kv.Key] = Origin{Layer: "user", Path: path}
+		case policyKeys[kv.Key]:
+			l.Origins[kv.Key] = Origin{Layer: "policy", Path: policy.Path}
+		default:
+			l.Origins[kv.Key] = Origin{Layer: "default"}
+		}
+	}
+	return l, nil
+}
+
+// mergePolicy는 정책 값 values를 사용자 문서 doc에 합친다. 테이블은 키별로 합친다.
+// 사용자가 정한 키는 사용자 값을 쓰되, 잠긴 키는 정책 값을 쓰고 다른 사용자 값을 무시한 내역을 반환한다.
+func mergePolicy(doc, values map[string]any, prefix string, policy *Policy) []Issue {
+	var ignored []Issue
+	for key, v := range values {
+		full := prefix + key
+		userValue, set := doc[key]
+		if table, ok := v.(map[string]any); ok {
+			userTable, isTable := userValue.(map[string]any)
+			if !set {
+				userTable, isTable = make(map[string]any), true
+				doc[key] = userTable
+			}
+			if isTable {
+				ignored = append(ignored, mergePolicy(userTable, table, full+".", policy)...)
+				continue
+			}
+		}
+		if set && !policy.IsLocked(full) {
+			continue
+		}
+		if set && !sameValue(userValue, v) {
+			ignored = append(ignored, Issue{
+				Severity: SeverityWarning,
+				Field:    full,
+				Message:  fmt.Sprintf("팀 정책(%s)으로 %v에 고정되어 사용자 값 %v를 무시합니다", policy.Path, v, userValue),
+			})
+		}
+		doc[key] = v
+	}
+	return ignored
+}
+
+// dropLockedProfileGuards는 잠긴 [guard] 항목을 덮어쓰는 프로필별 검사 수준을 문서에서 지우고
+// 무시한 내역을 반환한다. 프로필 guard는 [guard] 위에 합쳐지므로 남겨 두면 잠금을 우회한다.
+func dropLockedProfileGuards(doc map[string]any, policy *Policy) []Issue {
+	var ignored []Issue
+	profiles, _ := doc["profiles"].(map[string]any)
+	for name, p := range profiles {
+		profile, _ := p.(map[string]any)
+		guard, _ := profile["guard"].(map[string]any)
+		for check, v := range guard {
+			full := "profiles." + name + ".guard." + check
+			if !policy.IsLocked(full) {
+				continue
+			}
+			ignored = append(ignored, Issue{
+				Severity: SeverityWarning,
+				Field:    full,
+				Message:  fmt.Sprintf("팀 정책(%s)으로 guard.%s가 고정되어 프로필 값 %v를 무시합니다", policy.Path, check, v),
+			})
+			delete(guard, check)
+		}
+	}
+	return ignored
+}
+
+// flattenDocument는 TOML 문서의 테이블을 펼쳐 dotted key → 값으로 반환한다.
+// 배열 테이블(email_rules)은 키 하나로 둔다.
+func flattenDocument(doc map[string]any) map[string]any {
+	flat := make(map[string]any)
+	var walk func(prefix string, m map[string]any)
+	walk = func(prefix string, m map[string]any) {
+		for k, v := range m {
+			if sub, ok := v.(map[string]any); ok {
+				walk(prefix+k+".", sub)
+				continue
+			}
+			flat[prefix+k] = v
+		}
+	}
+	walk("", doc)
+	return flat
+}
+
+// documentKeys는 TOML 문서에 명시된 키를 List()와 같은 dotted key로 모은다.
+func documentKeys(doc map[string]any) map[string]bool {
+	keys := make(map[string]bool)
+	for k := range flattenDocument(doc) {
+		keys[k] = true
+	}
+	return keys
+}
+
+func sameValue(a, b any) bool {
+	return fmt.Sprint(a) == fmt.Sprint(b)
+}
@@ -0,0 +1,183 @@
+package config_test
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/hbjs97/ctx/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const layeredUserConfig = `version = 1
+require_push_guard = false
+cache_ttl_days = 7
+
+[profiles.work]
+gh_config_dir = "/gh/work"
+ssh_host = "github.com-work"
+git_name = "Work"
+git_email = "w@acme.com"
+`
+
+func writeFile(t *testing.T, name, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	require.NoError(t, os.WriteFile(path, []byte(content), 0600))
+	return path
+}
+
+func TestLoadPolicy_Missing(t *testing.T) {
+	p, err := config.LoadPolicy(filepath.Join(t.TempDir(), "policy.toml"))
+	require.NoError(t, err)
+	assert.Nil(t, p)
+	assert.False(t, p.IsLocked("require_push_guard"))
+}
+
+func TestLoadPolicy_RejectsInvalidKeys(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+	}{
+		{"profiles", "[profiles.work]\nssh_host = \"x\"\n"},
+		{"unknown key", "no_such_key = 1\n"},
+		{"lock unknown key", "locked = [\"no_such_key\"]\n"},
+		{"lock without value", "locked = [\"cache_ttl_days\"]\n"},
+		{"locked not a list", "locked = \"cache_ttl_days\"\ncache_ttl_days = 1\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := config.LoadPolicy(writeFile(t, "policy.toml", tt.content))
+			require.Error(t, err)
+			assert.True(t, errors.Is(err, config.ErrConfig))
+		})
+	}
+}
+
+func TestLoadLayered(t *testing.T) {
+	policyPath := writeFile(t, "policy.toml", `require_push_guard = true
+cache_ttl_days = 30
+git_include_if = true
+locked = ["require_push_guard"]
+`)
+	policy, err := config.LoadPolicy(policyPath)
+	require.NoError(t, err)
+	cfgPath := writeFile(t, "config.toml", layeredUserConfig)
+
+	l, err := config.LoadLayered(cfgPath, policy)
+	require.NoError(t, err)
+
+	assert.True(t, l.Config.IsRequirePushGuard(), "잠긴 정책 값이 사용자 값을 이긴다")
+	assert.Equal(t, 7, l.Config.CacheTTLDays, "잠기지 않은 키는 사용자 값이 이긴다")
+	assert.True(t, l.Config.GitIncludeIf, "사용자가 정하지 않은 키는 정책 기본값을 쓴다")
+
+	require.Len(t, l.Ignored, 1)
+	assert.Equal(t, "require_push_guard", l.Ignored[0].Field)
+	assert.Equal(t, config.SeverityWarning, l.Ignored[0].Severity)
+
+	assert.Equal(t, config.Origin{Layer: "policy", Path: policyPath, Locked: true}, l.Origins["require_push_guard"])
+	assert.Equal(t, config.Origin{Layer: "user", Path: cfgPath}, l.Origins["cache_ttl_days"])
+	assert.Equal(t, config.Origin{Layer: "policy", Path: policyPath}, l.Origins["git_include_if"])
+	assert.Equal(t, config.Origin{Layer: "default"}, l.Origins["prompt_on_ambiguous"])
+	assert.Equal(t, config.Origin{Layer: "user", Path: cfgPath}, l.Origins["profiles.work.ssh_host"])
+	assert.Equal(t, "policy:"+policyPath+" (locked)", l.Origins["require_push_guard"].String())
+
+	data, err := os.ReadFile(cfgPath)
+	require.NoError(t, err)
+	assert.Equal(t, layeredUserConfig, string(data), "정책은 사용자 파일을 고치지 않는다")
+}
+
+func TestLoadLayered_LockedGuardIgnoresProfileOverride(t *testing.T) {
+	policyPath := writeFile(t, "policy.toml", `locked = ["guard"]
+
+[guard]
+user_email = "block"
+`)
+	policy, err := config.LoadPolicy(policyPath)
+	require.NoError(t, err)
+	cfgPath := writeFile(t, "config.toml", layeredUserConfig+`
+[profiles.work.guard]
+user_email = "off"
+user_name = "off"
+`)
+
+	l, err := config.LoadLayered(cfgPath, policy)
+	require.NoError(t, err)
+
+	g := l.Config.GuardChecksFor("work", nil)
+	assert.Equal(t, config.GuardBlock, g.UserEmail, "프로필 guard로 잠긴 검사를 끌 수 없다")
+	assert.Equal(t, config.GuardWarn, g.UserName, "잠긴 [guard] 테이블 아래 항목은 모두 기본값 이상으로 고정된다")
+	require.Len(t, l.Ignored, 2)
+	assert.Equal(t, "profiles.work.guard.user_email", l.Ignored[0].Field)
+	assert.Equal(t, "profiles.work.guard.user_name", l.Ignored[1].Field)
+
+	assert.True(t, policy.IsLocked("profiles.work.guard.user_email"))
+	assert.False(t, policy.IsLocked("profiles.work.git_email"))
+}
+
+func TestLoadLayered_NilPolicy(t *testing.T) {
+	cfgPath := writeFile(t, "config.toml", layeredUserConfig)
+
+	l, err := config.LoadLayered(cfgPath, nil)
+	require.NoError(t, err)
+	assert.False(t, l.Config.IsRequirePushGuard())
+	assert.Empty(t, l.Ignored)
+}
+
+func TestLoadRepoHints(t *testing.T) {
+	t.Run("missing", func(t *testing.T) {
+		h, err := config.LoadRepoHints(t.TempDir())
+		require.NoError(t, err)
+		assert.Nil(t, h)
+	})
+
+	t.Run("valid", func(t *testing.T) {
+		dir := t.TempDir()
+		require.NoError(t, os.WriteFile(filepath.Join(dir, config.RepoFileName), []byte(`host = "github.com"
+owner = "acme"
+email_domain = "@acme.com"
+`), 0644))
+
+		h, err := config.LoadRepoHints(dir)
+		require.NoError(t, err)
+		assert.Equal(t, "acme.com", h.EmailDomain)
+		assert.Equal(t, []config.KeyValue{
+			{Key: "repo.host", Value: "github.com"},
+			{Key: "repo.owner", Value: "acme"},
+			{Key: "repo.email_domain", Value: "acme.com"},
+		}, h.List())
+	})
+
+	t.Run("unknown key", func(t *testing.T) {
+		dir := t.TempDir()
+		require.NoError(t, os.WriteFile(filepath.Join(dir, config.RepoFileName), []byte("ssh_host = \"gh-work\"\n"), 0644))
+
+		_, err := config.LoadRepoHints(dir)
+		require.Error(t, err)
+		assert.True(t, errors.Is(err, config.ErrConfig))
+	})
+}
+
+func TestLoadLayered_PolicyEmailRules(t *testing.T) {
+	policy, err := config.LoadPolicy(writeFile(t, "policy.toml", `locked = ["email_rules"]
+
+[[email_rules]]
+name = "acme-only"
+owners = ["acme"]
+allow = ["*@acme.com"]
+`))
+	require.NoError(t, err)
+
+	l, err := config.LoadLayered(writeFile(t, "config.toml", layeredUserConfig+`
+[[email_rules]]
+name = "mine"
+deny = ["*@gmail.com"]
+`), policy)
+	require.NoError(t, err)
+	require.Len(t, l.Config.EmailRules, 1)
+	assert.Equal(t, "acme-only", l.Config.EmailRules[0].Name)
+	require.Len(t, l.Ignored, 1)
+	assert.Equal(t, "email_rules", l.Ignored[0].Field)
+}
@@ -0,0 +1,73 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+)
+
+// RepoFileName은 리포 최상위에 커밋하는 ctx 힌트 파일 이름이다.
+const RepoFileName = ".ctx.toml"
+
+// RepoHints는 리포의 .ctx.toml이 선언한 기대값이다.
+// 사용자 설정을 바꾸지 않으며, guard와 doctor가 현재 프로필과 checkout을 검사하는 데 쓴다.
+//
+//	host = "github.com"          # 프로필 ssh_host의 HostName
+//	owner = "company-org"        # origin remote의 owner
+//	email_domain = "company.com" # user.email 도메인
+//
+//	[guard]                      # 사용자 설정보다 엄격하게만 적용된다
+//	force_push = "block"
+type RepoHints struct {
+	Path        string      `toml:"-"`
+	Host        string      `toml:"host"`
+	Owner       string      `toml:"owner"`
+	EmailDomain string      `toml:"email_domain"`
+	Guard       GuardChecks `toml:"guard"`
+}
+
+// LoadRepoHints는 repoRoot의 .ctx.toml을 읽는다. 파일이 없으면 nil, nil을 반환한다.
+// 모르는 키가 있으면 오타일 수 있으므로 ErrConfig를 반환한다.
+func LoadRepoHints(repoRoot string) (*RepoHints, error) {
+	path := filepath.Join(repoRoot, RepoFileName)
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("config.LoadRepoHints: %w", err)
+	}
+
+	h := &RepoHints{Path: path}
+	md, err := toml.Decode(string(data), h)
+	if err != nil {
+		return nil, fmt.Errorf("config.LoadRepoHints: %s: %w: %w", path, err, ErrConfig)
+	}
+	if undecoded := md.Undecoded(); len(undecoded) > 0 {
+		keys := make([]string, len(undecoded))
+		for i, k := range undecoded {
+			keys[i] = k.String()
+		}
+		return nil, fmt.Errorf("config.LoadRepoHints: %s: 알 수 없는 키 %s: %w", path, strings.Join(keys, ", "), ErrConfig)
+	}
+	if invalid := h.Guard.invalidLevels(); len(invalid) > 0 {
+		return nil, fmt.Errorf("config.LoadRepoHints: %s: guard.%s = %q (off, warn, block 중 하나): %w", path, invalid[0].Key, invalid[0].Value, ErrConfig)
+	}
+	h.EmailDomain = strings.TrimPrefix(h.EmailDomain, "@")
+	return h, nil
+}
+
+// List는 선언된 힌트를 "repo.<key>" 형식으로 반환한다.
+func (h *RepoHints) List() []KeyValue {
+	var kvs []KeyValue
+	for _, kv := range appendFields(nil, "repo.", reflect.ValueOf(h).Elem()) {
+		if kv.Value != "" {
+			kvs = append(kvs, kv)
+		}
+	}
+	return kvs
+}
@@ -1,8 +1,10 @@
 package config_test
 
 import (
+	"errors"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/hbjs97/ctx/internal/config"
@@ -145,3 +147,52 @@ func TestSave_CreatesParentDir(t *testing.T) {
 	require.NoError(t, err)
 	assert.Equal(t, 1, loaded.Version)
 }
+
+func TestSave_RejectsInvalidConfig(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.toml")
+
+	cfg := &config.Config{
+		Version:        1,
+		DefaultProfile: "missing",
+		Profiles: map[string]config.Profile{
+			"work": {GHConfigDir: "/tmp/gh", SSHHost: "github-work", GitName: "Test", GitEmail: "t@t.com"},
+		},
+	}
+
+	err := config.Save(path, cfg)
+	require.Error(t, err)
+	assert.True(t, errors.Is(err, config.ErrConfig))
+	_, statErr := os.Stat(path)
+	assert.True(t, os.IsNotExist(statErr), "invalid config must not be written")
+}
+
+func TestUpdate(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.toml")
+	require.NoError(t, os.WriteFile(path, []byte(`# 주석
+version = 1
+
+[profiles.work]
+gh_config_dir = "/tmp/gh"
+ssh_host = "github-work"
+git_name = "Test"
+git_email = "t@t.com"
+`), 0600))
+
+	require.NoError(t, config.Update(path, func(cfg *config.Config) error {
+		return cfg.Set("profiles.work.owners", "org")
+	}))
+	cfg, err := config.Load(path)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"org"}, cfg.Profiles["work"].Owners)
+
+	err = config.Update(path, func(cfg *config.Config) error {
+		return cfg.Set("default_profile", "missing")
+	})
+	require.Error(t, err)
+	assert.True(t, errors.Is(err, config.ErrConfig))
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(string(data), "# 주석\n"))
+	assert.NotContains(t, string(data), "missing")
+}
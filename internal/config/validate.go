@@ -0,0 +1,200 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// Severity는 설정 검증 문제의 수준이다.
+type Severity string
+
+const (
+	// SeverityError는 ctx가 올바르게 동작할 수 없는 문제다. Load가 실패한다.
+	SeverityError Severity = "error"
+	// SeverityWarning은 동작은 하지만 의도와 다른 판정을 낳을 수 있는 문제다.
+	SeverityWarning Severity = "warning"
+)
+
+// Issue는 설정 검증에서 발견된 문제 하나다.
+type Issue struct {
+	Severity Severity
+	Field    string // 예: "profiles.work.ssh_host"
+	Message  string
+	// Conflict는 프로필 간 값 중복이다. 저장은 거부하지만, 이미 디스크에 있는 설정은
+	// 모든 명령이 멈추지 않도록 Load가 경고만 하고 읽는다.
+	Conflict bool
+}
+
+func (i Issue) String() string {
+	return fmt.Sprintf("%s %s", i.Field, i.Message)
+}
+
+// Decode는 config.toml을 파싱하고 마이그레이션과 기본값만 적용한다. 검증은 하지 않는다.
+// 잘못된 설정을 진단하거나 고칠 때 사용한다.
+func Decode(path string) (*Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("config.Decode: %w", err)
+	}
+	cfg, _, err := decodeDocument(data)
+	if err != nil {
+		return nil, fmt.Errorf("config.Decode: %w", err)
+	}
+	return cfg, nil
+}
+
+// Validate는 필수 필드와 프로필 간 충돌을 검사해 발견된 모든 문제를 반환한다.
+// hasSSHHost가 nil이 아니면 각 프로필의 ssh_host alias가 SSH config에 있는지도 확인한다.
+// 결과는 필드 이름순으로 정렬된다.
+func (c *Config) Validate(hasSSHHost func(host string) bool) []Issue {
+	var issues []Issue
+	add := func(sev Severity, field, format string, args ...any) {
+		issues = append(issues, Issue{Severity: sev, Field: field, Message: fmt.Sprintf(format, args...)})
+	}
+
+	if len(c.Profiles) == 0 {
+		add(SeverityError, "profiles", "프로필이 정의되지 않았습니다")
+		return issues
+	}
+	if c.DefaultProfile != "" {
+		if _, ok := c.Profiles[c.DefaultProfile]; !ok {
+			add(SeverityError, "default_profile", "존재하지 않는 프로필 %q", c.DefaultProfile)
+		}
+	}
+
+	names := make([]string, 0, len(c.Profiles))
+	for name := range c.Profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	// 값 → 그 값을 쓰는 프로필 목록
+	sshHosts := make(map[string][]string)
+	ghDirs := make(map[string][]string)
+	emails := make(map[string][]string)
+	owners := make(map[string][]string)
+	githubUsers := make(map[string][]string)
+
+	for _, name := range names {
+		p := c.Profiles[name]
+		prefix := "profiles." + name
+		for _, f := range []struct{ key, value string }{
+			{"gh_config_dir", p.GHConfigDir},
+			{"ssh_host", p.SSHHost},
+			{"git_name", p.GitName},
+			{"git_email", p.GitEmail},
+		} {
+			if f.value == "" {
+				add(SeverityError, prefix+"."+f.key, "필수")
+			}
+		}
+
+		if p.SSHHost != "" {
+			sshHosts[p.SSHHost] = append(sshHosts[p.SSHHost], name)
+			// alias가 아닌 실제 GitHub 호스트 이름은 Host 블록 없이도 동작한다
+			if hasSSHHost != nil && !isGitHubHostName(p.SSHHost) && !hasSSHHost(p.SSHHost) {
+				add(SeverityWarning, prefix+".ssh_host", "SSH config에 Host %s 없음", p.SSHHost)
+			}
+		}
+		if p.GHConfigDir != "" {
+			ghDirs[p.GHConfigDir] = append(ghDirs[p.GHConfigDir], name)
+		}
+		if p.GitEmail != "" {
+			key := strings.ToLower(p.GitEmail)
+			emails[key] = append(emails[key], name)
+		}
+		if p.GitHubUser != "" {
+			key := strings.ToLower(p.GitHubUser)
+			githubUsers[key] = append(githubUsers[key], name)
+		}
+		for _, o := range p.EffectiveOwners() {
+			key := strings.ToLower(o)
+			if !slices.Contains(owners[key], name) {
+				owners[key] = append(owners[key], name)
+			}
+		}
+	}
+
+	for _, d := range []struct {
+		values   map[string][]string
+		key      string
+		severity Severity
+		reason   string
+	}{
+		{sshHosts, "ssh_host", SeverityError, "같은 SSH 키로 인증되어 계정이 섞임"},
+		{ghDirs, "gh_config_dir", SeverityError, "gh 인증을 공유해 계정이 섞임"},
+		{emails, "git_email", SeverityWarning, "커밋 작성자로 프로필을 구분할 수 없음"},
+		{githubUsers, "github_user", SeverityWarning, "같은 GitHub 계정이라 프로필을 나눈 의미가 없음"},
+		{owners, "owners", SeverityWarning, "owner 규칙으로 판정하지 못하고 권한 probe로 넘어감"},
+	} {
+		for value, profiles := range d.values {
+			if len(profiles) < 2 {
+				continue
+			}
+			issues = append(issues, Issue{
+				Severity: d.severity,
+				Field:    "profiles." + profiles[0] + "." + d.key,
+				Message:  fmt.Sprintf("%q이 프로필 %s에서 중복 — %s", value, strings.Join(profiles, ", "), d.reason),
+				Conflict: true,
+			})
+		}
+	}
+
+	for _, kv := range c.Guard.invalidLevels() {
+		add(SeverityError, "guard."+kv.Key, "알 수 없는 수준 %q (off, warn, block 중 하나)", kv.Value)
+	}
+	for _, name := range names {
+		p := c.Profiles[name]
+		for _, kv := range p.Guard.invalidLevels() {
+			add(SeverityError, "profiles."+name+".guard."+kv.Key, "알 수 없는 수준 %q (off, warn, block 중 하나)", kv.Value)
+		}
+		for _, pattern := range p.ProtectedBranches {
+			if _, err := path.Match(pattern, ""); err != nil {
+				add(SeverityError, "profiles."+name+".protected_branches", "잘못된 브랜치 패턴 %q", pattern)
+			}
+		}
+	}
+
+	for i, r := range c.EmailRules {
+		field := fmt.Sprintf("email_rules[%d]", i)
+		if len(r.Allow) == 0 && len(r.Deny) == 0 && !r.RequireSigning {
+			add(SeverityWarning, field, "allow, deny, require_signing이 모두 없어 효과 없음")
+		}
+		for _, pattern := range slices.Concat(r.Allow, r.Deny) {
+			if _, err := path.Match(pattern, ""); err != nil {
+				add(SeverityError, field, "잘못된 이메일 패턴 %q", pattern)
+			}
+		}
+	}
+
+	sort.SliceStable(issues, func(i, j int) bool {
+		if issues[i].Field != issues[j].Field {
+			return issues[i].Field < issues[j].Field
+		}
+		return issues[i].Message < issues[j].Message
+	})
+	return issues
+}
+
+// IssuesError는 issues에 error 수준 문제가 있으면 ErrConfig로 감싼 에러 하나로 묶어 반환한다.
+func IssuesError(issues []Issue) error {
+	var msgs []string
+	for _, i := range issues {
+		if i.Severity == SeverityError {
+			msgs = append(msgs, i.String())
+		}
+	}
+	if len(msgs) == 0 {
+		return nil
+	}
+	return fmt.Errorf("%s: %w", strings.Join(msgs, "; "), ErrConfig)
+}
+
+// isGitHubHostName은 host가 alias가 아닌 GitHub SSH 엔드포인트 이름인지 반환한다.
+func isGitHubHostName(host string) bool {
+	return strings.EqualFold(host, "github.com") || strings.EqualFold(host, "ssh.github.com")
+}
@@ -0,0 +1,173 @@
+package config_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/hbjs97/ctx/internal/config"
+	"github.com/hbjs97/ctx/internal/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func validProfile(sshHost, ghDir, email string, owners ...string) config.Profile {
+	return config.Profile{GHConfigDir: ghDir, SSHHost: sshHost, GitName: "Test", GitEmail: email, Owners: owners}
+}
+
+func issueFields(issues []config.Issue, sev config.Severity) []string {
+	var fields []string
+	for _, i := range issues {
+		if i.Severity == sev {
+			fields = append(fields, i.Field)
+		}
+	}
+	return fields
+}
+
+func TestValidate_NoIssues(t *testing.T) {
+	cfg := &config.Config{
+		DefaultProfile: "work",
+		Profiles: map[string]config.Profile{
+			"work":     validProfile("github.com-work", "/gh/work", "w@acme.com", "acme"),
+			"personal": validProfile("github.com-personal", "/gh/personal", "me@example.com", "me"),
+		},
+	}
+	assert.Empty(t, cfg.Validate(func(string) bool { return true }))
+}
+
+func TestValidate_Errors(t *testing.T) {
+	cfg := &config.Config{
+		DefaultProfile: "missing",
+		Profiles: map[string]config.Profile{
+			"work":     validProfile("github.com-work", "/gh/shared", "w@acme.com"),
+			"personal": validProfile("github.com-work", "/gh/shared", "me@example.com"),
+			"broken":   {SSHHost: "github.com-broken", GitName: "B", GitEmail: "b@example.com"},
+		},
+	}
+	issues := cfg.Validate(nil)
+
+	assert.Equal(t, []string{
+		"default_profile",
+		"profiles.broken.gh_config_dir",
+		"profiles.personal.gh_config_dir",
+		"profiles.personal.ssh_host",
+	}, issueFields(issues, config.SeverityError))
+	assert.Empty(t, issueFields(issues, config.SeverityWarning))
+}
+
+func TestValidate_SkipsHostBlockCheckForGitHubHostName(t *testing.T) {
+	cfg := &config.Config{
+		Profiles: map[string]config.Profile{
+			"work":     validProfile("github.com", "/gh/work", "w@acme.com", "acme"),
+			"personal": validProfile("github.com-personal", "/gh/personal", "me@example.com", "me"),
+		},
+	}
+	issues := cfg.Validate(func(string) bool { return false })
+	assert.Equal(t, []string{"profiles.personal.ssh_host"}, issueFields(issues, config.SeverityWarning),
+		"alias가 아닌 github.com은 Host 블록이 없어도 경고하지 않는다")
+}
+
+func TestLoad_DuplicateHostWarnsInsteadOfFailing(t *testing.T) {
+	path := testutil.TempConfigFile(t, `version = 1
+[profiles.work]
+gh_config_dir = "/gh/shared"
+ssh_host = "github.com-work"
+git_name = "Work"
+git_email = "w@acme.com"
+
+[profiles.personal]
+gh_config_dir = "/gh/shared"
+ssh_host = "github.com-work"
+git_name = "Me"
+git_email = "me@example.com"
+`)
+	cfg, err := config.Load(path)
+	require.NoError(t, err, "이미 디스크에 있는 중복 설정은 읽는다")
+	assert.Len(t, cfg.Profiles, 2)
+
+	err = config.Save(path, cfg)
+	require.Error(t, err, "중복이 남은 설정은 저장하지 않는다")
+	assert.True(t, errors.Is(err, config.ErrConfig))
+}
+
+func TestValidate_Warnings(t *testing.T) {
+	cfg := &config.Config{
+		Profiles: map[string]config.Profile{
+			"work":     validProfile("github.com-work", "/gh/work", "Me@Example.com", "shared-org", "acme"),
+			"personal": validProfile("github.com-personal", "/gh/personal", "me@example.com", "Shared-Org"),
+		},
+	}
+	issues := cfg.Validate(func(host string) bool { return host == "github.com-work" })
+
+	assert.Empty(t, issueFields(issues, config.SeverityError))
+	assert.Equal(t, []string{
+		"profiles.personal.git_email",
+		"profiles.personal.owners",
+		"profiles.personal.ssh_host",
+	}, issueFields(issues, config.SeverityWarning))
+	assert.Contains(t, issues[1].Message, "personal, work")
+	assert.NoError(t, config.IssuesError(issues), "warning만 있으면 에러 아님")
+}
+
+func TestValidate_GitHubUser(t *testing.T) {
+	work := validProfile("github.com-work", "/gh/work", "w@acme.com", "acme")
+	work.GitHubUser = "hbjs97"
+	personal := validProfile("github.com-personal", "/gh/personal", "me@example.com", "HBJS97")
+	personal.GitHubUser = "hbjs97"
+	cfg := &config.Config{Profiles: map[string]config.Profile{"work": work, "personal": personal}}
+
+	assert.Equal(t, []string{
+		"profiles.personal.github_user",
+		"profiles.personal.owners",
+	}, issueFields(cfg.Validate(nil), config.SeverityWarning), "github_user도 owner 중복 검사에 포함")
+}
+
+func TestLoad_RejectsSemanticErrors(t *testing.T) {
+	path := testutil.TempConfigFile(t, `version = 1
+default_profile = "gone"
+
+[profiles.work]
+gh_config_dir = "/gh/work"
+ssh_host = "github.com-work"
+git_name = "Work"
+git_email = "w@acme.com"
+`)
+	_, err := config.Load(path)
+	require.Error(t, err)
+	assert.True(t, errors.Is(err, config.ErrConfig))
+	assert.Contains(t, err.Error(), "default_profile")
+
+	cfg, err := config.Decode(path)
+	require.NoError(t, err, "Decode는 검증하지 않는다")
+	assert.Equal(t, "gone", cfg.DefaultProfile)
+}
+
+func TestValidate_EmailRules(t *testing.T) {
+	cfg := &config.Config{
+		EmailRules: []config.EmailRule{
+			{Name: "acme-only", Owners: []string{"acme"}, Allow: []string{"*@acme.com"}},
+			{Name: "noop"},
+			{Deny: []string{"[gmail.com"}},
+		},
+		Profiles: map[string]config.Profile{
+			"work": validProfile("github.com-work", "/gh/work", "w@acme.com", "acme"),
+		},
+	}
+	issues := cfg.Validate(nil)
+
+	assert.Equal(t, []string{"email_rules[2]"}, issueFields(issues, config.SeverityError))
+	assert.Equal(t, []string{"email_rules[1]"}, issueFields(issues, config.SeverityWarning))
+}
+
+func TestEmailRule(t *testing.T) {
+	r := config.EmailRule{Owners: []string{"Acme"}}
+	assert.True(t, r.AppliesTo("acme"))
+	assert.False(t, r.AppliesTo("other"))
+	assert.True(t, config.EmailRule{}.AppliesTo(""), "owners가 없으면 전역 규칙")
+	assert.Equal(t, "email_rules[3]", r.Label(3))
+
+	assert.True(t, config.MatchEmail("*@acme.com", "Dev@ACME.com"))
+	assert.True(t, config.MatchEmail("@acme.com", "dev@acme.com"))
+	assert.False(t, config.MatchEmail("@acme.com", "dev@acme.com.evil.io"))
+	assert.True(t, config.MatchEmail("*@users.noreply.github.com", "123+dev@users.noreply.github.com"))
+}
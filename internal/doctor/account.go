@@ -0,0 +1,126 @@
+package doctor
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/hbjs97/ctx/internal/cmdexec"
+	"github.com/hbjs97/ctx/internal/gh"
+)
+
+// ParseSSHLogin은 ssh -T 출력에서 인증된 GitHub 로그인을 찾는다.
+func ParseSSHLogin(output string) (string, bool) {
+	return gh.ParseSSHLogin(output)
+}
+
+// GHLogin은 GH_CONFIG_DIR의 gh 인증이 가리키는 GitHub 로그인을 반환한다.
+func GHLogin(ctx context.Context, cmd cmdexec.Commander, ghConfigDir string) (string, error) {
+	login, err := gh.NewAdapter(cmd).Login(ctx, ghConfigDir)
+	if err != nil {
+		return "", fmt.Errorf("doctor.GHLogin: %w", err)
+	}
+	return login, nil
+}
+
+// CheckSSHAccount는 SSH 키로 인증되는 계정이 프로필 계정과 같은지 확인한다.
+// githubUser(프로필의 github_user)가 있으면 그 값과, 없으면 gh 로그인 계정과 비교한다.
+// 로그인이 빈 문자열이면 확인하지 못한 것으로 본다.
+// ssh-agent에 다른 계정의 키가 먼저 올라와 있으면 회사 alias가 개인 계정으로 인증되는 일이 흔하다.
+func CheckSSHAccount(sshHost, sshLogin, ghLogin, githubUser string) DiagResult {
+	name := fmt.Sprintf("ssh_account_%s", sshHost)
+	expected, source := githubUser, "github_user"
+	if expected == "" {
+		expected, source = ghLogin, "gh"
+	}
+	switch {
+	case sshLogin == "":
+		return DiagResult{
+			Name:    name,
+			Status:  StatusWarn,
+			Message: fmt.Sprintf("SSH %s 인증 계정 확인 불가", sshHost),
+			Fix:     fmt.Sprintf("ssh -T git@%s 로 인증 계정 확인", sshHost),
+		}
+	case expected == "":
+		return DiagResult{
+			Name:    name,
+			Status:  StatusWarn,
+			Message: "gh 로그인 계정 확인 불가",
+			Fix:     "gh auth status 로 인증 상태 확인",
+		}
+	case !strings.EqualFold(sshLogin, expected):
+		return DiagResult{
+			Name:    name,
+			Status:  StatusFail,
+			Message: fmt.Sprintf("SSH %s는 %s로 인증되지만 %s 계정은 %s", sshHost, sshLogin, source, expected),
+			Fix:     fmt.Sprintf("Host %s의 IdentityFile을 %s 계정의 키로 지정하고 IdentitiesOnly yes 설정", sshHost, expected),
+		}
+	}
+	return DiagResult{
+		Name:    name,
+		Status:  StatusOK,
+		Message: fmt.Sprintf("SSH %s와 %s 모두 %s로 인증", sshHost, source, sshLogin),
+	}
+}
+
+// CheckGHAccount는 gh 설정 디렉토리가 프로필의 github_user 계정으로 로그인되어 있는지 확인한다.
+// 다른 계정으로 gh auth login을 다시 하면 probe와 토큰이 엉뚱한 계정으로 동작한다.
+func CheckGHAccount(ghConfigDir, ghLogin, githubUser string) DiagResult {
+	switch {
+	case ghLogin == "":
+		return DiagResult{
+			Name:    "gh_account",
+			Status:  StatusWarn,
+			Message: "gh 로그인 계정 확인 불가",
+			Fix:     "gh auth status 로 인증 상태 확인",
+		}
+	case !strings.EqualFold(ghLogin, githubUser):
+		return DiagResult{
+			Name:    "gh_account",
+			Status:  StatusFail,
+			Message: fmt.Sprintf("%s는 %s로 로그인되어 있지만 github_user는 %s", ghConfigDir, ghLogin, githubUser),
+			Fix:     fmt.Sprintf("GH_CONFIG_DIR=%s gh auth login 으로 %s 계정에 다시 로그인", ghConfigDir, githubUser),
+		}
+	}
+	return DiagResult{
+		Name:    "gh_account",
+		Status:  StatusOK,
+		Message: fmt.Sprintf("gh 로그인 계정 %s", ghLogin),
+	}
+}
+
+// CheckSSHAccountsUnique는 서로 다른 프로필의 SSH alias가 같은 계정으로 인증되는지 확인한다.
+// logins는 프로필 이름 → SSH 로그인이며, 빈 로그인은 건너뛴다.
+func CheckSSHAccountsUnique(logins map[string]string) DiagResult {
+	byLogin := make(map[string][]string)
+	for profile, login := range logins {
+		if login == "" {
+			continue
+		}
+		key := strings.ToLower(login)
+		byLogin[key] = append(byLogin[key], profile)
+	}
+
+	var dups []string
+	for login, profiles := range byLogin {
+		if len(profiles) > 1 {
+			sort.Strings(profiles)
+			dups = append(dups, fmt.Sprintf("%s(%s)", login, strings.Join(profiles, ", ")))
+		}
+	}
+	if len(dups) > 0 {
+		sort.Strings(dups)
+		return DiagResult{
+			Name:    "ssh_account_unique",
+			Status:  StatusFail,
+			Message: fmt.Sprintf("여러 프로필의 SSH alias가 같은 계정으로 인증됨: %s", strings.Join(dups, "; ")),
+			Fix:     "프로필마다 다른 계정의 키를 IdentityFile로 지정하고 IdentitiesOnly yes 설정",
+		}
+	}
+	return DiagResult{
+		Name:    "ssh_account_unique",
+		Status:  StatusOK,
+		Message: "프로필별 SSH 계정이 모두 다름",
+	}
+}
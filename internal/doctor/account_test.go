@@ -0,0 +1,177 @@
+package doctor_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/hbjs97/ctx/internal/config"
+	"github.com/hbjs97/ctx/internal/doctor"
+	"github.com/hbjs97/ctx/internal/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseSSHLogin(t *testing.T) {
+	login, ok := doctor.ParseSSHLogin("Hi octo-cat! You've successfully authenticated, but GitHub does not provide shell access.\n")
+	require.True(t, ok)
+	assert.Equal(t, "octo-cat", login)
+
+	_, ok = doctor.ParseSSHLogin("git@github.com: Permission denied (publickey).\n")
+	assert.False(t, ok)
+}
+
+func TestCheckSSHAccount(t *testing.T) {
+	tests := []struct {
+		name     string
+		sshLogin string
+		ghLogin  string
+		user     string
+		want     doctor.Status
+	}{
+		{name: "same", sshLogin: "hbjs-work", ghLogin: "hbjs-work", want: doctor.StatusOK},
+		{name: "case insensitive", sshLogin: "HBJS-work", ghLogin: "hbjs-work", want: doctor.StatusOK},
+		{name: "agent key of other account", sshLogin: "hbjs97", ghLogin: "hbjs-work", want: doctor.StatusFail},
+		{name: "ssh unknown", ghLogin: "hbjs-work", want: doctor.StatusWarn},
+		{name: "gh unknown", sshLogin: "hbjs-work", want: doctor.StatusWarn},
+		{name: "github_user preferred", sshLogin: "hbjs-work", ghLogin: "hbjs97", user: "hbjs-work", want: doctor.StatusOK},
+		{name: "github_user mismatch", sshLogin: "hbjs97", user: "hbjs-work", want: doctor.StatusFail},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := doctor.CheckSSHAccount("github.com-work", tt.sshLogin, tt.ghLogin, tt.user)
+			assert.Equal(t, tt.want, result.Status, result.Message)
+		})
+	}
+}
+
+func TestCheckGHAccount(t *testing.T) {
+	assert.Equal(t, doctor.StatusOK, doctor.CheckGHAccount("/tmp/gh-work", "HBJS-work", "hbjs-work").Status)
+	assert.Equal(t, doctor.StatusWarn, doctor.CheckGHAccount("/tmp/gh-work", "", "hbjs-work").Status)
+
+	result := doctor.CheckGHAccount("/tmp/gh-work", "hbjs97", "hbjs-work")
+	assert.Equal(t, doctor.StatusFail, result.Status, "gh 설정 디렉토리가 다른 계정으로 로그인됨")
+	assert.Contains(t, result.Fix, "GH_CONFIG_DIR=/tmp/gh-work gh auth login")
+}
+
+func TestCheckSSHAccountsUnique(t *testing.T) {
+	assert.Equal(t, doctor.StatusOK, doctor.CheckSSHAccountsUnique(map[string]string{
+		"work": "hbjs-work", "personal": "hbjs97", "broken": "",
+	}).Status)
+
+	result := doctor.CheckSSHAccountsUnique(map[string]string{
+		"work": "hbjs97", "personal": "hbjs97", "oss": "hbjs-oss",
+	})
+	assert.Equal(t, doctor.StatusFail, result.Status)
+	assert.Contains(t, result.Message, "hbjs97(personal, work)")
+}
+
+func TestChecks_SSHAccount_ReusesSSHLogin(t *testing.T) {
+	fake := testutil.NewFakeCommander()
+	fake.Register("ssh -T git@github.com-work", "Hi hbjs97! You've successfully authenticated, but GitHub does not provide shell access.", fmt.Errorf("exit status 1"))
+	fake.Register("ssh -T git@github.com-personal", "Hi hbjs97! You've successfully authenticated, but GitHub does not provide shell access.", fmt.Errorf("exit status 1"))
+	fake.Register("gh api user", "hbjs-work\n", nil)
+
+	env := &doctor.Env{
+		Commander: fake,
+		Config: &config.Config{Profiles: map[string]config.Profile{
+			"work":     {GHConfigDir: "/tmp/gh-work", SSHHost: "github.com-work"},
+			"personal": {GHConfigDir: "/tmp/gh-personal", SSHHost: "github.com-personal"},
+		}},
+	}
+	checks, err := doctor.Select([]string{"ssh_account", "ssh_account_unique"})
+	require.NoError(t, err)
+
+	var results []doctor.DiagResult
+	for _, c := range checks {
+		if c.PerProfile {
+			results = append(results, c.Run(context.Background(), env, "work")...)
+		} else {
+			results = append(results, c.Run(context.Background(), env, "")...)
+		}
+	}
+	require.Len(t, results, 2)
+	assert.Equal(t, doctor.StatusFail, results[0].Status, "work alias가 개인 계정으로 인증됨")
+	assert.Equal(t, doctor.StatusFail, results[1].Status, "두 프로필이 같은 계정")
+
+	assert.Equal(t, 1, fake.CallCount("ssh -T git@github.com-work"), "ssh -T는 Host마다 한 번만 실행")
+}
+
+func TestChecks_GHAccount(t *testing.T) {
+	fake := testutil.NewFakeCommander()
+	fake.Register("ssh -T git@github.com-work", "Hi hbjs-work! You've successfully authenticated, but GitHub does not provide shell access.", fmt.Errorf("exit status 1"))
+	fake.Register("gh api user", "hbjs97\n", nil)
+
+	env := &doctor.Env{
+		Commander: fake,
+		Config: &config.Config{Profiles: map[string]config.Profile{
+			"work":     {GHConfigDir: "/tmp/gh-work", SSHHost: "github.com-work", GitHubUser: "hbjs-work"},
+			"personal": {GHConfigDir: "/tmp/gh-personal", SSHHost: "github.com-personal"},
+		}},
+	}
+	checks, err := doctor.Select([]string{"ssh_account", "gh_account"})
+	require.NoError(t, err)
+
+	var results []doctor.DiagResult
+	for _, c := range checks {
+		results = append(results, c.Run(context.Background(), env, "work")...)
+	}
+	require.Len(t, results, 2)
+	assert.Equal(t, doctor.StatusOK, results[0].Status, "SSH는 github_user와 비교")
+	assert.Equal(t, doctor.StatusFail, results[1].Status, "gh 설정 디렉토리가 다른 계정으로 로그인됨")
+	assert.Equal(t, 1, fake.CallCount("gh api user"), "gh 로그인은 한 번만 조회")
+
+	for _, c := range checks {
+		if c.Name == "gh_account" {
+			assert.Empty(t, c.Run(context.Background(), env, "personal"), "github_user가 없으면 건너뜀")
+		}
+	}
+}
+
+func TestChecks_SSHAccount_DoesNotCacheFailedLookup(t *testing.T) {
+	fake := testutil.NewFakeCommander()
+	fake.Register("ssh -T git@github.com-work", "", context.DeadlineExceeded)
+	fake.Register("gh api user", "hbjs-work\n", nil)
+
+	env := &doctor.Env{
+		Commander: fake,
+		Config: &config.Config{Profiles: map[string]config.Profile{
+			"work": {GHConfigDir: "/tmp/gh-work", SSHHost: "github.com-work"},
+		}},
+	}
+	checks, err := doctor.Select([]string{"ssh_account"})
+	require.NoError(t, err)
+
+	results := checks[0].Run(context.Background(), env, "work")
+	require.Len(t, results, 1)
+	assert.Equal(t, doctor.StatusWarn, results[0].Status, "시간 초과는 확인하지 못한 것으로 보고")
+
+	fake.Register("ssh -T git@github.com-work", "Hi hbjs-work! You've successfully authenticated, but GitHub does not provide shell access.", fmt.Errorf("exit status 1"))
+	results = checks[0].Run(context.Background(), env, "work")
+	require.Len(t, results, 1)
+	assert.Equal(t, doctor.StatusOK, results[0].Status, "실패한 조회는 재사용하지 않고 다시 조회한다")
+	assert.Equal(t, 2, fake.CallCount("ssh -T git@github.com-work"))
+}
+
+func TestRunTasks_SharesSSHProbeAcrossChecks(t *testing.T) {
+	fake := testutil.NewFakeCommander()
+	fake.Register("ssh -T git@github.com-work", "Hi hbjs97! You've successfully authenticated, but GitHub does not provide shell access.", fmt.Errorf("exit status 1"))
+	fake.Register("ssh -T git@github.com-personal", "Hi hbjs97! You've successfully authenticated, but GitHub does not provide shell access.", fmt.Errorf("exit status 1"))
+
+	cfg := &config.Config{Profiles: map[string]config.Profile{
+		"work":     {GHConfigDir: "/tmp/gh-work", SSHHost: "github.com-work", GitHubUser: "hbjs-work"},
+		"personal": {GHConfigDir: "/tmp/gh-personal", SSHHost: "github.com-personal", GitHubUser: "hbjs97"},
+	}}
+	env := &doctor.Env{Commander: fake, Config: cfg}
+	checks, err := doctor.Select([]string{"ssh", "ssh_account", "ssh_account_unique"})
+	require.NoError(t, err)
+
+	results := doctor.RunTasks(context.Background(), env, doctor.Plan(checks, cfg), doctor.RunOptions{})
+	require.Len(t, results, 5)
+	assert.Equal(t, "ssh_account_unique", results[0].Task.Check.Name)
+	require.Len(t, results[0].Results, 1)
+	assert.Equal(t, doctor.StatusFail, results[0].Results[0].Status, "두 프로필이 같은 계정")
+
+	assert.Equal(t, 1, fake.CallCount("ssh -T git@github.com-work"), "ssh, ssh_account, ssh_account_unique가 ssh -T 한 번을 공유")
+	assert.Equal(t, 1, fake.CallCount("ssh -T git@github.com-personal"))
+}
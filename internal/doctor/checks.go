@@ -0,0 +1,694 @@
+package doctor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/hbjs97/ctx/internal/cache"
+	"github.com/hbjs97/ctx/internal/cmdexec"
+	"github.com/hbjs97/ctx/internal/config"
+	"github.com/hbjs97/ctx/internal/gh"
+	"github.com/hbjs97/ctx/internal/gitinclude"
+	"github.com/hbjs97/ctx/internal/shell"
+	"github.com/hbjs97/ctx/internal/sshconfig"
+)
+
+// tokenExpiryWarnDays는 토큰 만료 임박 경고 기준(일)이다.
+const tokenExpiryWarnDays = 7
+
+// conflictingHelpers는 계정 구분 없이 github.com 자격 증명을 공유하는 credential helper다.
+var conflictingHelpers = map[string]bool{
+	"osxkeychain":  true,
+	"manager":      true,
+	"manager-core": true,
+	"wincred":      true,
+	"libsecret":    true,
+	"store":        true,
+	"cache":        true,
+}
+
+// Env는 진단 항목 실행에 필요한 환경이다.
+type Env struct {
+	Commander  cmdexec.Commander
+	Config     *config.Config // 로드 실패 시 nil
+	ConfigPath string
+	ConfigErr  error
+	CachePath  string
+	// ConfigIgnored는 팀 정책 잠금 때문에 무시된 사용자 설정 값이다.
+	ConfigIgnored []config.Issue
+
+	SSHConfigPath string
+
+	ShellType   string
+	ShellRCPath string
+
+	// RepoDir은 현재 작업 디렉토리다. 리포 밖일 수 있다.
+	RepoDir string
+	// Repo는 리포 진단 대상이다. --repo 모드가 아니면 nil.
+	Repo *RepoInfo
+
+	Now func() time.Time // 테스트용. nil이면 time.Now.
+
+	mu        sync.Mutex
+	sshProbes map[string]*memo[sshProbe] // SSH Host별 ssh -T 결과. ssh, ssh_account, ssh_account_unique가 공유한다.
+	ghLogins  map[string]*memo[string]   // gh 설정 디렉토리별 로그인. gh api user 반복 호출을 막는다.
+}
+
+// memo는 Host나 gh 설정 디렉토리 하나의 조회 결과다.
+// 동시에 조회하면 차례로 기다리며, 성공한 결과만 재사용한다.
+// 한 작업의 timeout이나 취소로 실패한 조회가 다른 작업의 결과가 되지 않게 한다.
+type memo[T any] struct {
+	mu  sync.Mutex
+	val T
+	ok  bool
+}
+
+// get은 저장된 결과가 있으면 반환하고, 없으면 lookup을 실행해 성공한 결과만 저장한다.
+func (m *memo[T]) get(lookup func() (T, error)) (T, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.ok {
+		return m.val, nil
+	}
+	v, err := lookup()
+	if err != nil {
+		return v, err // 다음 호출이 다시 조회한다
+	}
+	m.val, m.ok = v, true
+	return v, nil
+}
+
+// memoFor는 m에서 key의 memo를 찾고 없으면 만든다.
+func memoFor[T any](e *Env, m *map[string]*memo[T], key string) *memo[T] {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if *m == nil {
+		*m = make(map[string]*memo[T])
+	}
+	entry, ok := (*m)[key]
+	if !ok {
+		entry = &memo[T]{}
+		(*m)[key] = entry
+	}
+	return entry
+}
+
+// sshProbe는 ssh -T git@<host> 한 번의 출력과 오류다.
+type sshProbe struct {
+	out string
+	err error
+}
+
+func (e *Env) now() time.Time {
+	if e.Now != nil {
+		return e.Now()
+	}
+	return time.Now()
+}
+
+// sshProbe는 SSH Host로 ssh -T를 실행하고 결과를 재사용한다.
+// 취소·시간 초과이거나 출력 없이 실패한 결과는 재사용하지 않는다.
+func (e *Env) sshProbe(ctx context.Context, sshHost string) sshProbe {
+	p, _ := memoFor(e, &e.sshProbes, sshHost).get(func() (sshProbe, error) {
+		out, err := e.Commander.Run(ctx, "ssh", "-T", fmt.Sprintf("git@%s", sshHost))
+		p := sshProbe{out: string(out), err: err}
+		if ctx.Err() != nil {
+			return p, ctx.Err()
+		}
+		if err != nil && len(out) == 0 {
+			return p, err
+		}
+		return p, nil
+	})
+	return p
+}
+
+// sshLogin은 SSH Host로 인증되는 로그인을 반환한다. 확인하지 못하면 빈 문자열이다.
+func (e *Env) sshLogin(ctx context.Context, sshHost string) string {
+	login, _ := ParseSSHLogin(e.sshProbe(ctx, sshHost).out)
+	return login
+}
+
+// ghLogin은 gh 설정 디렉토리의 로그인을 조회하고 성공하면 재사용한다. 확인하지 못하면 빈 문자열이다.
+func (e *Env) ghLogin(ctx context.Context, ghConfigDir string) string {
+	login, _ := memoFor(e, &e.ghLogins, ghConfigDir).get(func() (string, error) {
+		return GHLogin(ctx, e.Commander, ghConfigDir)
+	})
+	return login
+}
+
+// identityFile은 프로필의 SSH 개인 키 경로를 찾는다.
+// 프로필에 identity_file이 없으면 SSH config에서 Host의 IdentityFile을 조회한다.
+func (e *Env) identityFile(p config.Profile) string {
+	if p.IdentityFile != "" {
+		return p.IdentityFile
+	}
+	if e.SSHConfigPath == "" {
+		return ""
+	}
+	c, err := sshconfig.Load(e.SSHConfigPath)
+	if err != nil {
+		return ""
+	}
+	return c.Lookup(p.SSHHost, "IdentityFile")
+}
+
+// hostName은 SSH Host alias의 실제 HostName을 반환한다. SSH config를 읽을 수 없으면 alias 자체다.
+func (e *Env) hostName(alias string) string {
+	if e.SSHConfigPath == "" {
+		return alias
+	}
+	c, err := sshconfig.Load(e.SSHConfigPath)
+	if err != nil {
+		return alias
+	}
+	return c.HostName(alias)
+}
+
+// Check는 이름으로 실행할 수 있는 진단 항목이다.
+type Check struct {
+	Name        string
+	Description string
+	// PerProfile이 true면 프로필마다 실행되며, Run의 profile 인자로 프로필 이름이 전달된다.
+	PerProfile bool
+	// Repo가 true면 현재 checkout을 진단하며 Env.Repo가 필요하다. ctx doctor --repo에서 실행된다.
+	Repo bool
+	// Aggregate가 true면 다른 항목이 모두 끝난 뒤 실행되어 그 항목들이 조회한 결과를 모은다.
+	Aggregate bool
+	Run       func(ctx context.Context, env *Env, profile string) []DiagResult
+}
+
+// registry는 ctx doctor가 실행하는 진단 항목 목록이다. 출력 순서를 따른다.
+var registry = []Check{
+	{
+		Name:        "binaries",
+		Description: "git, gh, ssh 바이너리",
+		Run: func(ctx context.Context, env *Env, _ string) []DiagResult {
+			return CheckBinaries(ctx, env.Commander)
+		},
+	},
+	{
+		Name:        "config_file",
+		Description: "config.toml 권한과 유효성",
+		Run: func(ctx context.Context, env *Env, _ string) []DiagResult {
+			r := CheckConfigFile(env.ConfigPath, env.ConfigErr)
+			if r.Status == StatusWarn {
+				r.Remedy = ChmodRemedy(env.ConfigPath)
+			}
+			return []DiagResult{r}
+		},
+	},
+	{
+		Name:        "config_issues",
+		Description: "프로필 간 충돌과 누락 설정 (ctx config validate)",
+		Run: func(ctx context.Context, env *Env, _ string) []DiagResult {
+			return CheckConfigIssues(env.ConfigPath, env.SSHConfigPath, env.ConfigIgnored)
+		},
+	},
+	{
+		Name:        "cache_file",
+		Description: "cache.json 권한",
+		Run: func(ctx context.Context, env *Env, _ string) []DiagResult {
+			if env.CachePath == "" {
+				return nil
+			}
+			return withRemedy([]DiagResult{CheckCacheFile(env.CachePath)}, ChmodRemedy(env.CachePath))
+		},
+	},
+	{
+		Name:        "env_tokens",
+		Description: "GH_TOKEN/GITHUB_TOKEN 간섭",
+		Run: func(ctx context.Context, env *Env, _ string) []DiagResult {
+			return []DiagResult{CheckEnvTokens()}
+		},
+	},
+	{
+		Name:        "credential_helper",
+		Description: "github.com HTTPS credential helper 충돌",
+		Run: func(ctx context.Context, env *Env, _ string) []DiagResult {
+			return []DiagResult{CheckCredentialHelper(ctx, env.Commander)}
+		},
+	},
+	{
+		Name:        "shell_hook",
+		Description: "셸 hook 설치 상태",
+		Run: func(ctx context.Context, env *Env, _ string) []DiagResult {
+			return withRemedy([]DiagResult{CheckShellHook(env.ShellType, env.ShellRCPath)}, ShellHookRemedy(env.ShellType, env.ShellRCPath))
+		},
+	},
+	{
+		Name:        "includeif_local",
+		Description: "현재 리포의 로컬 설정과 includeIf 일치 (git_include_if 사용 시)",
+		Run: func(ctx context.Context, env *Env, _ string) []DiagResult {
+			if env.Config == nil || !env.Config.GitIncludeIf || env.RepoDir == "" {
+				return nil
+			}
+			return []DiagResult{CheckIncludeIfLocal(ctx, env.Commander, env.RepoDir, gitinclude.DefaultDir())}
+		},
+	},
+	{
+		Name:        "gh_auth",
+		Description: "프로필별 gh 인증 상태",
+		PerProfile:  true,
+		Run: func(ctx context.Context, env *Env, profile string) []DiagResult {
+			return []DiagResult{CheckGHAuth(ctx, env.Commander, env.Config.Profiles[profile].GHConfigDir)}
+		},
+	},
+	{
+		Name:        "token_expiry",
+		Description: "프로필별 gh 토큰 만료일",
+		PerProfile:  true,
+		Run: func(ctx context.Context, env *Env, profile string) []DiagResult {
+			return []DiagResult{CheckTokenExpiry(ctx, env.Commander, env.Config.Profiles[profile].GHConfigDir, env.now())}
+		},
+	},
+	{
+		Name:        "ssh",
+		Description: "프로필별 SSH 연결",
+		PerProfile:  true,
+		Run: func(ctx context.Context, env *Env, profile string) []DiagResult {
+			p := env.Config.Profiles[profile]
+			probe := env.sshProbe(ctx, p.SSHHost)
+			r := CheckSSHOutput(p.SSHHost, probe.out, probe.err)
+			// 키 등록은 GitHub이 키를 거부했을 때만 도움이 된다. 이름 해석이나 네트워크 실패에는 붙이지 않는다
+			if r.Status == StatusFail && strings.Contains(probe.out, sshPublicKeyDenied) {
+				r.Remedy = SSHKeyRegisterRemedy(env.Commander, p.GHConfigDir, env.identityFile(p), "ctx-"+profile)
+			}
+			return []DiagResult{r}
+		},
+	},
+	{
+		Name:        "ssh_account",
+		Description: "프로필별 SSH 인증 계정과 github_user(없으면 gh 로그인) 일치",
+		PerProfile:  true,
+		Run: func(ctx context.Context, env *Env, profile string) []DiagResult {
+			p := env.Config.Profiles[profile]
+			var ghLogin string
+			if p.GitHubUser == "" {
+				ghLogin = env.ghLogin(ctx, p.GHConfigDir)
+			}
+			return []DiagResult{CheckSSHAccount(p.SSHHost, env.sshLogin(ctx, p.SSHHost), ghLogin, p.GitHubUser)}
+		},
+	},
+	{
+		Name:        "gh_account",
+		Description: "프로필별 gh 로그인 계정과 github_user 일치",
+		PerProfile:  true,
+		Run: func(ctx context.Context, env *Env, profile string) []DiagResult {
+			p := env.Config.Profiles[profile]
+			if p.GitHubUser == "" {
+				return nil
+			}
+			return []DiagResult{CheckGHAccount(p.GHConfigDir, env.ghLogin(ctx, p.GHConfigDir), p.GitHubUser)}
+		},
+	},
+	{
+		Name:        "ssh_account_unique",
+		Description: "프로필 간 SSH 인증 계정 중복",
+		Aggregate:   true,
+		Run: func(ctx context.Context, env *Env, _ string) []DiagResult {
+			if env.Config == nil || len(env.Config.Profiles) < 2 {
+				return nil
+			}
+			// ssh/ssh_account 항목이 먼저 조회한 결과를 재사용한다. 단독 실행이면 프로필별로 동시에 조회한다.
+			var mu sync.Mutex
+			var wg sync.WaitGroup
+			logins := make(map[string]string, len(env.Config.Profiles))
+			for name, p := range env.Config.Profiles {
+				wg.Go(func() {
+					login := env.sshLogin(ctx, p.SSHHost)
+					mu.Lock()
+					logins[name] = login
+					mu.Unlock()
+				})
+			}
+			wg.Wait()
+			return []DiagResult{CheckSSHAccountsUnique(logins)}
+		},
+	},
+	{
+		Name:        "identities_only",
+		Description: "프로필별 SSH IdentitiesOnly 설정",
+		PerProfile:  true,
+		Run: func(ctx context.Context, env *Env, profile string) []DiagResult {
+			host := env.Config.Profiles[profile].SSHHost
+			r := CheckIdentitiesOnly(ctx, env.Commander, host)
+			if r.Status == StatusFail {
+				r.Remedy = IdentitiesOnlyRemedy(env.SSHConfigPath, host)
+			}
+			return []DiagResult{r}
+		},
+	},
+	{
+		Name:        "includeif",
+		Description: "프로필별 includeIf gitconfig (git_include_if 사용 시)",
+		PerProfile:  true,
+		Run: func(ctx context.Context, env *Env, profile string) []DiagResult {
+			if !env.Config.GitIncludeIf {
+				return nil
+			}
+			return []DiagResult{CheckIncludeIf(profile, env.Config.Profiles[profile], gitinclude.DefaultGlobalPath(), gitinclude.DefaultDir())}
+		},
+	},
+}
+
+// Checks는 등록된 모든 진단 항목을 실행 순서대로 반환한다. 리포 진단 항목이 마지막에 온다.
+func Checks() []Check {
+	return append(append([]Check(nil), registry...), repoRegistry...)
+}
+
+// Select는 이름으로 진단 항목을 고른다. names가 비어있으면 전체를 반환한다.
+// 알 수 없는 이름이 있으면 사용 가능한 이름 목록과 함께 에러를 반환한다.
+func Select(names []string) ([]Check, error) {
+	if len(names) == 0 {
+		return Checks(), nil
+	}
+	want := make(map[string]bool, len(names))
+	for _, n := range names {
+		want[n] = true
+	}
+	var selected []Check
+	all := Checks()
+	for _, c := range all {
+		if want[c.Name] {
+			selected = append(selected, c)
+			delete(want, c.Name)
+		}
+	}
+	for n := range want {
+		available := make([]string, 0, len(all))
+		for _, c := range all {
+			available = append(available, c.Name)
+		}
+		return nil, fmt.Errorf("doctor.Select: 알 수 없는 진단 항목 %q (사용 가능: %s)", n, strings.Join(available, ", "))
+	}
+	return selected, nil
+}
+
+// CheckIdentitiesOnly는 ssh -G로 계산한 Host 설정에 IdentitiesOnly yes가 적용되는지 확인한다.
+// 미설정 시 ssh-agent의 다른 키가 먼저 제시되어 엉뚱한 계정으로 인증될 수 있다.
+func CheckIdentitiesOnly(ctx context.Context, cmd cmdexec.Commander, sshHost string) DiagResult {
+	name := fmt.Sprintf("identities_only_%s", sshHost)
+	out, err := cmd.Run(ctx, "ssh", "-G", sshHost)
+	if err != nil {
+		return DiagResult{
+			Name:    name,
+			Status:  StatusWarn,
+			Message: fmt.Sprintf("ssh -G %s 실행 실패 — 확인 불가", sshHost),
+			Fix:     fmt.Sprintf("ssh -G %s 로 SSH 설정 확인", sshHost),
+		}
+	}
+
+	for _, line := range strings.Split(string(out), "\n") {
+		key, value, ok := strings.Cut(strings.TrimSpace(line), " ")
+		if ok && strings.EqualFold(key, "identitiesonly") && strings.EqualFold(strings.TrimSpace(value), "yes") {
+			return DiagResult{
+				Name:    name,
+				Status:  StatusOK,
+				Message: fmt.Sprintf("%s IdentitiesOnly 설정됨", sshHost),
+			}
+		}
+	}
+	return DiagResult{
+		Name:    name,
+		Status:  StatusFail,
+		Message: fmt.Sprintf("%s IdentitiesOnly 미설정 — 다중 키 혼선 위험", sshHost),
+		Fix:     fmt.Sprintf("~/.ssh/config의 Host %s 블록에 IdentitiesOnly yes 추가", sshHost),
+	}
+}
+
+// CheckCredentialHelper는 github.com HTTPS에 계정 구분 없는 credential helper가 적용되는지 확인한다.
+// 빈 helper 값은 그 이전 목록을 초기화한다는 git 규칙을 따른다.
+func CheckCredentialHelper(ctx context.Context, cmd cmdexec.Commander) DiagResult {
+	out, err := cmd.Run(ctx, "git", "config", "--get-regexp", `^credential\..*helper$`)
+	if err != nil {
+		// 일치하는 키가 없으면 git config가 exit 1을 반환한다.
+		return DiagResult{
+			Name:    "credential_helper",
+			Status:  StatusOK,
+			Message: "credential helper 미설정",
+		}
+	}
+
+	var helpers []string
+	for _, line := range strings.Split(strings.TrimSpace(string(out)), "\n") {
+		key, value, _ := strings.Cut(strings.TrimSpace(line), " ")
+		if !credentialKeyAppliesToGitHub(key) {
+			continue
+		}
+		value = strings.TrimSpace(value)
+		if value == "" {
+			helpers = nil
+			continue
+		}
+		helpers = append(helpers, value)
+	}
+
+	var conflicts []string
+	for _, h := range helpers {
+		fields := strings.Fields(strings.TrimPrefix(h, "!"))
+		if len(fields) == 0 {
+			continue
+		}
+		base := strings.TrimPrefix(filepath.Base(fields[0]), "git-credential-")
+		if conflictingHelpers[base] {
+			conflicts = append(conflicts, h)
+		}
+	}
+	if len(conflicts) > 0 {
+		return DiagResult{
+			Name:    "credential_helper",
+			Status:  StatusFail,
+			Message: fmt.Sprintf("github.com에 계정 공유 credential helper 적용됨: %s", strings.Join(conflicts, ", ")),
+			Fix:     `git config --global credential.https://github.com.helper '' && git config --global --add credential.https://github.com.helper '!gh auth git-credential'`,
+		}
+	}
+	return DiagResult{
+		Name:    "credential_helper",
+		Status:  StatusOK,
+		Message: "credential helper 충돌 없음",
+	}
+}
+
+// credentialKeyAppliesToGitHub는 credential.helper 또는 credential.<url>.helper 키가
+// github.com에 적용되는지 판단한다.
+func credentialKeyAppliesToGitHub(key string) bool {
+	key = strings.TrimPrefix(key, "credential.")
+	url, ok := strings.CutSuffix(key, "helper")
+	if !ok {
+		return false
+	}
+	url = strings.TrimSuffix(url, ".")
+	if url == "" {
+		return true
+	}
+	if _, rest, ok := strings.Cut(url, "://"); ok {
+		url = rest
+	}
+	host, _, _ := strings.Cut(url, "/")
+	return strings.EqualFold(host, "github.com")
+}
+
+// CheckConfigFile은 config.toml의 존재, 파싱 결과(loadErr), 파일 권한을 확인한다.
+func CheckConfigFile(path string, loadErr error) DiagResult {
+	if _, err := os.Stat(path); err != nil {
+		return DiagResult{
+			Name:    "config_file",
+			Status:  StatusFail,
+			Message: fmt.Sprintf("설정 파일 없음: %s", path),
+			Fix:     "ctx setup 실행",
+		}
+	}
+	if loadErr != nil {
+		return DiagResult{
+			Name:    "config_file",
+			Status:  StatusFail,
+			Message: loadErr.Error(),
+			Fix:     fmt.Sprintf("%s 수정 또는 ctx setup --force로 재생성", path),
+		}
+	}
+	if err := config.ValidateFilePermissions(path); err != nil {
+		return DiagResult{
+			Name:    "config_file",
+			Status:  StatusWarn,
+			Message: err.Error(),
+			Fix:     fmt.Sprintf("chmod 600 %s", path),
+		}
+	}
+	return DiagResult{
+		Name:    "config_file",
+		Status:  StatusOK,
+		Message: fmt.Sprintf("설정 파일 유효: %s", path),
+	}
+}
+
+// CheckConfigIssues는 설정의 프로필 간 충돌과 누락을 검사한다. 문제마다 결과 하나를 반환한다.
+// ignored(정책 잠금으로 무시된 사용자 값)도 경고로 함께 보고한다.
+// 파싱할 수 없는 설정은 config_file 항목이 보고하므로 건너뛴다.
+func CheckConfigIssues(path, sshConfigPath string, ignored []config.Issue) []DiagResult {
+	cfg, err := config.Decode(path)
+	if err != nil {
+		return nil
+	}
+	var hasSSHHost func(string) bool
+	if sshConfigPath != "" {
+		if sshCfg, err := sshconfig.Load(sshConfigPath); err == nil {
+			hasSSHHost = sshCfg.HasHost
+		}
+	}
+
+	issues := append(cfg.Validate(hasSSHHost), ignored...)
+	if len(issues) == 0 {
+		return []DiagResult{{
+			Name:    "config_issues",
+			Status:  StatusOK,
+			Message: "프로필 간 충돌 없음",
+		}}
+	}
+	results := make([]DiagResult, 0, len(issues))
+	for _, i := range issues {
+		status := StatusWarn
+		if i.Severity == config.SeverityError {
+			status = StatusFail
+		}
+		results = append(results, DiagResult{
+			Name:    i.Field,
+			Status:  status,
+			Message: i.Message,
+			Fix:     fmt.Sprintf("%s의 %s 수정 후 ctx config validate", path, i.Field),
+		})
+	}
+	return results
+}
+
+// CheckCacheFile은 캐시 파일 권한이 0600인지 확인한다. 캐시가 없으면 정상이다.
+func CheckCacheFile(path string) DiagResult {
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		return DiagResult{
+			Name:    "cache_file",
+			Status:  StatusOK,
+			Message: "캐시 없음",
+		}
+	}
+	if _, err := cache.Load(path); errors.Is(err, cache.ErrUnsupportedVersion) {
+		return DiagResult{
+			Name:    "cache_file",
+			Status:  StatusFail,
+			Message: err.Error() + " — 캐시를 읽지도 갱신하지도 않음",
+			Fix:     fmt.Sprintf("ctx 업데이트 또는 %s 삭제", path),
+		}
+	}
+	if err := config.ValidateFilePermissions(path); err != nil {
+		return DiagResult{
+			Name:    "cache_file",
+			Status:  StatusWarn,
+			Message: err.Error(),
+			Fix:     fmt.Sprintf("chmod 600 %s", path),
+		}
+	}
+	return DiagResult{
+		Name:    "cache_file",
+		Status:  StatusOK,
+		Message: fmt.Sprintf("캐시 파일 권한 정상: %s", path),
+	}
+}
+
+// CheckShellHook은 셸 RC 파일에 ctx hook이 설치되어 있는지 확인한다.
+func CheckShellHook(shellType, rcPath string) DiagResult {
+	if rcPath == "" {
+		return DiagResult{
+			Name:    "shell_hook",
+			Status:  StatusWarn,
+			Message: fmt.Sprintf("지원하지 않는 셸: %q — 셸 hook 확인 불가", shellType),
+			Fix:     "zsh, bash, fish 중 하나에서 ctx doctor --check shell_hook --fix 실행",
+		}
+	}
+	data, _ := os.ReadFile(rcPath) // 파일이 없으면 미설치로 판단
+	if strings.Contains(string(data), shell.HookMarker) {
+		return DiagResult{
+			Name:    "shell_hook",
+			Status:  StatusOK,
+			Message: fmt.Sprintf("셸 hook 설치됨: %s", rcPath),
+		}
+	}
+	return DiagResult{
+		Name:    "shell_hook",
+		Status:  StatusFail,
+		Message: fmt.Sprintf("%s에 셸 hook 없음 — 디렉토리 이동 시 GH_CONFIG_DIR이 전환되지 않음", rcPath),
+		Fix:     fmt.Sprintf("ctx doctor --check shell_hook --fix (또는 %s에 eval \"$(ctx activate --shell %s)\" hook 추가)", rcPath, shellType),
+	}
+}
+
+// CheckTokenExpiry는 gh API 응답의 토큰 만료 헤더로 만료 임박 여부를 확인한다.
+// fine-grained/classic PAT처럼 만료일이 있는 토큰에만 헤더가 포함된다.
+func CheckTokenExpiry(ctx context.Context, cmd cmdexec.Commander, ghConfigDir string, now time.Time) DiagResult {
+	env := gh.SuppressEnvTokens()
+	env["GH_CONFIG_DIR"] = ghConfigDir
+	refreshFix := fmt.Sprintf("GH_CONFIG_DIR=%s gh auth login 으로 새 토큰 발급", ghConfigDir)
+
+	out, err := cmd.RunWithEnv(ctx, env, "gh", "api", "-i", "user")
+	if err != nil {
+		return DiagResult{
+			Name:    "token_expiry",
+			Status:  StatusWarn,
+			Message: "토큰 만료일 확인 실패",
+			Fix:     fmt.Sprintf("GH_CONFIG_DIR=%s gh auth status 로 인증 상태 확인", ghConfigDir),
+		}
+	}
+
+	expiry, ok := parseTokenExpiration(string(out))
+	if !ok {
+		return DiagResult{
+			Name:    "token_expiry",
+			Status:  StatusOK,
+			Message: "토큰 만료일 없음",
+		}
+	}
+
+	remaining := expiry.Sub(now)
+	switch {
+	case remaining <= 0:
+		return DiagResult{
+			Name:    "token_expiry",
+			Status:  StatusFail,
+			Message: fmt.Sprintf("토큰 만료됨 (%s)", expiry.Format("2006-01-02")),
+			Fix:     refreshFix,
+		}
+	case remaining < tokenExpiryWarnDays*24*time.Hour:
+		return DiagResult{
+			Name:    "token_expiry",
+			Status:  StatusWarn,
+			Message: fmt.Sprintf("토큰 만료 임박: %s (%d일 남음)", expiry.Format("2006-01-02"), int(remaining.Hours()/24)),
+			Fix:     refreshFix,
+		}
+	default:
+		return DiagResult{
+			Name:    "token_expiry",
+			Status:  StatusOK,
+			Message: fmt.Sprintf("토큰 만료일: %s", expiry.Format("2006-01-02")),
+		}
+	}
+}
+
+// parseTokenExpiration은 gh api -i 출력에서 github-authentication-token-expiration 헤더를 파싱한다.
+func parseTokenExpiration(output string) (time.Time, bool) {
+	for _, line := range strings.Split(output, "\n") {
+		key, value, ok := strings.Cut(strings.TrimSpace(line), ":")
+		if !ok || !strings.EqualFold(key, "github-authentication-token-expiration") {
+			continue
+		}
+		value = strings.TrimSpace(value)
+		for _, layout := range []string{"2006-01-02 15:04:05 MST", "2006-01-02 15:04:05 -0700"} {
+			if t, err := time.Parse(layout, value); err == nil {
+				return t, true
+			}
+		}
+	}
+	return time.Time{}, false
+}
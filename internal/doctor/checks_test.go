@@ -0,0 +1,193 @@
+package doctor_test
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/hbjs97/ctx/internal/config"
+	"github.com/hbjs97/ctx/internal/doctor"
+	"github.com/hbjs97/ctx/internal/shell"
+	"github.com/hbjs97/ctx/internal/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSelect(t *testing.T) {
+	all, err := doctor.Select(nil)
+	require.NoError(t, err)
+	assert.Len(t, all, len(doctor.Checks()))
+
+	selected, err := doctor.Select([]string{"identities_only", "binaries"})
+	require.NoError(t, err)
+	require.Len(t, selected, 2)
+	assert.Equal(t, "binaries", selected[0].Name, "등록 순서를 따른다")
+	assert.Equal(t, "identities_only", selected[1].Name)
+
+	_, err = doctor.Select([]string{"nope"})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "token_expiry")
+}
+
+func TestChecks_AllHaveFixOnFailure(t *testing.T) {
+	dir := t.TempDir()
+	fake := testutil.NewFakeCommander()
+	fake.DefaultResponse = &testutil.Response{Err: fmt.Errorf("fail")}
+	fake.Register("git config --get-regexp", "credential.helper osxkeychain\n", nil)
+	fake.Register("ssh -G", "user git\nidentitiesonly no\n", nil)
+
+	env := &doctor.Env{
+		Commander: fake,
+		Config: &config.Config{Profiles: map[string]config.Profile{
+			"work": {GHConfigDir: "/tmp/gh-work", SSHHost: "github.com-work"},
+		}},
+		ConfigPath:  filepath.Join(dir, "config.toml"),
+		ShellType:   "zsh",
+		ShellRCPath: filepath.Join(dir, ".zshrc"),
+	}
+	for _, c := range doctor.Checks() {
+		profile := ""
+		if c.PerProfile {
+			profile = "work"
+		}
+		for _, r := range c.Run(context.Background(), env, profile) {
+			if r.Status != doctor.StatusOK {
+				assert.NotEmpty(t, r.Fix, "%s(%s) 결과에 Fix가 없다", c.Name, r.Name)
+			}
+		}
+	}
+}
+
+func TestCheckIdentitiesOnly(t *testing.T) {
+	fake := testutil.NewFakeCommander()
+	fake.Register("ssh -G github.com-work", "hostname github.com\nidentitiesonly yes\n", nil)
+	fake.Register("ssh -G github.com-loose", "hostname github.com\nidentitiesonly no\n", nil)
+
+	assert.Equal(t, doctor.StatusOK, doctor.CheckIdentitiesOnly(context.Background(), fake, "github.com-work").Status)
+
+	result := doctor.CheckIdentitiesOnly(context.Background(), fake, "github.com-loose")
+	assert.Equal(t, doctor.StatusFail, result.Status)
+	assert.Contains(t, result.Fix, "IdentitiesOnly yes")
+}
+
+func TestCheckCredentialHelper(t *testing.T) {
+	tests := []struct {
+		name   string
+		output string
+		err    error
+		want   doctor.Status
+	}{
+		{name: "none", err: fmt.Errorf("exit 1"), want: doctor.StatusOK},
+		{name: "osxkeychain global", output: "credential.helper osxkeychain\n", want: doctor.StatusFail},
+		{name: "manager for github", output: "credential.https://github.com.helper /usr/local/bin/git-credential-manager\n", want: doctor.StatusFail},
+		{name: "gh helper", output: "credential.https://github.com.helper !/usr/bin/gh auth git-credential\n", want: doctor.StatusOK},
+		{
+			name:   "reset then gh",
+			output: "credential.helper osxkeychain\ncredential.https://github.com.helper \ncredential.https://github.com.helper !gh auth git-credential\n",
+			want:   doctor.StatusOK,
+		},
+		{name: "other host", output: "credential.https://gitlab.com.helper store\n", want: doctor.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fake := testutil.NewFakeCommander()
+			fake.Register("git config --get-regexp", tt.output, tt.err)
+			result := doctor.CheckCredentialHelper(context.Background(), fake)
+			assert.Equal(t, tt.want, result.Status, result.Message)
+		})
+	}
+}
+
+func TestCheckConfigFile(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "config.toml")
+
+	assert.Equal(t, doctor.StatusFail, doctor.CheckConfigFile(path, nil).Status, "파일 없음")
+
+	require.NoError(t, os.WriteFile(path, []byte("version = 1\n"), 0600))
+	result := doctor.CheckConfigFile(path, fmt.Errorf("프로필이 정의되지 않았습니다"))
+	assert.Equal(t, doctor.StatusFail, result.Status)
+	assert.Contains(t, result.Message, "프로필")
+
+	assert.Equal(t, doctor.StatusOK, doctor.CheckConfigFile(path, nil).Status)
+
+	require.NoError(t, os.Chmod(path, 0644))
+	result = doctor.CheckConfigFile(path, nil)
+	assert.Equal(t, doctor.StatusWarn, result.Status)
+	assert.Equal(t, "chmod 600 "+path, result.Fix)
+}
+
+func TestCheckShellHook(t *testing.T) {
+	dir := t.TempDir()
+	rcPath := filepath.Join(dir, ".zshrc")
+
+	missing := doctor.CheckShellHook("zsh", rcPath)
+	assert.Equal(t, doctor.StatusFail, missing.Status)
+	assert.Contains(t, missing.Fix, "ctx doctor --check shell_hook --fix", "setup은 기존 설정에서 hook을 설치하지 않는다")
+	assert.Contains(t, missing.Fix, "ctx activate --shell zsh")
+
+	require.NoError(t, os.WriteFile(rcPath, []byte("export A=1\n"+shell.HookSnippet("zsh")), 0600))
+	assert.Equal(t, doctor.StatusOK, doctor.CheckShellHook("zsh", rcPath).Status)
+
+	assert.Equal(t, doctor.StatusWarn, doctor.CheckShellHook("tcsh", "").Status)
+}
+
+func TestCheckTokenExpiry(t *testing.T) {
+	now := time.Date(2026, 10, 18, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name   string
+		header string
+		want   doctor.Status
+	}{
+		{name: "no expiry", header: "", want: doctor.StatusOK},
+		{name: "far", header: "Github-Authentication-Token-Expiration: 2027-01-01 00:00:00 UTC\r\n", want: doctor.StatusOK},
+		{name: "soon", header: "github-authentication-token-expiration: 2026-10-21 09:00:00 +0900\r\n", want: doctor.StatusWarn},
+		{name: "expired", header: "Github-Authentication-Token-Expiration: 2026-10-01 00:00:00 UTC\r\n", want: doctor.StatusFail},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fake := testutil.NewFakeCommander()
+			fake.Register("gh api -i user", "HTTP/2.0 200 OK\r\n"+tt.header+"Content-Type: application/json\r\n\r\n{\"login\":\"me\"}", nil)
+			result := doctor.CheckTokenExpiry(context.Background(), fake, "/tmp/gh-work", now)
+			assert.Equal(t, tt.want, result.Status, result.Message)
+		})
+	}
+}
+
+func TestCheckConfigIssues(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "config.toml")
+	sshPath := filepath.Join(dir, "ssh_config")
+	require.NoError(t, os.WriteFile(sshPath, []byte("Host github.com-work\n  HostName github.com\n"), 0600))
+	require.NoError(t, os.WriteFile(path, []byte(`version = 1
+[profiles.work]
+gh_config_dir = "/gh/work"
+ssh_host = "github.com-work"
+git_name = "Work"
+git_email = "w@acme.com"
+
+[profiles.personal]
+gh_config_dir = "/gh/work"
+ssh_host = "github.com-personal"
+git_name = "Me"
+git_email = "me@example.com"
+`), 0600))
+
+	results := doctor.CheckConfigIssues(path, sshPath, nil)
+	require.Len(t, results, 2)
+	assert.Equal(t, "profiles.personal.gh_config_dir", results[0].Name)
+	assert.Equal(t, doctor.StatusFail, results[0].Status)
+	assert.Equal(t, "profiles.personal.ssh_host", results[1].Name)
+	assert.Equal(t, doctor.StatusWarn, results[1].Status, "SSH config에 없는 Host")
+
+	assert.Nil(t, doctor.CheckConfigIssues(filepath.Join(dir, "missing.toml"), sshPath, nil))
+
+	ignored := []config.Issue{{Severity: config.SeverityWarning, Field: "profiles.work.guard.user_email", Message: "팀 정책으로 무시"}}
+	results = doctor.CheckConfigIssues(path, sshPath, ignored)
+	require.Len(t, results, 3)
+	assert.Equal(t, "profiles.work.guard.user_email", results[2].Name)
+	assert.Equal(t, doctor.StatusWarn, results[2].Status, "정책 잠금으로 무시된 값도 알린다")
+}
@@ -6,7 +6,9 @@ import (
 	"strings"
 
 	"github.com/hbjs97/ctx/internal/cmdexec"
+	"github.com/hbjs97/ctx/internal/config"
 	"github.com/hbjs97/ctx/internal/gh"
+	"github.com/hbjs97/ctx/internal/gitinclude"
 )
 
 // Status는 진단 결과 상태다.
@@ -27,6 +29,14 @@ type DiagResult struct {
 	Status  Status
 	Message string
 	Fix     string
+	// Remedy는 ctx doctor --fix로 실행할 수 있는 자동 조치다. 없으면 nil.
+	Remedy *Remedy
+}
+
+// Remedy는 진단 결과를 자동으로 바로잡는 조치다.
+type Remedy struct {
+	Description string
+	Apply       func(ctx context.Context) error
 }
 
 // CheckBinaries는 필수 바이너리(git, gh, ssh) 존재 여부를 확인한다.
@@ -63,10 +73,21 @@ func CheckBinaries(ctx context.Context, cmd cmdexec.Commander) []DiagResult {
 }
 
 // CheckGHAuth는 gh CLI 인증 상태를 확인한다.
+// 로그인은 되어 있고 토큰 scope만 부족하면 gh auth refresh 조치를 붙인다.
 func CheckGHAuth(ctx context.Context, cmd cmdexec.Commander, ghConfigDir string) DiagResult {
 	env := gh.SuppressEnvTokens()
 	env["GH_CONFIG_DIR"] = ghConfigDir
-	_, err := cmd.RunWithEnv(ctx, env, "gh", "auth", "status")
+	out, err := cmd.RunWithEnv(ctx, env, "gh", "auth", "status")
+	if strings.Contains(string(out), ghMissingScopes) {
+		// 로그인은 되어 있으므로 gh auth refresh로 scope만 다시 받으면 된다
+		return DiagResult{
+			Name:    "gh_auth",
+			Status:  StatusFail,
+			Message: "gh CLI 인증됨, 필요한 토큰 scope 없음",
+			Fix:     "gh auth refresh 실행",
+			Remedy:  GHAuthRefreshRemedy(cmd, ghConfigDir),
+		}
+	}
 	if err != nil {
 		return DiagResult{
 			Name:    "gh_auth",
@@ -82,10 +103,21 @@ func CheckGHAuth(ctx context.Context, cmd cmdexec.Commander, ghConfigDir string)
 	}
 }
 
+const (
+	// ghMissingScopes는 로그인은 되어 있지만 토큰 scope가 부족할 때 gh auth status가 출력하는 문구다.
+	ghMissingScopes = "Missing required token scopes"
+	// sshPublicKeyDenied는 GitHub이 SSH 키를 받아들이지 않을 때 ssh가 출력하는 문구다.
+	sshPublicKeyDenied = "Permission denied (publickey)"
+)
+
 // CheckSSH는 SSH 연결을 확인한다.
 func CheckSSH(ctx context.Context, cmd cmdexec.Commander, sshHost string) DiagResult {
 	out, err := cmd.Run(ctx, "ssh", "-T", fmt.Sprintf("git@%s", sshHost))
-	outStr := string(out)
+	return CheckSSHOutput(sshHost, string(out), err)
+}
+
+// CheckSSHOutput은 ssh -T git@<sshHost>의 출력과 오류로 SSH 연결 결과를 만든다.
+func CheckSSHOutput(sshHost, outStr string, err error) DiagResult {
 	if err != nil {
 		// GitHub은 ssh -T 시 항상 exit code 1을 반환한다.
 		// "successfully authenticated" 메시지가 출력에 포함되면 실제로는 성공이다.
@@ -96,11 +128,19 @@ func CheckSSH(ctx context.Context, cmd cmdexec.Commander, sshHost string) DiagRe
 				Message: fmt.Sprintf("SSH %s 연결 성공", sshHost),
 			}
 		}
+		if strings.Contains(outStr, sshPublicKeyDenied) {
+			return DiagResult{
+				Name:    fmt.Sprintf("ssh_%s", sshHost),
+				Status:  StatusFail,
+				Message: fmt.Sprintf("SSH %s 키 인증 거부 (%s)", sshHost, sshPublicKeyDenied),
+				Fix:     "키 미등록 시 gh ssh-key add <공개키>.pub 실행",
+			}
+		}
 		return DiagResult{
 			Name:    fmt.Sprintf("ssh_%s", sshHost),
 			Status:  StatusFail,
 			Message: fmt.Sprintf("SSH %s 연결 실패", sshHost),
-			Fix:     fmt.Sprintf("ssh -T git@%s 로 연결 확인. 키 미등록 시 gh ssh-key add <공개키>.pub 실행", sshHost),
+			Fix:     fmt.Sprintf("ssh -T git@%s 로 연결 확인. SSH config의 Host %s와 네트워크 확인", sshHost, sshHost),
 		}
 	}
 	return DiagResult{
@@ -128,12 +168,92 @@ func CheckEnvTokens() DiagResult {
 	}
 }
 
-// RunAll은 모든 진단을 실행한다.
-func RunAll(ctx context.Context, cmd cmdexec.Commander, ghConfigDir, sshHost string) []DiagResult {
-	var results []DiagResult
-	results = append(results, CheckBinaries(ctx, cmd)...)
-	results = append(results, CheckGHAuth(ctx, cmd, ghConfigDir))
-	results = append(results, CheckSSH(ctx, cmd, sshHost))
-	results = append(results, CheckEnvTokens())
-	return results
+// CheckIncludeIf는 프로필의 includeIf용 gitconfig 파일이 현재 프로필 설정과 일치하고
+// 전역 git config에서 참조되는지 확인한다.
+func CheckIncludeIf(profileName string, p config.Profile, globalPath, dir string) DiagResult {
+	name := fmt.Sprintf("includeif_%s", profileName)
+	path := gitinclude.ProfilePath(dir, profileName)
+	fix := "ctx setup --include-if 재실행으로 includeIf 설정 재생성"
+
+	gitName, gitEmail := gitinclude.ReadIdentity(path)
+	if gitName == "" && gitEmail == "" {
+		return DiagResult{
+			Name:    name,
+			Status:  StatusWarn,
+			Message: fmt.Sprintf("%s 없음", path),
+			Fix:     fix,
+		}
+	}
+	if gitEmail != p.GitEmail || gitName != p.GitName {
+		return DiagResult{
+			Name:    name,
+			Status:  StatusWarn,
+			Message: fmt.Sprintf("includeIf identity 불일치: %s <%s> (프로필: %s <%s>)", gitName, gitEmail, p.GitName, p.GitEmail),
+			Fix:     fix,
+		}
+	}
+
+	referenced := false
+	for _, inc := range gitinclude.ParseIncludes(globalPath) {
+		if inc.Path == path {
+			referenced = true
+			break
+		}
+	}
+	if !referenced && (len(p.Owners) > 0 || len(p.GitDirs) > 0) {
+		return DiagResult{
+			Name:    name,
+			Status:  StatusWarn,
+			Message: fmt.Sprintf("%s에 %s includeIf 항목 없음", globalPath, path),
+			Fix:     fix,
+		}
+	}
+	return DiagResult{
+		Name:    name,
+		Status:  StatusOK,
+		Message: "includeIf 설정이 프로필과 일치",
+	}
+}
+
+// CheckIncludeIfLocal은 리포의 로컬 user.email과 includeIf로 적용되는 user.email이 다르면 경고한다.
+// includeDir는 ctx가 생성한 프로필별 gitconfig 디렉토리다.
+func CheckIncludeIfLocal(ctx context.Context, cmd cmdexec.Commander, repoDir, includeDir string) DiagResult {
+	out, err := cmd.Run(ctx, "git", "-C", repoDir, "config", "--show-scope", "--show-origin", "--get-all", "user.email")
+	if err != nil {
+		return DiagResult{
+			Name:    "includeif_local",
+			Status:  StatusOK,
+			Message: "리포 밖이거나 user.email 미설정 — 건너뜀",
+		}
+	}
+
+	var localEmail, includeEmail string
+	for _, line := range strings.Split(strings.TrimSpace(string(out)), "\n") {
+		// scope\torigin\tvalue. 워크트리와 분리된 git 디렉토리도 scope로 로컬 설정을 구분한다.
+		fields := strings.SplitN(line, "\t", 3)
+		if len(fields) != 3 {
+			continue
+		}
+		scope, origin, value := fields[0], strings.TrimPrefix(fields[1], "file:"), fields[2]
+		switch {
+		case scope == "local" || scope == "worktree":
+			localEmail = strings.TrimSpace(value)
+		case includeDir != "" && strings.HasPrefix(origin, includeDir):
+			includeEmail = strings.TrimSpace(value)
+		}
+	}
+
+	if localEmail != "" && includeEmail != "" && localEmail != includeEmail {
+		return DiagResult{
+			Name:    "includeif_local",
+			Status:  StatusWarn,
+			Message: fmt.Sprintf("로컬 user.email(%s)과 includeIf user.email(%s) 불일치", localEmail, includeEmail),
+			Fix:     "ctx init 으로 리포 프로필 재적용 또는 프로필 owners/git_dirs 규칙 확인",
+		}
+	}
+	return DiagResult{
+		Name:    "includeif_local",
+		Status:  StatusOK,
+		Message: "로컬 설정과 includeIf 설정 일치",
+	}
 }
@@ -3,9 +3,12 @@ package doctor_test
 import (
 	"context"
 	"fmt"
+	"path/filepath"
 	"testing"
 
+	"github.com/hbjs97/ctx/internal/config"
 	"github.com/hbjs97/ctx/internal/doctor"
+	"github.com/hbjs97/ctx/internal/gitinclude"
 	"github.com/hbjs97/ctx/internal/testutil"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -57,6 +60,18 @@ func TestCheckGHAuth_NotAuthenticated(t *testing.T) {
 	result := doctor.CheckGHAuth(context.Background(), fake, "/tmp/gh-config")
 	assert.Equal(t, doctor.StatusFail, result.Status)
 	assert.Contains(t, result.Fix, "gh auth login")
+	assert.Nil(t, result.Remedy, "로그인이 안 되어 있으면 refresh로 고칠 수 없다")
+}
+
+func TestCheckGHAuth_MissingScopes(t *testing.T) {
+	fake := testutil.NewFakeCommander()
+	fake.Register("gh auth status", "github.com\n  ✓ Logged in to github.com account hbjs97\n  ! Missing required token scopes: 'read:org'\n", fmt.Errorf("exit status 1"))
+
+	result := doctor.CheckGHAuth(context.Background(), fake, "/tmp/gh-config")
+	assert.Equal(t, doctor.StatusFail, result.Status)
+	assert.Contains(t, result.Fix, "gh auth refresh")
+	require.NotNil(t, result.Remedy)
+	assert.Contains(t, result.Remedy.Description, "gh auth refresh")
 }
 
 func TestCheckSSHConnection_Success(t *testing.T) {
@@ -84,6 +99,33 @@ func TestCheckSSHConnection_Failure(t *testing.T) {
 
 	result := doctor.CheckSSH(context.Background(), fake, "github-work")
 	assert.Equal(t, doctor.StatusFail, result.Status)
+	assert.NotContains(t, result.Fix, "gh ssh-key add", "연결 실패는 키 등록으로 고칠 수 없다")
+}
+
+func TestChecks_SSH_RemedyOnlyForPublicKeyDenied(t *testing.T) {
+	fake := testutil.NewFakeCommander()
+	fake.Register("ssh -T git@github.com-work", "git@github.com: Permission denied (publickey).", fmt.Errorf("exit status 255"))
+	fake.Register("ssh -T git@github.com-typo", "ssh: Could not resolve hostname github.com-typo: nodename nor servname provided", fmt.Errorf("exit status 255"))
+
+	env := &doctor.Env{
+		Commander: fake,
+		Config: &config.Config{Profiles: map[string]config.Profile{
+			"work": {GHConfigDir: "/tmp/gh-work", SSHHost: "github.com-work", IdentityFile: "~/.ssh/id_work"},
+			"typo": {GHConfigDir: "/tmp/gh-typo", SSHHost: "github.com-typo", IdentityFile: "~/.ssh/id_typo"},
+		}},
+	}
+	checks, err := doctor.Select([]string{"ssh"})
+	require.NoError(t, err)
+
+	denied := checks[0].Run(context.Background(), env, "work")
+	require.Len(t, denied, 1)
+	assert.Equal(t, doctor.StatusFail, denied[0].Status)
+	assert.NotNil(t, denied[0].Remedy, "키가 거부되면 등록 조치를 제안한다")
+
+	unresolved := checks[0].Run(context.Background(), env, "typo")
+	require.Len(t, unresolved, 1)
+	assert.Equal(t, doctor.StatusFail, unresolved[0].Status)
+	assert.Nil(t, unresolved[0].Remedy, "호스트 이름 실패에는 키 등록 조치를 붙이지 않는다")
 }
 
 func TestCheckEnvTokens_None(t *testing.T) {
@@ -102,16 +144,65 @@ func TestCheckEnvTokens_Set(t *testing.T) {
 	assert.Contains(t, result.Message, "GH_TOKEN")
 }
 
-func TestRunAll(t *testing.T) {
+func TestCheckIncludeIf_Consistent(t *testing.T) {
+	dir := t.TempDir()
+	globalPath := filepath.Join(dir, ".gitconfig")
+	includeDir := filepath.Join(dir, "git")
+	cfg := &config.Config{Profiles: map[string]config.Profile{
+		"work": {SSHHost: "github.com-work", GitName: "Work", GitEmail: "w@acme.com", Owners: []string{"acme"}},
+	}}
+	require.NoError(t, gitinclude.Sync(globalPath, includeDir, cfg, nil))
+
+	result := doctor.CheckIncludeIf("work", cfg.Profiles["work"], globalPath, includeDir)
+	assert.Equal(t, doctor.StatusOK, result.Status)
+}
+
+func TestCheckIncludeIf_Stale(t *testing.T) {
+	dir := t.TempDir()
+	globalPath := filepath.Join(dir, ".gitconfig")
+	includeDir := filepath.Join(dir, "git")
+	cfg := &config.Config{Profiles: map[string]config.Profile{
+		"work": {SSHHost: "github.com-work", GitName: "Work", GitEmail: "w@acme.com", Owners: []string{"acme"}},
+	}}
+	require.NoError(t, gitinclude.Sync(globalPath, includeDir, cfg, nil))
+
+	changed := cfg.Profiles["work"]
+	changed.GitEmail = "new@acme.com"
+	result := doctor.CheckIncludeIf("work", changed, globalPath, includeDir)
+	assert.Equal(t, doctor.StatusWarn, result.Status)
+	assert.Contains(t, result.Fix, "--include-if")
+}
+
+func TestCheckIncludeIf_Missing(t *testing.T) {
+	dir := t.TempDir()
+	result := doctor.CheckIncludeIf("work", config.Profile{GitEmail: "w@acme.com"}, filepath.Join(dir, ".gitconfig"), dir)
+	assert.Equal(t, doctor.StatusWarn, result.Status)
+}
+
+func TestCheckIncludeIfLocal_Disagree(t *testing.T) {
 	fake := testutil.NewFakeCommander()
-	fake.DefaultResponse = &testutil.Response{Output: []byte("ok"), Err: nil}
+	fake.Register("git -C /repo config --show-scope --show-origin --get-all user.email",
+		"global\tfile:/home/u/.config/ctx/git/work.gitconfig\tw@acme.com\nlocal\tfile:.git/config\tme@example.com\n", nil)
 
-	t.Setenv("GH_TOKEN", "")
-	t.Setenv("GITHUB_TOKEN", "")
+	result := doctor.CheckIncludeIfLocal(context.Background(), fake, "/repo", "/home/u/.config/ctx/git")
+	assert.Equal(t, doctor.StatusWarn, result.Status)
+	assert.Contains(t, result.Message, "me@example.com")
+}
 
-	results := doctor.RunAll(context.Background(), fake, "/tmp/gh-config", "github-work")
-	assert.NotEmpty(t, results)
-	for _, r := range results {
-		assert.NotEmpty(t, r.Name)
-	}
+func TestCheckIncludeIfLocal_Worktree(t *testing.T) {
+	fake := testutil.NewFakeCommander()
+	fake.Register("git -C /wt config --show-scope --show-origin --get-all user.email",
+		"global\tfile:/home/u/.config/ctx/git/work.gitconfig\tw@acme.com\nworktree\tfile:/src/repo/.git/worktrees/wt/config.worktree\tme@example.com\n", nil)
+
+	result := doctor.CheckIncludeIfLocal(context.Background(), fake, "/wt", "/home/u/.config/ctx/git")
+	assert.Equal(t, doctor.StatusWarn, result.Status, "워크트리 설정(config.worktree)도 로컬 설정이다")
+}
+
+func TestCheckIncludeIfLocal_Agree(t *testing.T) {
+	fake := testutil.NewFakeCommander()
+	fake.Register("git -C /repo config --show-scope --show-origin --get-all user.email",
+		"global\tfile:/home/u/.config/ctx/git/work.gitconfig\tw@acme.com\nlocal\tfile:.git/config\tw@acme.com\n", nil)
+
+	result := doctor.CheckIncludeIfLocal(context.Background(), fake, "/repo", "/home/u/.config/ctx/git")
+	assert.Equal(t, doctor.StatusOK, result.Status)
 }
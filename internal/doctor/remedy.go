@@ -0,0 +1,129 @@
+package doctor
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/hbjs97/ctx/internal/cmdexec"
+	"github.com/hbjs97/ctx/internal/fsutil"
+	"github.com/hbjs97/ctx/internal/gh"
+	"github.com/hbjs97/ctx/internal/shell"
+	"github.com/hbjs97/ctx/internal/sshconfig"
+)
+
+// withRemedy는 정상(OK)이 아닌 결과에 자동 조치를 붙인다. rem이 nil이면 그대로 반환한다.
+func withRemedy(results []DiagResult, rem *Remedy) []DiagResult {
+	if rem == nil {
+		return results
+	}
+	for i := range results {
+		if results[i].Status != StatusOK {
+			results[i].Remedy = rem
+		}
+	}
+	return results
+}
+
+// ChmodRemedy는 파일 권한을 0600으로 바꾸는 조치다.
+func ChmodRemedy(path string) *Remedy {
+	return &Remedy{
+		Description: fmt.Sprintf("chmod 600 %s", path),
+		Apply: func(ctx context.Context) error {
+			if err := os.Chmod(path, 0600); err != nil {
+				return fmt.Errorf("doctor.ChmodRemedy: %w", err)
+			}
+			return nil
+		},
+	}
+}
+
+// IdentitiesOnlyRemedy는 SSH Host 블록에 IdentitiesOnly yes를 추가하는 조치다.
+// Host가 Include된 파일에 선언되어 있으면 그 파일을 수정하고, 선언이 없으면 main config에 블록을 추가한다.
+func IdentitiesOnlyRemedy(sshConfigPath, sshHost string) *Remedy {
+	if sshConfigPath == "" {
+		return nil
+	}
+	return &Remedy{
+		Description: fmt.Sprintf("Host %s에 IdentitiesOnly yes 추가", sshHost),
+		Apply: func(ctx context.Context) error {
+			main, err := sshconfig.Load(sshConfigPath)
+			if err != nil {
+				return fmt.Errorf("doctor.IdentitiesOnlyRemedy: %w", err)
+			}
+
+			target := main
+			if b := main.FindHost(sshHost); b != nil && b.File() != sshConfigPath {
+				if target, err = sshconfig.Load(b.File()); err != nil {
+					return fmt.Errorf("doctor.IdentitiesOnlyRemedy: %w", err)
+				}
+			}
+			b := target.FindHost(sshHost)
+			if b == nil {
+				b = target.AddHost(sshHost)
+			}
+			b.Set("IdentitiesOnly", "yes")
+			if err := target.Save(); err != nil {
+				return fmt.Errorf("doctor.IdentitiesOnlyRemedy: %w", err)
+			}
+			return nil
+		},
+	}
+}
+
+// ShellHookRemedy는 RC 파일에 ctx 셸 hook을 설치하는 조치다.
+func ShellHookRemedy(shellType, rcPath string) *Remedy {
+	if rcPath == "" || shell.HookSnippet(shellType) == "" {
+		return nil
+	}
+	return &Remedy{
+		Description: fmt.Sprintf("%s에 셸 hook 설치", rcPath),
+		Apply: func(ctx context.Context) error {
+			if err := shell.InstallHook(shellType, rcPath); err != nil {
+				return fmt.Errorf("doctor.ShellHookRemedy: %w", err)
+			}
+			return nil
+		},
+	}
+}
+
+// GHAuthRefreshRemedy는 프로필의 gh 인증을 gh auth refresh로 갱신하는 조치다.
+// 브라우저 인증이 필요할 수 있으므로 터미널을 연결해 실행한다.
+func GHAuthRefreshRemedy(cmd cmdexec.Commander, ghConfigDir string) *Remedy {
+	return &Remedy{
+		Description: fmt.Sprintf("GH_CONFIG_DIR=%s gh auth refresh 실행", ghConfigDir),
+		Apply: func(ctx context.Context) error {
+			env := gh.SuppressEnvTokens()
+			env["GH_CONFIG_DIR"] = ghConfigDir
+			if err := cmd.RunInteractiveWithEnv(ctx, env, "gh", "auth", "refresh", "--hostname", "github.com"); err != nil {
+				return fmt.Errorf("doctor.GHAuthRefreshRemedy: %w", err)
+			}
+			return nil
+		},
+	}
+}
+
+// SSHKeyRegisterRemedy는 프로필의 공개 키를 gh ssh-key add로 GitHub 계정에 등록하는 조치다.
+// identityFile이 비어있으면 nil을 반환한다.
+func SSHKeyRegisterRemedy(cmd cmdexec.Commander, ghConfigDir, identityFile, title string) *Remedy {
+	if identityFile == "" {
+		return nil
+	}
+	pubKey := fsutil.ExpandHome(identityFile) + ".pub"
+	return &Remedy{
+		Description: fmt.Sprintf("gh ssh-key add %s --title %s", pubKey, title),
+		Apply: func(ctx context.Context) error {
+			if _, err := os.Stat(pubKey); err != nil {
+				return fmt.Errorf("doctor.SSHKeyRegisterRemedy: 공개 키 없음: %w", err)
+			}
+			env := gh.SuppressEnvTokens()
+			env["GH_CONFIG_DIR"] = ghConfigDir
+			out, err := cmd.RunWithEnv(ctx, env, "gh", "ssh-key", "add", pubKey, "--title", title)
+			if err != nil {
+				return fmt.Errorf("doctor.SSHKeyRegisterRemedy: %s: %w", strings.TrimSpace(string(out)), err)
+			}
+			return nil
+		},
+	}
+}
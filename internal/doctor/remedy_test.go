@@ -0,0 +1,98 @@
+package doctor_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/hbjs97/ctx/internal/doctor"
+	"github.com/hbjs97/ctx/internal/shell"
+	"github.com/hbjs97/ctx/internal/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestChmodRemedy(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.toml")
+	require.NoError(t, os.WriteFile(path, []byte("version = 1\n"), 0644))
+
+	require.NoError(t, doctor.ChmodRemedy(path).Apply(context.Background()))
+
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+}
+
+func TestIdentitiesOnlyRemedy_IncludedFile(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "config")
+	ctxPath := filepath.Join(dir, "config.d", "ctx")
+	require.NoError(t, os.MkdirAll(filepath.Dir(ctxPath), 0700))
+	require.NoError(t, os.WriteFile(ctxPath, []byte("Host github.com-work\n  HostName github.com\n"), 0600))
+	require.NoError(t, os.WriteFile(path, []byte("Include config.d/ctx\n"), 0600))
+
+	require.NoError(t, doctor.IdentitiesOnlyRemedy(path, "github.com-work").Apply(context.Background()))
+
+	data, err := os.ReadFile(ctxPath)
+	require.NoError(t, err)
+	assert.Equal(t, "Host github.com-work\n  HostName github.com\n  IdentitiesOnly yes\n", string(data))
+	data, err = os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, "Include config.d/ctx\n", string(data), "main config는 건드리지 않는다")
+}
+
+func TestIdentitiesOnlyRemedy_MissingHost(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config")
+
+	require.NoError(t, doctor.IdentitiesOnlyRemedy(path, "github.com-work").Apply(context.Background()))
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, "Host github.com-work\n  IdentitiesOnly yes\n", string(data))
+	assert.Nil(t, doctor.IdentitiesOnlyRemedy("", "github.com-work"))
+}
+
+func TestShellHookRemedy(t *testing.T) {
+	rcPath := filepath.Join(t.TempDir(), ".zshrc")
+
+	require.NoError(t, doctor.ShellHookRemedy("zsh", rcPath).Apply(context.Background()))
+
+	data, err := os.ReadFile(rcPath)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), shell.HookMarker)
+	assert.Nil(t, doctor.ShellHookRemedy("tcsh", rcPath), "지원하지 않는 셸")
+}
+
+func TestSSHKeyRegisterRemedy(t *testing.T) {
+	dir := t.TempDir()
+	key := filepath.Join(dir, "id_work")
+	fake := testutil.NewFakeCommander()
+	fake.Register("gh ssh-key add", "", nil)
+
+	rem := doctor.SSHKeyRegisterRemedy(fake, "/tmp/gh-work", key, "ctx-work")
+	require.Error(t, rem.Apply(context.Background()), "공개 키 없음")
+	assert.False(t, fake.Called("gh ssh-key add"))
+
+	require.NoError(t, os.WriteFile(key+".pub", []byte("ssh-ed25519 AAAA work\n"), 0600))
+	require.NoError(t, rem.Apply(context.Background()))
+	assert.True(t, fake.Called("gh ssh-key add "+key+".pub --title ctx-work"))
+
+	assert.Nil(t, doctor.SSHKeyRegisterRemedy(fake, "/tmp/gh-work", "", "ctx-work"))
+}
+
+func TestCheckCacheFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cache.json")
+
+	assert.Equal(t, doctor.StatusOK, doctor.CheckCacheFile(path).Status, "캐시가 없으면 정상")
+
+	require.NoError(t, os.WriteFile(path, []byte("{}"), 0644))
+	result := doctor.CheckCacheFile(path)
+	assert.Equal(t, doctor.StatusWarn, result.Status)
+	assert.Equal(t, "chmod 600 "+path, result.Fix)
+
+	require.NoError(t, os.WriteFile(path, []byte(`{"version": 99}`), 0600))
+	result = doctor.CheckCacheFile(path)
+	assert.Equal(t, doctor.StatusFail, result.Status)
+	assert.Contains(t, result.Message, "version 99")
+}
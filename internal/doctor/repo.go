@@ -0,0 +1,596 @@
+package doctor
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/hbjs97/ctx/internal/cache"
+	"github.com/hbjs97/ctx/internal/cmdexec"
+	"github.com/hbjs97/ctx/internal/config"
+	"github.com/hbjs97/ctx/internal/fsutil"
+	"github.com/hbjs97/ctx/internal/git"
+	"github.com/hbjs97/ctx/internal/guard"
+)
+
+// RepoInfo는 리포 진단 대상 checkout의 정보다.
+type RepoInfo struct {
+	Root      string // 작업 트리 최상위 경로
+	RemoteURL string // origin URL. 없으면 빈 문자열
+	OwnerRepo string // origin에서 추출한 owner/repo. 파싱 실패 시 빈 문자열
+	Profile   string // .git/ctx-profile 내용. 없으면 빈 문자열
+	Hints     *config.RepoHints
+	HintsErr  error // .ctx.toml을 읽지 못한 이유
+}
+
+// LoadRepoInfo는 dir이 속한 리포의 진단 정보를 수집한다. git 리포가 아니면 에러를 반환한다.
+func LoadRepoInfo(ctx context.Context, cmd cmdexec.Commander, dir string) (*RepoInfo, error) {
+	g := git.NewAdapter(cmd)
+	root, err := g.TopLevel(ctx, dir)
+	if err != nil {
+		return nil, fmt.Errorf("doctor.LoadRepoInfo: git 리포가 아님: %w", err)
+	}
+	info := &RepoInfo{Root: root}
+	if remote, err := g.GetRemoteURL(ctx, root, "origin"); err == nil {
+		info.RemoteURL = remote
+		if ref, err := git.ParseRepoURL(remote); err == nil {
+			info.OwnerRepo = ref.Owner + "/" + ref.Repo
+		}
+	}
+	if data, err := os.ReadFile(filepath.Join(root, ".git", "ctx-profile")); err == nil {
+		info.Profile = strings.TrimSpace(string(data))
+	}
+	info.Hints, info.HintsErr = config.LoadRepoHints(root)
+	return info, nil
+}
+
+// repoProfile은 리포 진단에 쓸 프로필을 반환한다. ctx-profile이 없거나 설정에 없으면 nil이다.
+func (e *Env) repoProfile() *config.Profile {
+	if e.Repo == nil || e.Config == nil || e.Repo.Profile == "" {
+		return nil
+	}
+	p, err := e.Config.GetProfile(e.Repo.Profile)
+	if err != nil {
+		return nil
+	}
+	return p
+}
+
+// repoRegistry는 ctx doctor --repo가 실행하는 리포 진단 항목이다.
+var repoRegistry = []Check{
+	{
+		Name:        "repo_profile",
+		Description: "리포의 .git/ctx-profile과 owner 규칙",
+		Repo:        true,
+		Run: func(ctx context.Context, env *Env, _ string) []DiagResult {
+			if env.Repo == nil {
+				return nil
+			}
+			return []DiagResult{CheckRepoProfile(env.Config, env.Repo)}
+		},
+	},
+	{
+		Name:        "repo_guard_hook",
+		Description: "guard pre-push hook 설치와 실행 권한 (core.hooksPath 포함)",
+		Repo:        true,
+		Run: func(ctx context.Context, env *Env, _ string) []DiagResult {
+			if env.Repo == nil || (env.Config != nil && !env.Config.IsRequirePushGuard()) {
+				return nil
+			}
+			return []DiagResult{CheckGuardHook(ctx, env.Commander, env.Repo.Root)}
+		},
+	},
+	{
+		Name:        "repo_remote",
+		Description: "origin remote host와 프로필 SSH host",
+		Repo:        true,
+		Run: func(ctx context.Context, env *Env, _ string) []DiagResult {
+			p := env.repoProfile()
+			if p == nil {
+				return nil
+			}
+			return []DiagResult{CheckRepoRemote(env.Repo.RemoteURL, p, env.Config.AllowHTTPSManagedRepo)}
+		},
+	},
+	{
+		Name:        "repo_identity",
+		Description: "로컬/유효 git identity와 프로필",
+		Repo:        true,
+		Run: func(ctx context.Context, env *Env, _ string) []DiagResult {
+			p := env.repoProfile()
+			if p == nil {
+				return nil
+			}
+			return CheckRepoIdentity(ctx, env.Commander, env.Repo.Root, p)
+		},
+	},
+	{
+		Name:        "repo_includeif",
+		Description: "리포에 적용되지만 로컬 설정에 가려진 includeIf identity",
+		Repo:        true,
+		Run: func(ctx context.Context, env *Env, _ string) []DiagResult {
+			p := env.repoProfile()
+			if p == nil {
+				return nil
+			}
+			return []DiagResult{CheckShadowedIdentity(ctx, env.Commander, env.Repo.Root, p.GitEmail)}
+		},
+	},
+	{
+		Name:        "repo_cache",
+		Description: "리포의 프로필 판정 캐시",
+		Repo:        true,
+		Run: func(ctx context.Context, env *Env, _ string) []DiagResult {
+			if env.repoProfile() == nil || env.Repo.OwnerRepo == "" || env.CachePath == "" {
+				return nil
+			}
+			c, err := cache.Load(env.CachePath)
+			if err != nil {
+				return nil
+			}
+			r := CheckRepoCache(c, env.Repo.OwnerRepo, env.Repo.Profile, env.Config, env.now())
+			if r.Status != StatusOK {
+				r.Remedy = CacheEntryRemedy(env.CachePath, env.Repo.OwnerRepo)
+			}
+			return []DiagResult{r}
+		},
+	},
+	{
+		Name:        "repo_shell",
+		Description: "현재 셸의 CTX_PROFILE",
+		Repo:        true,
+		Run: func(ctx context.Context, env *Env, _ string) []DiagResult {
+			if env.repoProfile() == nil {
+				return nil
+			}
+			return []DiagResult{CheckShellProfile(env.Repo.Profile, os.Getenv("CTX_PROFILE"))}
+		},
+	},
+	{
+		Name:        "repo_email_rules",
+		Description: "리포 user.email과 커밋 서명이 email_rules를 지키는지",
+		Repo:        true,
+		Run: func(ctx context.Context, env *Env, _ string) []DiagResult {
+			if env.Repo == nil || env.Config == nil || len(env.Config.EmailRules) == 0 {
+				return nil
+			}
+			return CheckRepoEmailRules(ctx, env.Commander, env.Repo.Root, env.Repo.OwnerRepo, env.Config.EmailRules)
+		},
+	},
+	{
+		Name:        "repo_hints",
+		Description: "리포의 .ctx.toml 기대값(host, owner, email 도메인)과 프로필",
+		Repo:        true,
+		Run: func(ctx context.Context, env *Env, _ string) []DiagResult {
+			if env.Repo == nil || (env.Repo.Hints == nil && env.Repo.HintsErr == nil) {
+				return nil
+			}
+			if env.Repo.HintsErr != nil {
+				return []DiagResult{{
+					Name:    "repo_hints",
+					Status:  StatusFail,
+					Message: env.Repo.HintsErr.Error(),
+					Fix:     fmt.Sprintf("%s 수정", filepath.Join(env.Repo.Root, config.RepoFileName)),
+				}}
+			}
+			p := env.repoProfile()
+			if p == nil {
+				return nil
+			}
+			return CheckRepoHints(env.Repo.Hints, env.Repo.OwnerRepo, p, env.hostName(p.SSHHost))
+		},
+	},
+}
+
+// CheckRepoEmailRules는 리포의 유효한 user.email과 commit.gpgsign을 email_rules로 검사한다.
+// guard와 같은 규칙을 적용하므로 FAIL이면 push가 차단된다.
+func CheckRepoEmailRules(ctx context.Context, cmd cmdexec.Commander, root, ownerRepo string, rules []config.EmailRule) []DiagResult {
+	const name = "repo_email_rules"
+	out, _ := cmd.Run(ctx, "git", "-C", root, "config", "--get", "user.email") // 없으면 빈 이메일로 평가한다
+	email := strings.TrimSpace(string(out))
+
+	violations := guard.CheckEmailRules(ctx, cmd, root, rules, ownerRepo, email)
+	if len(violations) == 0 {
+		return []DiagResult{{Name: name, Status: StatusOK, Message: fmt.Sprintf("%s: email_rules 통과", email)}}
+	}
+	results := make([]DiagResult, 0, len(violations))
+	for _, v := range violations {
+		r := DiagResult{
+			Name:    name,
+			Status:  StatusFail,
+			Message: fmt.Sprintf("규칙 %s: %s: 기대=%s, 실제=%s", v.Rule, v.Field, v.Expected, v.Actual),
+			Fix:     "규칙에 맞는 프로필로 ctx init --profile <name> 재실행",
+		}
+		if v.Field == "commit_signing" {
+			r.Fix = "git config --local commit.gpgsign true (서명 키 설정 필요)"
+		}
+		results = append(results, r)
+	}
+	return results
+}
+
+// CheckRepoHints는 리포 .ctx.toml의 기대값을 리포 프로필과 origin remote에 비교한다.
+// 위반마다 FAIL 결과 하나를 반환하며, guard도 같은 위반으로 push를 차단한다.
+func CheckRepoHints(hints *config.RepoHints, ownerRepo string, p *config.Profile, hostName string) []DiagResult {
+	const name = "repo_hints"
+	violations := guard.CheckRepoHints(hints, ownerRepo, p.GitEmail, hostName)
+	if len(violations) == 0 {
+		return []DiagResult{{
+			Name:    name,
+			Status:  StatusOK,
+			Message: fmt.Sprintf("%s 기대값과 일치", hints.Path),
+		}}
+	}
+	results := make([]DiagResult, 0, len(violations))
+	for _, v := range violations {
+		r := DiagResult{
+			Name:    name,
+			Status:  StatusFail,
+			Message: fmt.Sprintf("%s: 기대=%s, 실제=%s (%s)", v.Field, v.Expected, v.Actual, hints.Path),
+			Fix:     "기대값에 맞는 프로필로 ctx init --profile <name> 재실행",
+		}
+		if v.Field == "repo_owner" {
+			r.Fix = "origin remote가 올바른 리포를 가리키는지 확인"
+		}
+		results = append(results, r)
+	}
+	return results
+}
+
+// CheckRepoProfile은 .git/ctx-profile이 설정에 있는 프로필을 가리키는지 확인한다.
+// owner 규칙이 다른 프로필을 가리키면 경고한다.
+func CheckRepoProfile(cfg *config.Config, repo *RepoInfo) DiagResult {
+	const name = "repo_profile"
+	switch {
+	case repo.Profile == "":
+		return DiagResult{
+			Name:    name,
+			Status:  StatusFail,
+			Message: "ctx로 관리되지 않는 리포 (.git/ctx-profile 없음)",
+			Fix:     "ctx init",
+		}
+	case cfg == nil:
+		return DiagResult{
+			Name:    name,
+			Status:  StatusFail,
+			Message: fmt.Sprintf("설정 로드 실패 — 프로필 %q 확인 불가", repo.Profile),
+			Fix:     "ctx doctor --check config_file",
+		}
+	}
+	if _, err := cfg.GetProfile(repo.Profile); err != nil {
+		return DiagResult{
+			Name:    name,
+			Status:  StatusFail,
+			Message: fmt.Sprintf(".git/ctx-profile의 프로필 %q이 설정에 없음", repo.Profile),
+			Fix:     "ctx init --profile <프로필>",
+		}
+	}
+
+	if owner, _, ok := strings.Cut(repo.OwnerRepo, "/"); ok {
+		matches := cfg.MatchOwner(owner)
+		if len(matches) > 0 && !slices.Contains(matches, repo.Profile) {
+			return DiagResult{
+				Name:    name,
+				Status:  StatusWarn,
+				Message: fmt.Sprintf("프로필 %s 사용 중이지만 owner %s 규칙은 %s를 가리킴", repo.Profile, owner, strings.Join(matches, ", ")),
+				Fix:     fmt.Sprintf("의도한 프로필이 아니면 ctx init --profile %s", matches[0]),
+			}
+		}
+	}
+	return DiagResult{
+		Name:    name,
+		Status:  StatusOK,
+		Message: fmt.Sprintf("프로필: %s", repo.Profile),
+	}
+}
+
+// CheckGuardHook은 guard pre-push hook이 git이 실제로 실행하는 위치에 실행 가능하게 설치되어 있는지 확인한다.
+// core.hooksPath가 설정되어 있으면 .git/hooks 대신 그 디렉토리를 본다.
+func CheckGuardHook(ctx context.Context, cmd cmdexec.Commander, root string) DiagResult {
+	const name = "repo_guard_hook"
+	defaultDir := filepath.Join(root, ".git", "hooks")
+	hooksDir := defaultDir
+	if out, err := cmd.Run(ctx, "git", "-C", root, "config", "--get", "core.hooksPath"); err == nil {
+		if dir := strings.TrimSpace(string(out)); dir != "" {
+			hooksDir = fsutil.ExpandHome(dir)
+			if !filepath.IsAbs(hooksDir) {
+				hooksDir = filepath.Join(root, hooksDir)
+			}
+		}
+	}
+	hookPath := filepath.Join(hooksDir, "pre-push")
+
+	data, _ := os.ReadFile(hookPath) // 파일이 없으면 미설치로 판단
+	if !strings.Contains(string(data), guard.HookMarker) {
+		if hooksDir != defaultDir {
+			msg := fmt.Sprintf("core.hooksPath(%s)의 pre-push에 guard 없음", hooksDir)
+			if installed, _ := os.ReadFile(filepath.Join(defaultDir, "pre-push")); strings.Contains(string(installed), guard.HookMarker) {
+				msg += " — .git/hooks에 설치된 guard는 실행되지 않음"
+			}
+			return DiagResult{
+				Name:    name,
+				Status:  StatusFail,
+				Message: msg,
+				Fix:     fmt.Sprintf("%s에 ctx guard check 호출 추가", hookPath),
+			}
+		}
+		return DiagResult{
+			Name:    name,
+			Status:  StatusFail,
+			Message: "guard pre-push hook 없음",
+			Fix:     "ctx init 재실행 (guard hook 자동 설치)",
+			Remedy: &Remedy{
+				Description: fmt.Sprintf("%s에 guard hook 설치", hookPath),
+				Apply: func(ctx context.Context) error {
+					if err := guard.InstallHook(root); err != nil {
+						return fmt.Errorf("doctor.CheckGuardHook: %w", err)
+					}
+					return nil
+				},
+			},
+		}
+	}
+
+	info, err := os.Stat(hookPath)
+	if err == nil && info.Mode().Perm()&0111 == 0 {
+		return DiagResult{
+			Name:    name,
+			Status:  StatusFail,
+			Message: fmt.Sprintf("%s에 실행 권한 없음 — git이 hook을 건너뜀", hookPath),
+			Fix:     fmt.Sprintf("chmod +x %s", hookPath),
+			Remedy: &Remedy{
+				Description: fmt.Sprintf("chmod 755 %s", hookPath),
+				Apply: func(ctx context.Context) error {
+					if err := os.Chmod(hookPath, 0755); err != nil { // git hook은 실행 권한 필요
+						return fmt.Errorf("doctor.CheckGuardHook: %w", err)
+					}
+					return nil
+				},
+			},
+		}
+	}
+	if hooksDir == defaultDir && guard.HookOutdated(string(data)) {
+		return DiagResult{
+			Name:    name,
+			Status:  StatusWarn,
+			Message: "이전 버전 guard hook — push되는 브랜치를 넘기지 않아 protected_branch, force_push 검사 불가",
+			Fix:     "ctx init 재실행 (guard hook 갱신)",
+			Remedy: &Remedy{
+				Description: fmt.Sprintf("%s의 guard 블록 갱신", hookPath),
+				Apply: func(ctx context.Context) error {
+					if err := guard.InstallHook(root); err != nil {
+						return fmt.Errorf("doctor.CheckGuardHook: %w", err)
+					}
+					return nil
+				},
+			},
+		}
+	}
+	return DiagResult{
+		Name:    name,
+		Status:  StatusOK,
+		Message: fmt.Sprintf("guard hook 설치됨: %s", hookPath),
+	}
+}
+
+// CheckRepoRemote는 origin remote가 프로필의 SSH host를 쓰는지 확인한다.
+func CheckRepoRemote(remoteURL string, p *config.Profile, allowHTTPS bool) DiagResult {
+	const name = "repo_remote"
+	switch {
+	case remoteURL == "":
+		return DiagResult{
+			Name:    name,
+			Status:  StatusWarn,
+			Message: "origin remote 없음",
+			Fix:     "git remote add origin <url>",
+		}
+	case git.IsHTTPSRemote(remoteURL):
+		if allowHTTPS {
+			return DiagResult{Name: name, Status: StatusOK, Message: fmt.Sprintf("HTTPS remote 허용됨: %s", remoteURL)}
+		}
+		return DiagResult{
+			Name:    name,
+			Status:  StatusWarn,
+			Message: fmt.Sprintf("HTTPS remote — SSH host %s가 적용되지 않음: %s", p.SSHHost, remoteURL),
+			Fix:     "ctx init 재실행 (SSH remote로 변환)",
+		}
+	}
+	if host := git.SSHHost(remoteURL); host != p.SSHHost {
+		return DiagResult{
+			Name:    name,
+			Status:  StatusFail,
+			Message: fmt.Sprintf("remote host %s가 프로필 SSH host %s와 다름 — guard가 push 차단", host, p.SSHHost),
+			Fix:     "ctx init 재실행 (remote URL 갱신)",
+		}
+	}
+	return DiagResult{
+		Name:    name,
+		Status:  StatusOK,
+		Message: fmt.Sprintf("remote: %s", remoteURL),
+	}
+}
+
+// configValue는 git config --show-scope --show-origin 출력의 한 줄이다.
+type configValue struct {
+	Scope  string
+	Origin string
+	Value  string
+}
+
+// configValues는 key의 모든 값을 우선순위가 낮은 것부터 반환한다. 마지막 값이 유효 값이다.
+func configValues(ctx context.Context, cmd cmdexec.Commander, root, key string) []configValue {
+	out, err := cmd.Run(ctx, "git", "-C", root, "config", "--show-scope", "--show-origin", "--get-all", key)
+	if err != nil {
+		return nil // 값이 하나도 없으면 exit 1
+	}
+	var values []configValue
+	for _, line := range strings.Split(strings.TrimRight(string(out), "\n"), "\n") {
+		parts := strings.SplitN(line, "\t", 3)
+		if len(parts) != 3 {
+			continue
+		}
+		values = append(values, configValue{Scope: parts[0], Origin: strings.TrimPrefix(parts[1], "file:"), Value: parts[2]})
+	}
+	return values
+}
+
+// CheckRepoIdentity는 리포의 로컬 user.email/user.name과 실제로 적용되는 값이 프로필과 같은지 확인한다.
+// guard는 로컬 값을 검사하므로 로컬 email이 다르면 push가 차단된다.
+func CheckRepoIdentity(ctx context.Context, cmd cmdexec.Commander, root string, p *config.Profile) []DiagResult {
+	g := git.NewAdapter(cmd)
+	var results []DiagResult
+	for _, f := range []struct {
+		key      string
+		expected string
+		severity Status
+	}{
+		{key: "user.email", expected: p.GitEmail, severity: StatusFail},
+		{key: "user.name", expected: p.GitName, severity: StatusWarn},
+	} {
+		name := "repo_identity_" + strings.TrimPrefix(f.key, "user.")
+		values := configValues(ctx, cmd, root, f.key)
+
+		var local string
+		for _, v := range values {
+			if v.Scope == "local" {
+				local = v.Value
+			}
+		}
+		key, expected := f.key, f.expected
+		remedy := &Remedy{
+			Description: fmt.Sprintf("git config --local %s %q", key, expected),
+			Apply: func(ctx context.Context) error {
+				return g.SetLocalConfig(ctx, root, key, expected)
+			},
+		}
+
+		if local != f.expected {
+			results = append(results, DiagResult{
+				Name:    name,
+				Status:  f.severity,
+				Message: fmt.Sprintf("로컬 %s %q이 프로필 값 %q과 다름", f.key, local, f.expected),
+				Fix:     fmt.Sprintf("git config --local %s %q", f.key, f.expected),
+				Remedy:  remedy,
+			})
+			continue
+		}
+		if n := len(values); n > 0 && values[n-1].Value != f.expected {
+			eff := values[n-1]
+			results = append(results, DiagResult{
+				Name:    name,
+				Status:  f.severity,
+				Message: fmt.Sprintf("유효 %s %q이 %s(%s)에서 옴 — 로컬 값을 덮어씀", f.key, eff.Value, eff.Origin, eff.Scope),
+				Fix:     fmt.Sprintf("%s에서 %s 제거", eff.Origin, f.key),
+			})
+			continue
+		}
+		results = append(results, DiagResult{
+			Name:    name,
+			Status:  StatusOK,
+			Message: fmt.Sprintf("%s: %s", f.key, f.expected),
+		})
+	}
+	return results
+}
+
+// CheckShadowedIdentity는 리포 로컬 설정보다 우선순위가 낮은 곳(includeIf 등)에서
+// 프로필과 다른 user.email이 이 리포에 적용되고 있는지 확인한다.
+// 지금은 로컬 값에 가려지지만 ctx-profile이나 로컬 설정이 사라지면 그 값이 쓰인다.
+func CheckShadowedIdentity(ctx context.Context, cmd cmdexec.Commander, root, expectedEmail string) DiagResult {
+	const name = "repo_includeif"
+	var shadowed []string
+	for _, v := range configValues(ctx, cmd, root, "user.email") {
+		if v.Scope != "local" && v.Value != expectedEmail {
+			shadowed = append(shadowed, fmt.Sprintf("%s(%s)", v.Value, v.Origin))
+		}
+	}
+	if len(shadowed) > 0 {
+		return DiagResult{
+			Name:    name,
+			Status:  StatusWarn,
+			Message: fmt.Sprintf("이 리포에 다른 user.email이 적용되는 설정: %s", strings.Join(shadowed, ", ")),
+			Fix:     "includeIf gitdir 조건이 이 리포를 포함하지 않도록 수정 (git config --show-origin --get-all user.email)",
+		}
+	}
+	return DiagResult{
+		Name:    name,
+		Status:  StatusOK,
+		Message: "다른 identity를 주입하는 설정 없음",
+	}
+}
+
+// CheckRepoCache는 리포의 프로필 판정 캐시가 현재 ctx-profile, 설정과 일치하는지 확인한다.
+func CheckRepoCache(c *cache.Cache, ownerRepo, profile string, cfg *config.Config, now time.Time) DiagResult {
+	const name = "repo_cache"
+	e, ok := c.Entries[ownerRepo]
+	if !ok {
+		return DiagResult{Name: name, Status: StatusOK, Message: "캐시 항목 없음"}
+	}
+
+	var reason string
+	resolved, err := time.Parse(time.RFC3339, e.ResolvedAt)
+	switch {
+	case e.Profile != profile:
+		reason = fmt.Sprintf("캐시는 %s, ctx-profile은 %s", e.Profile, profile)
+	case e.ConfigHash != cfg.ConfigHash():
+		reason = "판정 이후 설정이 변경됨"
+	case err != nil || now.Sub(resolved) > time.Duration(cfg.CacheTTLDays)*24*time.Hour:
+		reason = fmt.Sprintf("TTL(%d일) 만료", cfg.CacheTTLDays)
+	}
+	if reason != "" {
+		return DiagResult{
+			Name:    name,
+			Status:  StatusWarn,
+			Message: fmt.Sprintf("%s 캐시 항목이 오래됨: %s", ownerRepo, reason),
+			Fix:     "ctx init 재실행 (캐시 갱신)",
+		}
+	}
+	return DiagResult{
+		Name:    name,
+		Status:  StatusOK,
+		Message: fmt.Sprintf("캐시: %s (%s)", e.Profile, e.Reason),
+	}
+}
+
+// CacheEntryRemedy는 캐시에서 리포 항목을 지우는 조치다. 다음 판정 시 다시 계산된다.
+func CacheEntryRemedy(cachePath, ownerRepo string) *Remedy {
+	return &Remedy{
+		Description: fmt.Sprintf("캐시에서 %s 항목 삭제", ownerRepo),
+		Apply: func(ctx context.Context) error {
+			err := cache.Update(cachePath, func(c *cache.Cache) error {
+				delete(c.Entries, ownerRepo)
+				return nil
+			})
+			if err != nil {
+				return fmt.Errorf("doctor.CacheEntryRemedy: %w", err)
+			}
+			return nil
+		},
+	}
+}
+
+// CheckShellProfile은 현재 셸의 CTX_PROFILE이 리포 프로필과 같은지 확인한다.
+func CheckShellProfile(expected, actual string) DiagResult {
+	const name = "repo_shell"
+	switch actual {
+	case expected:
+		return DiagResult{Name: name, Status: StatusOK, Message: fmt.Sprintf("CTX_PROFILE=%s", actual)}
+	case "":
+		return DiagResult{
+			Name:    name,
+			Status:  StatusWarn,
+			Message: "CTX_PROFILE 미설정 — 셸 hook이 동작하지 않음",
+			Fix:     "ctx doctor --check shell_hook",
+		}
+	}
+	return DiagResult{
+		Name:    name,
+		Status:  StatusFail,
+		Message: fmt.Sprintf("CTX_PROFILE=%s이지만 리포 프로필은 %s — gh가 다른 계정으로 동작", actual, expected),
+		Fix:     "cd . 으로 셸 hook 재실행 또는 새 셸 열기",
+	}
+}
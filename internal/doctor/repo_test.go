@@ -0,0 +1,238 @@
+package doctor_test
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/hbjs97/ctx/internal/cache"
+	"github.com/hbjs97/ctx/internal/config"
+	"github.com/hbjs97/ctx/internal/doctor"
+	"github.com/hbjs97/ctx/internal/guard"
+	"github.com/hbjs97/ctx/internal/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func repoConfig() *config.Config {
+	return &config.Config{
+		CacheTTLDays: 90,
+		Profiles: map[string]config.Profile{
+			"work": {
+				GHConfigDir: "/tmp/gh-work", SSHHost: "github.com-work",
+				GitName: "HB Work", GitEmail: "hb@company.com", Owners: []string{"company-org"},
+			},
+			"personal": {
+				GHConfigDir: "/tmp/gh-personal", SSHHost: "github.com-personal",
+				GitName: "hbjs97", GitEmail: "hbjs97@example.com", Owners: []string{"hbjs97"},
+			},
+		},
+	}
+}
+
+func TestLoadRepoInfo(t *testing.T) {
+	root := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(root, ".git"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(root, ".git", "ctx-profile"), []byte("work\n"), 0600))
+
+	fake := testutil.NewFakeCommander()
+	fake.Register("git -C "+root+"/sub rev-parse --show-toplevel", root+"\n", nil)
+	fake.Register("git -C "+root+" remote get-url origin", "git@github.com-work:company-org/api.git\n", nil)
+
+	info, err := doctor.LoadRepoInfo(context.Background(), fake, root+"/sub")
+	require.NoError(t, err)
+	assert.Equal(t, &doctor.RepoInfo{
+		Root:      root,
+		RemoteURL: "git@github.com-work:company-org/api.git",
+		OwnerRepo: "company-org/api",
+		Profile:   "work",
+	}, info)
+
+	fake.Register("git -C /outside", "", fmt.Errorf("not a git repository"))
+	_, err = doctor.LoadRepoInfo(context.Background(), fake, "/outside")
+	assert.Error(t, err)
+}
+
+func TestCheckRepoProfile(t *testing.T) {
+	cfg := repoConfig()
+	tests := []struct {
+		name string
+		repo doctor.RepoInfo
+		want doctor.Status
+	}{
+		{name: "ok", repo: doctor.RepoInfo{Profile: "work", OwnerRepo: "company-org/api"}, want: doctor.StatusOK},
+		{name: "unmanaged", repo: doctor.RepoInfo{OwnerRepo: "company-org/api"}, want: doctor.StatusFail},
+		{name: "unknown profile", repo: doctor.RepoInfo{Profile: "old", OwnerRepo: "company-org/api"}, want: doctor.StatusFail},
+		{name: "owner rule disagrees", repo: doctor.RepoInfo{Profile: "personal", OwnerRepo: "company-org/api"}, want: doctor.StatusWarn},
+		{name: "no owner rule", repo: doctor.RepoInfo{Profile: "personal", OwnerRepo: "other/api"}, want: doctor.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := doctor.CheckRepoProfile(cfg, &tt.repo)
+			assert.Equal(t, tt.want, result.Status, result.Message)
+		})
+	}
+}
+
+func TestCheckGuardHook(t *testing.T) {
+	root := t.TempDir()
+	hooks := filepath.Join(root, ".git", "hooks")
+	require.NoError(t, os.MkdirAll(hooks, 0755))
+	fake := testutil.NewFakeCommander()
+	fake.Register("git -C "+root+" config --get core.hooksPath", "", fmt.Errorf("exit 1"))
+
+	result := doctor.CheckGuardHook(context.Background(), fake, root)
+	assert.Equal(t, doctor.StatusFail, result.Status)
+	require.NotNil(t, result.Remedy)
+	require.NoError(t, result.Remedy.Apply(context.Background()))
+	assert.Equal(t, doctor.StatusOK, doctor.CheckGuardHook(context.Background(), fake, root).Status)
+
+	require.NoError(t, os.Chmod(filepath.Join(hooks, "pre-push"), 0644))
+	result = doctor.CheckGuardHook(context.Background(), fake, root)
+	assert.Equal(t, doctor.StatusFail, result.Status)
+	assert.Contains(t, result.Message, "실행 권한")
+}
+
+func TestCheckGuardHook_HooksPathShadows(t *testing.T) {
+	root := t.TempDir()
+	require.NoError(t, guard.InstallHook(root))
+	fake := testutil.NewFakeCommander()
+	fake.Register("git -C "+root+" config --get core.hooksPath", ".githooks\n", nil)
+
+	result := doctor.CheckGuardHook(context.Background(), fake, root)
+	assert.Equal(t, doctor.StatusFail, result.Status)
+	assert.Contains(t, result.Message, filepath.Join(root, ".githooks"))
+	assert.Contains(t, result.Message, "실행되지 않음")
+	assert.Nil(t, result.Remedy, "사용자 hooksPath는 자동 수정하지 않는다")
+}
+
+func TestCheckRepoRemote(t *testing.T) {
+	p := repoConfig().Profiles["work"]
+	assert.Equal(t, doctor.StatusOK, doctor.CheckRepoRemote("git@github.com-work:company-org/api.git", &p, false).Status)
+	assert.Equal(t, doctor.StatusFail, doctor.CheckRepoRemote("git@github.com-personal:company-org/api.git", &p, false).Status)
+	assert.Equal(t, doctor.StatusWarn, doctor.CheckRepoRemote("https://github.com/company-org/api.git", &p, false).Status)
+	assert.Equal(t, doctor.StatusOK, doctor.CheckRepoRemote("https://github.com/company-org/api.git", &p, true).Status)
+}
+
+func TestCheckRepoEmailRules(t *testing.T) {
+	rules := []config.EmailRule{{Name: "signed", Owners: []string{"company-org"}, RequireSigning: true}}
+	fake := testutil.NewFakeCommander()
+	fake.Register("git -C /repo config --get user.email", "hb@company.com\n", nil)
+	fake.Register("git -C /repo config --get commit.gpgsign", "false\n", nil)
+
+	results := doctor.CheckRepoEmailRules(context.Background(), fake, "/repo", "company-org/api", rules)
+	require.Len(t, results, 1)
+	assert.Equal(t, doctor.StatusFail, results[0].Status)
+	assert.Contains(t, results[0].Message, "규칙 signed")
+
+	results = doctor.CheckRepoEmailRules(context.Background(), fake, "/repo", "hbjs97/dotfiles", rules)
+	require.Len(t, results, 1)
+	assert.Equal(t, doctor.StatusOK, results[0].Status)
+}
+
+func TestCheckRepoHints(t *testing.T) {
+	p := repoConfig().Profiles["work"]
+	hints := &config.RepoHints{Path: "/repo/.ctx.toml", Owner: "company-org", EmailDomain: "company.com"}
+
+	results := doctor.CheckRepoHints(hints, "company-org/api", &p, "github.com")
+	require.Len(t, results, 1)
+	assert.Equal(t, doctor.StatusOK, results[0].Status, results[0].Message)
+
+	results = doctor.CheckRepoHints(hints, "hbjs97/dotfiles", &p, "github.com")
+	require.Len(t, results, 1)
+	assert.Equal(t, doctor.StatusFail, results[0].Status)
+	assert.Contains(t, results[0].Message, "repo_owner")
+}
+
+func TestCheckRepoIdentity(t *testing.T) {
+	p := repoConfig().Profiles["work"]
+	fake := testutil.NewFakeCommander()
+	fake.Register("git -C /repo config --show-scope --show-origin --get-all user.email",
+		"global\tfile:/home/u/.gitconfig\thbjs97@example.com\nlocal\tfile:.git/config\thb@company.com\n", nil)
+	fake.Register("git -C /repo config --show-scope --show-origin --get-all user.name",
+		"global\tfile:/home/u/.gitconfig\thbjs97\n", nil)
+	fake.Register("git -C /repo config --local", "", nil)
+
+	results := doctor.CheckRepoIdentity(context.Background(), fake, "/repo", &p)
+	require.Len(t, results, 2)
+	assert.Equal(t, doctor.StatusOK, results[0].Status, results[0].Message)
+	assert.Equal(t, doctor.StatusWarn, results[1].Status, "로컬 user.name 없음")
+
+	require.NotNil(t, results[1].Remedy)
+	require.NoError(t, results[1].Remedy.Apply(context.Background()))
+	assert.True(t, fake.Called("git -C /repo config --local user.name HB Work"))
+}
+
+func TestCheckRepoIdentity_EffectiveOverridden(t *testing.T) {
+	p := repoConfig().Profiles["work"]
+	fake := testutil.NewFakeCommander()
+	fake.Register("git -C /repo config --show-scope --show-origin --get-all user.email",
+		"local\tfile:.git/config\thb@company.com\nworktree\tfile:.git/config.worktree\thbjs97@example.com\n", nil)
+	fake.Register("git -C /repo config --show-scope --show-origin --get-all user.name",
+		"local\tfile:.git/config\tHB Work\n", nil)
+
+	results := doctor.CheckRepoIdentity(context.Background(), fake, "/repo", &p)
+	assert.Equal(t, doctor.StatusFail, results[0].Status)
+	assert.Contains(t, results[0].Message, ".git/config.worktree")
+	assert.Equal(t, doctor.StatusOK, results[1].Status)
+}
+
+func TestCheckShadowedIdentity(t *testing.T) {
+	fake := testutil.NewFakeCommander()
+	fake.Register("git -C /repo config --show-scope --show-origin --get-all user.email",
+		"global\tfile:/home/u/.config/ctx/gitconfig/personal\thbjs97@example.com\nlocal\tfile:.git/config\thb@company.com\n", nil)
+
+	result := doctor.CheckShadowedIdentity(context.Background(), fake, "/repo", "hb@company.com")
+	assert.Equal(t, doctor.StatusWarn, result.Status)
+	assert.Contains(t, result.Message, "gitconfig/personal")
+
+	assert.Equal(t, doctor.StatusOK, doctor.CheckShadowedIdentity(context.Background(), fake, "/repo", "hbjs97@example.com").Status,
+		"로컬 값이 달라도 여기서는 하위 설정만 본다")
+}
+
+func TestCheckRepoCache(t *testing.T) {
+	cfg := repoConfig()
+	now := time.Date(2026, 10, 18, 0, 0, 0, 0, time.UTC)
+	entry := cache.Entry{Profile: "work", Reason: "owner_rule", ResolvedAt: now.Add(-24 * time.Hour).Format(time.RFC3339), ConfigHash: cfg.ConfigHash()}
+
+	c := cache.New()
+	assert.Equal(t, doctor.StatusOK, doctor.CheckRepoCache(c, "company-org/api", "work", cfg, now).Status, "항목 없음")
+
+	c.Set("company-org/api", entry)
+	assert.Equal(t, doctor.StatusOK, doctor.CheckRepoCache(c, "company-org/api", "work", cfg, now).Status)
+	assert.Equal(t, doctor.StatusWarn, doctor.CheckRepoCache(c, "company-org/api", "personal", cfg, now).Status, "프로필 불일치")
+
+	stale := entry
+	stale.ConfigHash = "old"
+	c.Set("company-org/api", stale)
+	assert.Equal(t, doctor.StatusWarn, doctor.CheckRepoCache(c, "company-org/api", "work", cfg, now).Status, "설정 변경")
+
+	expired := entry
+	expired.ResolvedAt = now.Add(-91 * 24 * time.Hour).Format(time.RFC3339)
+	c.Set("company-org/api", expired)
+	assert.Equal(t, doctor.StatusWarn, doctor.CheckRepoCache(c, "company-org/api", "work", cfg, now).Status, "TTL 만료")
+}
+
+func TestCacheEntryRemedy(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cache.json")
+	c := cache.New()
+	c.Set("company-org/api", cache.Entry{Profile: "work"})
+	c.Set("hbjs97/dotfiles", cache.Entry{Profile: "personal"})
+	require.NoError(t, c.Save(path))
+
+	require.NoError(t, doctor.CacheEntryRemedy(path, "company-org/api").Apply(context.Background()))
+
+	c, err := cache.Load(path)
+	require.NoError(t, err)
+	assert.NotContains(t, c.Entries, "company-org/api")
+	assert.Contains(t, c.Entries, "hbjs97/dotfiles")
+}
+
+func TestCheckShellProfile(t *testing.T) {
+	assert.Equal(t, doctor.StatusOK, doctor.CheckShellProfile("work", "work").Status)
+	assert.Equal(t, doctor.StatusWarn, doctor.CheckShellProfile("work", "").Status)
+	assert.Equal(t, doctor.StatusFail, doctor.CheckShellProfile("work", "personal").Status)
+}
@@ -0,0 +1,134 @@
+package doctor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/hbjs97/ctx/internal/config"
+)
+
+const (
+	// DefaultWorkers는 동시에 실행하는 진단 항목 수의 기본값이다.
+	DefaultWorkers = 8
+	// DefaultTimeout은 진단 항목 하나의 기본 제한 시간이다.
+	// 잘못된 Host alias로 ssh -T가 TCP 타임아웃까지 멈추는 것을 막는다.
+	DefaultTimeout = 10 * time.Second
+)
+
+// Task는 실행할 진단 항목과 대상 프로필이다. 전역/리포 항목은 Profile이 비어있다.
+type Task struct {
+	Check   Check
+	Profile string
+}
+
+// TaskResult는 Task 하나의 실행 결과다.
+type TaskResult struct {
+	Task    Task
+	Results []DiagResult
+}
+
+// RunOptions는 RunTasks의 동시성과 제한 시간 설정이다.
+type RunOptions struct {
+	Workers int           // 0 이하이면 DefaultWorkers
+	Timeout time.Duration // 0 이하이면 DefaultTimeout
+}
+
+// Plan은 진단 항목을 실행 순서대로 Task로 펼친다.
+// 전역 항목, 리포 항목, 프로필별 항목(프로필 이름순) 순서이며, cfg가 nil이면 프로필별 항목은 제외한다.
+func Plan(checks []Check, cfg *config.Config) []Task {
+	var global, repo, perProfile []Check
+	for _, c := range checks {
+		switch {
+		case c.Repo:
+			repo = append(repo, c)
+		case c.PerProfile:
+			perProfile = append(perProfile, c)
+		default:
+			global = append(global, c)
+		}
+	}
+
+	var tasks []Task
+	for _, c := range append(global, repo...) {
+		tasks = append(tasks, Task{Check: c})
+	}
+	if cfg == nil {
+		return tasks
+	}
+	names := make([]string, 0, len(cfg.Profiles))
+	for name := range cfg.Profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		for _, c := range perProfile {
+			tasks = append(tasks, Task{Check: c, Profile: name})
+		}
+	}
+	return tasks
+}
+
+// RunTasks는 tasks를 최대 opts.Workers개씩 병렬로 실행하고 결과를 tasks와 같은 순서로 반환한다.
+// Aggregate 항목은 나머지 항목이 모두 끝난 뒤 실행한다.
+// 각 항목은 opts.Timeout이 지나면 취소되고, 끝나지 않은 항목은 FAIL 결과로 대체된다.
+func RunTasks(ctx context.Context, env *Env, tasks []Task, opts RunOptions) []TaskResult {
+	workers := opts.Workers
+	if workers <= 0 {
+		workers = DefaultWorkers
+	}
+	timeout := opts.Timeout
+	if timeout <= 0 {
+		timeout = DefaultTimeout
+	}
+
+	out := make([]TaskResult, len(tasks))
+	sem := make(chan struct{}, workers)
+	// Aggregate 항목은 다른 항목이 모두 끝난 뒤 실행해 그 조회 결과를 재사용한다
+	for _, aggregate := range []bool{false, true} {
+		var wg sync.WaitGroup
+		for i, t := range tasks {
+			if t.Check.Aggregate != aggregate {
+				continue
+			}
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				sem <- struct{}{}
+				defer func() { <-sem }()
+				out[i] = TaskResult{Task: t, Results: runTask(ctx, env, t, timeout)}
+			}()
+		}
+		wg.Wait()
+	}
+	return out
+}
+
+// runTask는 제한 시간 안에 진단 항목 하나를 실행한다.
+// 컨텍스트를 무시하는 항목도 있으므로 제한 시간이 지나면 결과를 기다리지 않는다.
+func runTask(ctx context.Context, env *Env, t Task, timeout time.Duration) []DiagResult {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	done := make(chan []DiagResult, 1) // 제한 시간 후 끝난 goroutine이 막히지 않도록 버퍼를 둔다
+	go func() { done <- t.Check.Run(ctx, env, t.Profile) }()
+
+	select {
+	case results := <-done:
+		return results
+	case <-ctx.Done():
+		msg := fmt.Sprintf("%s 제한 시간(%s) 초과", t.Check.Name, timeout)
+		if !errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			msg = fmt.Sprintf("%s 취소됨", t.Check.Name)
+		}
+		return []DiagResult{{
+			Name:    t.Check.Name,
+			Status:  StatusFail,
+			Message: msg,
+			Fix:     fmt.Sprintf("ctx doctor --check %s --timeout <시간> 으로 재실행하거나 네트워크/SSH Host 설정 확인", t.Check.Name),
+		}}
+	}
+}
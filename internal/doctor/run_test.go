@@ -0,0 +1,84 @@
+package doctor_test
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/hbjs97/ctx/internal/config"
+	"github.com/hbjs97/ctx/internal/doctor"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPlan_Order(t *testing.T) {
+	cfg := &config.Config{Profiles: map[string]config.Profile{"work": {}, "personal": {}}}
+	checks, err := doctor.Select([]string{"binaries", "gh_auth", "ssh", "env_tokens", "repo_remote"})
+	require.NoError(t, err)
+
+	var got []string
+	for _, task := range doctor.Plan(checks, cfg) {
+		got = append(got, task.Check.Name+"@"+task.Profile)
+	}
+	assert.Equal(t, []string{
+		"binaries@", "env_tokens@", "repo_remote@",
+		"gh_auth@personal", "ssh@personal",
+		"gh_auth@work", "ssh@work",
+	}, got, "전역 항목은 한 번만, 프로필은 이름순")
+
+	assert.Len(t, doctor.Plan(checks, nil), 3, "설정이 없으면 프로필별 항목 제외")
+}
+
+func TestRunTasks_StableOrderAndBoundedWorkers(t *testing.T) {
+	var running, peak atomic.Int32
+	check := func(name string, delay time.Duration) doctor.Check {
+		return doctor.Check{Name: name, Run: func(ctx context.Context, env *doctor.Env, _ string) []doctor.DiagResult {
+			n := running.Add(1)
+			for {
+				p := peak.Load()
+				if n <= p || peak.CompareAndSwap(p, n) {
+					break
+				}
+			}
+			time.Sleep(delay)
+			running.Add(-1)
+			return []doctor.DiagResult{{Name: name, Status: doctor.StatusOK}}
+		}}
+	}
+	tasks := []doctor.Task{
+		{Check: check("slow", 30*time.Millisecond)},
+		{Check: check("fast1", 0)},
+		{Check: check("fast2", 0)},
+		{Check: check("fast3", 10*time.Millisecond)},
+	}
+
+	results := doctor.RunTasks(context.Background(), &doctor.Env{}, tasks, doctor.RunOptions{Workers: 2})
+
+	require.Len(t, results, 4)
+	for i, name := range []string{"slow", "fast1", "fast2", "fast3"} {
+		assert.Equal(t, name, results[i].Results[0].Name, "입력 순서 유지")
+	}
+	assert.LessOrEqual(t, peak.Load(), int32(2))
+}
+
+func TestRunTasks_Timeout(t *testing.T) {
+	hang := doctor.Check{Name: "ssh", Run: func(ctx context.Context, env *doctor.Env, _ string) []doctor.DiagResult {
+		time.Sleep(time.Second) // 컨텍스트를 무시하는 항목
+		return []doctor.DiagResult{{Name: "ssh", Status: doctor.StatusOK}}
+	}}
+	ok := doctor.Check{Name: "binaries", Run: func(ctx context.Context, env *doctor.Env, _ string) []doctor.DiagResult {
+		return []doctor.DiagResult{{Name: "binaries", Status: doctor.StatusOK}}
+	}}
+
+	start := time.Now()
+	results := doctor.RunTasks(context.Background(), &doctor.Env{},
+		[]doctor.Task{{Check: hang, Profile: "work"}, {Check: ok}},
+		doctor.RunOptions{Timeout: 20 * time.Millisecond})
+
+	assert.Less(t, time.Since(start), 500*time.Millisecond)
+	require.Len(t, results[0].Results, 1)
+	assert.Equal(t, doctor.StatusFail, results[0].Results[0].Status)
+	assert.Contains(t, results[0].Results[0].Message, "제한 시간")
+	assert.Equal(t, doctor.StatusOK, results[1].Results[0].Status)
+}
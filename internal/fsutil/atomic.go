@@ -0,0 +1,53 @@
+package fsutil
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// WriteFileAtomic은 같은 디렉토리의 임시 파일에 data를 쓰고 fsync한 뒤 path로 rename한다.
+// 읽는 쪽은 이전 내용이나 새 내용 중 하나만 보게 된다. path가 심볼릭 링크면 링크 대상 파일을 교체한다.
+func WriteFileAtomic(path string, data []byte, perm os.FileMode) error {
+	if target, err := filepath.EvalSymlinks(path); err == nil {
+		path = target
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("fsutil.WriteFileAtomic: %w", err)
+	}
+	tmpPath := tmp.Name()
+	defer os.Remove(tmpPath) // rename에 성공하면 이미 없으므로 실패해도 무방
+
+	if _, err := tmp.Write(data); err != nil {
+		_ = tmp.Close() // Write 에러를 우선 반환
+		return fmt.Errorf("fsutil.WriteFileAtomic: %w", err)
+	}
+	if err := tmp.Chmod(perm); err != nil {
+		_ = tmp.Close() // Chmod 에러를 우선 반환
+		return fmt.Errorf("fsutil.WriteFileAtomic: %w", err)
+	}
+	if err := tmp.Sync(); err != nil {
+		_ = tmp.Close() // Sync 에러를 우선 반환
+		return fmt.Errorf("fsutil.WriteFileAtomic: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("fsutil.WriteFileAtomic: %w", err)
+	}
+	if err := os.Rename(tmpPath, path); err != nil {
+		return fmt.Errorf("fsutil.WriteFileAtomic: %w", err)
+	}
+	syncDir(filepath.Dir(path))
+	return nil
+}
+
+// syncDir는 rename이 디스크에 남도록 디렉토리를 fsync한다. 지원하지 않는 플랫폼에서는 무시한다.
+func syncDir(dir string) {
+	d, err := os.Open(dir)
+	if err != nil {
+		return
+	}
+	_ = d.Sync()  // 디렉토리 fsync를 지원하지 않는 파일시스템이 있다
+	_ = d.Close() // 읽기 전용 핸들
+}
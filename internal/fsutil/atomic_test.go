@@ -0,0 +1,48 @@
+package fsutil_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/hbjs97/ctx/internal/fsutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriteFileAtomic(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "state.json")
+	require.NoError(t, os.WriteFile(path, []byte("old"), 0644))
+
+	require.NoError(t, fsutil.WriteFileAtomic(path, []byte("new"), 0600))
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, "new", string(data))
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	assert.Len(t, entries, 1, "임시 파일이 남으면 안 된다")
+}
+
+func TestWriteFileAtomic_FollowsSymlink(t *testing.T) {
+	dir := t.TempDir()
+	target := filepath.Join(dir, "dotfiles", "config.toml")
+	require.NoError(t, os.MkdirAll(filepath.Dir(target), 0700))
+	require.NoError(t, os.WriteFile(target, []byte("old"), 0600))
+	link := filepath.Join(dir, "config.toml")
+	require.NoError(t, os.Symlink(target, link))
+
+	require.NoError(t, fsutil.WriteFileAtomic(link, []byte("new"), 0600))
+
+	info, err := os.Lstat(link)
+	require.NoError(t, err)
+	assert.NotZero(t, info.Mode()&os.ModeSymlink, "심볼릭 링크가 유지되어야 한다")
+	data, err := os.ReadFile(target)
+	require.NoError(t, err)
+	assert.Equal(t, "new", string(data))
+}
@@ -0,0 +1,2 @@
+// Package fsutil provides atomic file writes, advisory file locks and home path expansion for ctx state files.
+package fsutil
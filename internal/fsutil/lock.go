@@ -0,0 +1,38 @@
+package fsutil
+
+import (
+	"fmt"
+	"os"
+)
+
+// FileLock은 Lock으로 잡은 advisory lock이다.
+type FileLock struct {
+	f *os.File
+}
+
+// Lock은 path에 대한 배타적 advisory lock을 잡는다. 다른 프로세스가 잡고 있으면 풀릴 때까지 기다린다.
+// lock은 path 옆의 "<path>.lock" 파일에 걸리므로 path를 rename으로 교체해도 유지된다.
+// 같은 프로세스에서도 Lock 호출마다 새 파일을 열므로 중첩해서 잡으면 교착된다.
+func Lock(path string) (*FileLock, error) {
+	f, err := os.OpenFile(path+".lock", os.O_RDWR|os.O_CREATE, 0600)
+	if err != nil {
+		return nil, fmt.Errorf("fsutil.Lock: %w", err)
+	}
+	if err := lockFile(f); err != nil {
+		_ = f.Close() // lock 에러를 우선 반환
+		return nil, fmt.Errorf("fsutil.Lock: %w", err)
+	}
+	return &FileLock{f: f}, nil
+}
+
+// Unlock은 lock을 푼다.
+func (l *FileLock) Unlock() error {
+	if err := unlockFile(l.f); err != nil {
+		_ = l.f.Close() // unlock 에러를 우선 반환. Close해도 lock은 풀린다.
+		return fmt.Errorf("fsutil.Unlock: %w", err)
+	}
+	if err := l.f.Close(); err != nil {
+		return fmt.Errorf("fsutil.Unlock: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,10 @@
+//go:build !unix
+
+package fsutil
+
+import "os"
+
+// flock이 없는 플랫폼에서는 lock 없이 동작한다. 쓰기는 WriteFileAtomic으로 원자적이다.
+func lockFile(f *os.File) error { return nil }
+
+func unlockFile(f *os.File) error { return nil }
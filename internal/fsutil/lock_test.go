@@ -0,0 +1,41 @@
+//go:build unix
+
+package fsutil_test
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/hbjs97/ctx/internal/fsutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLock_Exclusive(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cache.json")
+
+	first, err := fsutil.Lock(path)
+	require.NoError(t, err)
+
+	acquired := make(chan *fsutil.FileLock)
+	go func() {
+		second, err := fsutil.Lock(path)
+		assert.NoError(t, err)
+		acquired <- second
+	}()
+
+	select {
+	case <-acquired:
+		t.Fatal("두 번째 Lock은 첫 번째 lock이 풀릴 때까지 기다려야 한다")
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	require.NoError(t, first.Unlock())
+	select {
+	case second := <-acquired:
+		require.NoError(t, second.Unlock())
+	case <-time.After(5 * time.Second):
+		t.Fatal("lock이 풀린 뒤에도 두 번째 Lock이 끝나지 않음")
+	}
+}
@@ -0,0 +1,18 @@
+package fsutil
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// ExpandHome은 "~/"로 시작하는 경로를 홈 디렉토리 기준 절대 경로로 바꾼다.
+// 그 외 경로나 홈 디렉토리를 알 수 없는 경우에는 그대로 반환한다.
+func ExpandHome(path string) string {
+	if strings.HasPrefix(path, "~/") {
+		if home, err := os.UserHomeDir(); err == nil {
+			return filepath.Join(home, path[2:])
+		}
+	}
+	return path
+}
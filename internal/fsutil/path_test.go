@@ -0,0 +1,18 @@
+package fsutil_test
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/hbjs97/ctx/internal/fsutil"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExpandHome(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+
+	assert.Equal(t, filepath.Join(home, ".ssh", "id_work"), fsutil.ExpandHome("~/.ssh/id_work"))
+	assert.Equal(t, "/etc/ssh/id", fsutil.ExpandHome("/etc/ssh/id"))
+	assert.Equal(t, "~user/id", fsutil.ExpandHome("~user/id"))
+}
@@ -1,2 +1,3 @@
-// Package gh provides permission probing and auth status queries via the gh CLI.
+// Package gh provides permission probing, auth status and organization membership
+// queries via the gh CLI, and resolves the GitHub login an SSH host alias authenticates as.
 package gh
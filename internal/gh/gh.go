@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"os"
+	"regexp"
 	"strings"
 
 	"github.com/hbjs97/ctx/internal/cmdexec"
@@ -58,6 +59,63 @@ func (a *Adapter) ProbeRepo(ctx context.Context, ghConfigDir, owner, repo string
 	return &ProbeResult{HasAccess: true, CanPush: resp.Permissions.Push}, nil
 }
 
+// Login은 GH_CONFIG_DIR의 gh 인증이 가리키는 GitHub 로그인을 반환한다.
+func (a *Adapter) Login(ctx context.Context, ghConfigDir string) (string, error) {
+	env := SuppressEnvTokens()
+	env["GH_CONFIG_DIR"] = ghConfigDir
+	out, err := a.cmd.RunWithEnv(ctx, env, "gh", "api", "user", "--jq", ".login")
+	if err != nil {
+		return "", fmt.Errorf("gh.Login: %w", err)
+	}
+	login := strings.TrimSpace(string(out))
+	if login == "" {
+		return "", fmt.Errorf("gh.Login: 빈 응답")
+	}
+	return login, nil
+}
+
+// Orgs는 GH_CONFIG_DIR의 gh 인증 사용자가 속한 조직 login 목록을 반환한다.
+// 조직이 없으면 빈 목록이며, 조회 실패와 구분된다.
+func (a *Adapter) Orgs(ctx context.Context, ghConfigDir string) ([]string, error) {
+	env := SuppressEnvTokens()
+	env["GH_CONFIG_DIR"] = ghConfigDir
+	out, err := a.cmd.RunWithEnv(ctx, env, "gh", "api", "user/orgs", "--jq", ".[].login", "--paginate")
+	if err != nil {
+		return nil, fmt.Errorf("gh.Orgs: %w", err)
+	}
+	var orgs []string
+	for line := range strings.SplitSeq(string(out), "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			orgs = append(orgs, line)
+		}
+	}
+	return orgs, nil
+}
+
+// sshGreeting은 ssh -T git@github.com 인사말에서 GitHub 로그인을 추출한다.
+// 예: "Hi octocat! You've successfully authenticated, but GitHub does not provide shell access."
+var sshGreeting = regexp.MustCompile(`Hi ([A-Za-z0-9][A-Za-z0-9-]*)! You've successfully authenticated`)
+
+// ParseSSHLogin은 ssh -T 출력에서 인증된 GitHub 로그인을 찾는다.
+func ParseSSHLogin(output string) (string, bool) {
+	m := sshGreeting.FindStringSubmatch(output)
+	if m == nil {
+		return "", false
+	}
+	return m[1], true
+}
+
+// SSHLogin은 SSH Host로 인증되는 GitHub 로그인을 반환한다.
+// GitHub은 ssh -T 성공 시에도 exit code 1을 반환하므로 출력만으로 판단한다.
+func (a *Adapter) SSHLogin(ctx context.Context, sshHost string) (string, error) {
+	out, _ := a.cmd.Run(ctx, "ssh", "-T", fmt.Sprintf("git@%s", sshHost)) // exit code는 항상 1
+	login, ok := ParseSSHLogin(string(out))
+	if !ok {
+		return "", fmt.Errorf("gh.SSHLogin: %s 인증 실패", sshHost)
+	}
+	return login, nil
+}
+
 // SuppressEnvTokens는 현재 프로세스에 설정된 GH_TOKEN/GITHUB_TOKEN 환경변수를
 // 빈 문자열로 덮어쓰기 위한 env 맵을 반환한다.
 // 토큰이 설정되지 않았으면 해당 키는 맵에 포함되지 않는다.
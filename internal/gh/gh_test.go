@@ -204,6 +204,22 @@ func TestProbeAllProfiles_Error(t *testing.T) {
 	assert.Contains(t, err.Error(), "gh.ProbeAllProfiles[work]:")
 }
 
+func TestOrgs(t *testing.T) {
+	t.Parallel()
+
+	fake := testutil.NewFakeCommander()
+	fake.Register("gh api user/orgs --jq .[].login --paginate", "acme\n\nacme-labs\n", nil)
+	orgs, err := gh.NewAdapter(fake).Orgs(context.Background(), "/tmp/gh-work")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"acme", "acme-labs"}, orgs)
+	assert.Equal(t, "/tmp/gh-work", fake.EnvCalls[0]["GH_CONFIG_DIR"])
+
+	fake = testutil.NewFakeCommander()
+	fake.Register("gh api user/orgs", "", fmt.Errorf("HTTP 401"))
+	_, err = gh.NewAdapter(fake).Orgs(context.Background(), "/tmp/gh-work")
+	assert.Error(t, err, "조회 실패는 빈 목록과 구분")
+}
+
 func TestSuppressEnvTokens_NoTokensSet(t *testing.T) {
 	t.Setenv("GH_TOKEN", "")
 	t.Setenv("GITHUB_TOKEN", "")
@@ -123,3 +123,25 @@ func (a *Adapter) SetRemoteURL(ctx context.Context, repoDir, remoteName, newURL
 	}
 	return nil
 }
+
+// SSHHost는 git@host:owner/repo 형식 remote URL의 host(SSH alias)를 반환한다.
+// SSH 형식이 아니면 빈 문자열을 반환한다.
+func SSHHost(remoteURL string) string {
+	if !strings.HasPrefix(remoteURL, "git@") {
+		return ""
+	}
+	host, _, ok := strings.Cut(strings.TrimPrefix(remoteURL, "git@"), ":")
+	if !ok {
+		return ""
+	}
+	return host
+}
+
+// TopLevel은 dir이 속한 작업 트리의 최상위 경로를 반환한다.
+func (a *Adapter) TopLevel(ctx context.Context, dir string) (string, error) {
+	out, err := a.cmd.Run(ctx, "git", "-C", dir, "rev-parse", "--show-toplevel")
+	if err != nil {
+		return "", fmt.Errorf("git.TopLevel: %w", err)
+	}
+	return strings.TrimSpace(string(out)), nil
+}
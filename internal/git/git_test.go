@@ -108,3 +108,9 @@ func TestAdapter_SetRemoteURL(t *testing.T) {
 	assert.NoError(t, err)
 	assert.True(t, fake.Called("git -C"))
 }
+
+func TestSSHHost(t *testing.T) {
+	assert.Equal(t, "github.com-work", git.SSHHost("git@github.com-work:org/repo.git"))
+	assert.Empty(t, git.SSHHost("https://github.com/org/repo.git"))
+	assert.Empty(t, git.SSHHost("git@broken"))
+}
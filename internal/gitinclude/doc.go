@@ -0,0 +1,2 @@
+// Package gitinclude generates per-profile git config files and the includeIf entries that load them.
+package gitinclude
@@ -0,0 +1,373 @@
+package gitinclude
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/hbjs97/ctx/internal/config"
+	"github.com/hbjs97/ctx/internal/fsutil"
+)
+
+const (
+	blockStartMarker = "# ctx-includeif-start"
+	blockEndMarker   = "# ctx-includeif-end"
+	fileSuffix       = ".gitconfig"
+)
+
+// Include는 전역 git config의 하나의 includeIf 항목이다.
+type Include struct {
+	Condition string // 예: "gitdir:~/work/", "hasconfig:remote.*.url:git@github.com:acme/**"
+	Path      string
+}
+
+// DefaultDir는 프로필별 gitconfig 파일이 저장되는 기본 디렉토리를 반환한다.
+func DefaultDir() string {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(home, ".config", "ctx", "git")
+}
+
+// DefaultGlobalPath는 전역 git config(~/.gitconfig) 경로를 반환한다.
+func DefaultGlobalPath() string {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(home, ".gitconfig")
+}
+
+// ProfilePath는 프로필의 gitconfig 파일 경로를 반환한다.
+func ProfilePath(dir, profileName string) string {
+	return filepath.Join(dir, profileName+fileSuffix)
+}
+
+// Conditions는 프로필의 git_dirs와 owners 규칙에서 includeIf 조건 목록을 만든다.
+// ambiguousOwners에 포함된 owner는 여러 프로필에 걸쳐 있으므로 조건을 만들지 않는다.
+func Conditions(p config.Profile, ambiguousOwners map[string]bool) []string {
+	var conds []string
+	for _, d := range p.GitDirs {
+		if d == "" {
+			continue
+		}
+		if !strings.HasSuffix(d, "/") {
+			d += "/"
+		}
+		conds = append(conds, "gitdir:"+d)
+	}
+	for _, o := range p.EffectiveOwners() {
+		if o == "" || ambiguousOwners[o] {
+			continue
+		}
+		conds = append(conds,
+			fmt.Sprintf("hasconfig:remote.*.url:git@github.com:%s/**", o),
+			fmt.Sprintf("hasconfig:remote.*.url:https://github.com/%s/**", o),
+		)
+		if p.SSHHost != "" && p.SSHHost != "github.com" {
+			conds = append(conds, fmt.Sprintf("hasconfig:remote.*.url:git@%s:%s/**", p.SSHHost, o))
+		}
+	}
+	return conds
+}
+
+// RenderProfile은 프로필의 gitconfig 파일 내용을 생성한다.
+// 값은 #, ;, " 등이 들어가도 그대로 읽히도록 모두 따옴표로 감싼다.
+// identityFile이 비어있으면 core.sshCommand를 생략한다.
+func RenderProfile(profileName string, p config.Profile, identityFile string) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "# Generated by ctx for profile %q — do not edit manually.\n", profileName)
+	b.WriteString("[user]\n")
+	fmt.Fprintf(&b, "\tname = %s\n", gitConfigQuote(p.GitName))
+	fmt.Fprintf(&b, "\temail = %s\n", gitConfigQuote(p.GitEmail))
+	if p.SigningKey != "" {
+		fmt.Fprintf(&b, "\tsigningkey = %s\n", gitConfigQuote(p.SigningKey))
+		b.WriteString("[commit]\n\tgpgsign = true\n")
+	}
+	if identityFile != "" {
+		sshCommand := "ssh -i " + shellQuote(identityFile) + " -o IdentitiesOnly=yes"
+		fmt.Fprintf(&b, "[core]\n\tsshCommand = %s\n", gitConfigQuote(sshCommand))
+	}
+	return b.String()
+}
+
+// RenderBlock은 전역 git config에 삽입할 ctx 관리 includeIf 블록을 생성한다.
+// 프로필 이름순으로 정렬되어 재실행 시에도 동일한 결과를 낸다.
+func RenderBlock(cfg *config.Config, dir string) string {
+	names := make([]string, 0, len(cfg.Profiles))
+	for name := range cfg.Profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	ambiguous := ambiguousOwners(cfg)
+
+	var b strings.Builder
+	b.WriteString(blockStartMarker + "\n")
+	b.WriteString("# Managed by ctx — do not edit this block manually.\n")
+	for _, name := range names {
+		path := ProfilePath(dir, name)
+		for _, cond := range Conditions(cfg.Profiles[name], ambiguous) {
+			fmt.Fprintf(&b, "[includeIf %q]\n\tpath = %s\n", cond, path)
+		}
+	}
+	b.WriteString(blockEndMarker + "\n")
+	return b.String()
+}
+
+// Sync는 프로필별 gitconfig 파일을 재생성하고 전역 git config의 ctx 블록을 갱신한다.
+// 더 이상 존재하지 않는 프로필의 파일은 삭제한다. 동시에 실행된 ctx와 겹치지 않도록 lock을 잡는다.
+// identityFiles는 SSH host alias → IdentityFile 매핑이다.
+func Sync(globalPath, dir string, cfg *config.Config, identityFiles map[string]string) error {
+	lock, err := lockGlobal(globalPath)
+	if err != nil {
+		return fmt.Errorf("gitinclude.Sync: %w", err)
+	}
+	defer lock.Unlock()
+
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return fmt.Errorf("gitinclude.Sync: %w", err)
+	}
+
+	for name, p := range cfg.Profiles {
+		content := RenderProfile(name, p, identityFiles[p.SSHHost])
+		if err := fsutil.WriteFileAtomic(ProfilePath(dir, name), []byte(content), 0600); err != nil {
+			return fmt.Errorf("gitinclude.Sync: %w", err)
+		}
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return fmt.Errorf("gitinclude.Sync: %w", err)
+	}
+	for _, e := range entries {
+		name := strings.TrimSuffix(e.Name(), fileSuffix)
+		if e.IsDir() || name == e.Name() {
+			continue
+		}
+		if _, ok := cfg.Profiles[name]; !ok {
+			if err := os.Remove(filepath.Join(dir, e.Name())); err != nil {
+				return fmt.Errorf("gitinclude.Sync: %w", err)
+			}
+		}
+	}
+
+	return writeBlock(globalPath, RenderBlock(cfg, dir))
+}
+
+// Remove는 전역 git config에서 ctx 관리 블록을 제거한다. 블록이 없으면 아무것도 하지 않는다.
+func Remove(globalPath string) error {
+	lock, err := lockGlobal(globalPath)
+	if err != nil {
+		return fmt.Errorf("gitinclude.Remove: %w", err)
+	}
+	defer lock.Unlock()
+	return writeBlock(globalPath, "")
+}
+
+// lockGlobal은 전역 git config를 고치는 ctx끼리 순서를 맞추는 lock을 잡는다.
+// "<globalPath>.lock"은 git config가 쓰는 lock 파일이라 남겨 두면 git이 실패하므로 다른 이름을 쓴다.
+func lockGlobal(globalPath string) (*fsutil.FileLock, error) {
+	if err := os.MkdirAll(filepath.Dir(globalPath), 0700); err != nil {
+		return nil, err
+	}
+	return fsutil.Lock(globalPath + ".ctx")
+}
+
+// writeBlock은 전역 git config의 마커 블록을 block으로 교체한다.
+// 블록이 없으면 파일 끝에 추가하고, block이 비어있으면 기존 블록을 제거한다.
+// 중간에 중단되어도 파일이 잘리지 않도록 원자적으로 교체하며, 기존 파일 권한은 유지한다.
+func writeBlock(globalPath, block string) error {
+	perm := os.FileMode(0644) // ~/.gitconfig 기본 권한
+	if info, err := os.Stat(globalPath); err == nil {
+		perm = info.Mode().Perm()
+	}
+	data, err := os.ReadFile(globalPath)
+	if err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("gitinclude.writeBlock: %w", err)
+	}
+	content := string(data)
+
+	startIdx := strings.Index(content, blockStartMarker)
+	endIdx := strings.Index(content, blockEndMarker)
+	if startIdx != -1 && endIdx > startIdx {
+		after := content[endIdx+len(blockEndMarker):]
+		after = strings.TrimPrefix(after, "\n")
+		content = content[:startIdx] + block + after
+	} else if block != "" {
+		if content != "" && !strings.HasSuffix(content, "\n") {
+			content += "\n"
+		}
+		content += block
+	} else {
+		return nil
+	}
+
+	if err := fsutil.WriteFileAtomic(globalPath, []byte(content), perm); err != nil {
+		return fmt.Errorf("gitinclude.writeBlock: %w", err)
+	}
+	return nil
+}
+
+// ParseIncludes는 git config 파일에서 includeIf 항목을 추출한다.
+// ctx가 관리하는 블록과 사용자가 직접 작성한 항목을 모두 포함한다.
+// 파일이 없으면 nil을 반환한다.
+func ParseIncludes(path string) []Include {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil
+	}
+	defer f.Close()
+
+	var includes []Include
+	var condition string
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, ";") {
+			continue
+		}
+		if strings.HasPrefix(line, "[") {
+			condition = parseIncludeIfHeader(line)
+			continue
+		}
+		if condition == "" {
+			continue
+		}
+		key, value, ok := splitKeyValue(line)
+		if ok && strings.EqualFold(key, "path") {
+			includes = append(includes, Include{Condition: condition, Path: fsutil.ExpandHome(value)})
+		}
+	}
+	return includes
+}
+
+// ReadIdentity는 gitconfig 파일의 [user] 섹션에서 name, email을 읽는다.
+func ReadIdentity(path string) (name, email string) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", ""
+	}
+	defer f.Close()
+
+	var section string
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, ";") {
+			continue
+		}
+		if strings.HasPrefix(line, "[") {
+			section = strings.ToLower(strings.Trim(line, "[] "))
+			continue
+		}
+		if section != "user" {
+			continue
+		}
+		key, value, ok := splitKeyValue(line)
+		if !ok {
+			continue
+		}
+		switch strings.ToLower(key) {
+		case "name":
+			name = value
+		case "email":
+			email = value
+		}
+	}
+	return name, email
+}
+
+// parseIncludeIfHeader는 `[includeIf "cond"]` 헤더에서 조건을 추출한다. includeIf가 아니면 빈 문자열.
+func parseIncludeIfHeader(line string) string {
+	inner := strings.TrimSpace(strings.TrimSuffix(strings.TrimPrefix(line, "["), "]"))
+	section, sub, ok := strings.Cut(inner, " ")
+	if !ok || !strings.EqualFold(section, "includeIf") {
+		return ""
+	}
+	return strings.Trim(strings.TrimSpace(sub), `"`)
+}
+
+func splitKeyValue(line string) (string, string, bool) {
+	key, value, ok := strings.Cut(line, "=")
+	if !ok {
+		return "", "", false
+	}
+	return strings.TrimSpace(key), parseValue(value), true
+}
+
+// parseValue는 git config 값의 따옴표와 이스케이프를 풀고, 따옴표 밖의 # 또는 ; 주석을 제거한다.
+func parseValue(raw string) string {
+	raw = strings.TrimLeft(raw, " \t")
+	var b strings.Builder
+	inQuote := false
+	pending := "" // 따옴표 밖 공백은 뒤에 값이 이어질 때만 남긴다
+	for i := 0; i < len(raw); i++ {
+		c := raw[i]
+		switch {
+		case c == '\\' && i+1 < len(raw):
+			i++
+			b.WriteString(pending)
+			pending = ""
+			switch raw[i] {
+			case 'n':
+				b.WriteByte('\n')
+			case 't':
+				b.WriteByte('\t')
+			case 'b':
+				b.WriteByte('\b')
+			default:
+				b.WriteByte(raw[i])
+			}
+		case c == '"':
+			b.WriteString(pending)
+			pending = ""
+			inQuote = !inQuote
+		case !inQuote && (c == '#' || c == ';'):
+			return b.String()
+		case !inQuote && (c == ' ' || c == '\t'):
+			pending += string(c)
+		default:
+			b.WriteString(pending)
+			pending = ""
+			b.WriteByte(c)
+		}
+	}
+	return b.String()
+}
+
+// shellQuote는 git이 셸로 실행하는 명령에 넣을 인자를 큰따옴표로 감싼다.
+// ~는 ssh가 -i 경로를 직접 확장하므로 그대로 둔다.
+func shellQuote(arg string) string {
+	return `"` + shellQuoteReplacer.Replace(arg) + `"`
+}
+
+var shellQuoteReplacer = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "$", `\$`, "`", "\\`")
+
+// gitConfigQuote는 git config 값으로 읽히도록 큰따옴표로 감싸고 이스케이프한다.
+func gitConfigQuote(value string) string {
+	return `"` + gitConfigQuoteReplacer.Replace(value) + `"`
+}
+
+var gitConfigQuoteReplacer = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`, "\t", `\t`)
+
+// ambiguousOwners는 둘 이상의 프로필에 등록된 owner 집합을 반환한다.
+func ambiguousOwners(cfg *config.Config) map[string]bool {
+	counts := make(map[string]int)
+	for _, p := range cfg.Profiles {
+		for _, o := range p.EffectiveOwners() {
+			counts[o]++
+		}
+	}
+	result := make(map[string]bool)
+	for o, n := range counts {
+		if n > 1 {
+			result[o] = true
+		}
+	}
+	return result
+}
@@ -0,0 +1,200 @@
+package gitinclude_test
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/hbjs97/ctx/internal/config"
+	"github.com/hbjs97/ctx/internal/gitinclude"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testConfig() *config.Config {
+	return &config.Config{
+		Version: 1,
+		Profiles: map[string]config.Profile{
+			"work": {
+				SSHHost: "github.com-work", GitName: "Work", GitEmail: "w@acme.com",
+				Owners: []string{"acme", "shared"}, GitDirs: []string{"~/work"},
+				SigningKey: "ABCD1234",
+			},
+			"personal": {
+				SSHHost: "github.com-personal", GitName: "Me", GitEmail: "me@example.com",
+				Owners: []string{"me", "shared"},
+			},
+		},
+	}
+}
+
+func TestConditions(t *testing.T) {
+	cfg := testConfig()
+	conds := gitinclude.Conditions(cfg.Profiles["work"], map[string]bool{"shared": true})
+
+	assert.Equal(t, []string{
+		"gitdir:~/work/",
+		"hasconfig:remote.*.url:git@github.com:acme/**",
+		"hasconfig:remote.*.url:https://github.com/acme/**",
+		"hasconfig:remote.*.url:git@github.com-work:acme/**",
+	}, conds)
+}
+
+func TestRenderProfile_SSHCommandQuoting(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "work.gitconfig")
+	identityFile := `/home/u/my keys/id_"work"$1`
+	content := gitinclude.RenderProfile("work", testConfig().Profiles["work"], identityFile)
+	require.NoError(t, os.WriteFile(path, []byte(content), 0600))
+
+	out, err := exec.Command("git", "config", "-f", path, "core.sshCommand").Output()
+	require.NoError(t, err)
+	assert.Equal(t, `ssh -i "/home/u/my keys/id_\"work\"\$1" -o IdentitiesOnly=yes`+"\n", string(out),
+		"git이 읽은 값은 셸이 경로를 인자 하나로 받는 명령이어야 한다")
+}
+
+func TestRenderProfile_QuotesIdentity(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "work.gitconfig")
+	p := testConfig().Profiles["work"]
+	p.GitName = `Kim "K" #1; dev\ops`
+	require.NoError(t, os.WriteFile(path, []byte(gitinclude.RenderProfile("work", p, "")), 0600))
+
+	out, err := exec.Command("git", "config", "-f", path, "user.name").Output()
+	require.NoError(t, err)
+	assert.Equal(t, p.GitName+"\n", string(out))
+
+	name, email := gitinclude.ReadIdentity(path)
+	assert.Equal(t, p.GitName, name)
+	assert.Equal(t, "w@acme.com", email)
+}
+
+func TestReadIdentity_StripsComments(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "gitconfig")
+	require.NoError(t, os.WriteFile(path, []byte("[user]\n\tname = Plain Name  # comment\n\temail = \"a@b.com\" ; trailing\n"), 0600))
+
+	name, email := gitinclude.ReadIdentity(path)
+	assert.Equal(t, "Plain Name", name)
+	assert.Equal(t, "a@b.com", email)
+}
+
+func TestRenderProfile(t *testing.T) {
+	cfg := testConfig()
+
+	content := gitinclude.RenderProfile("work", cfg.Profiles["work"], "~/.ssh/id_ed25519_work")
+	assert.Contains(t, content, `email = "w@acme.com"`)
+	assert.Contains(t, content, `signingkey = "ABCD1234"`)
+	assert.Contains(t, content, "gpgsign = true")
+	assert.Contains(t, content, `sshCommand = "ssh -i \"~/.ssh/id_ed25519_work\" -o IdentitiesOnly=yes"`)
+
+	content = gitinclude.RenderProfile("personal", cfg.Profiles["personal"], "")
+	assert.NotContains(t, content, "sshCommand")
+	assert.NotContains(t, content, "signingkey")
+}
+
+func TestSync_WritesFilesAndBlock(t *testing.T) {
+	dir := t.TempDir()
+	globalPath := filepath.Join(dir, ".gitconfig")
+	includeDir := filepath.Join(dir, "git")
+	require.NoError(t, os.WriteFile(globalPath, []byte("[user]\n\tname = Global\n"), 0644))
+
+	cfg := testConfig()
+	err := gitinclude.Sync(globalPath, includeDir, cfg, map[string]string{"github.com-work": "~/.ssh/id_work"})
+	require.NoError(t, err)
+
+	name, email := gitinclude.ReadIdentity(gitinclude.ProfilePath(includeDir, "work"))
+	assert.Equal(t, "Work", name)
+	assert.Equal(t, "w@acme.com", email)
+
+	data, err := os.ReadFile(globalPath)
+	require.NoError(t, err)
+	content := string(data)
+	assert.True(t, strings.HasPrefix(content, "[user]\n\tname = Global\n"), "기존 내용 보존")
+	assert.Contains(t, content, `[includeIf "gitdir:~/work/"]`)
+	// 여러 프로필에 걸친 owner는 includeIf를 만들지 않는다
+	assert.NotContains(t, content, "shared/**")
+
+	includes := gitinclude.ParseIncludes(globalPath)
+	assert.NotEmpty(t, includes)
+	for _, inc := range includes {
+		assert.True(t, strings.HasPrefix(inc.Path, includeDir))
+	}
+}
+
+func TestSync_KeepsModeAndGitLock(t *testing.T) {
+	dir := t.TempDir()
+	globalPath := filepath.Join(dir, ".gitconfig")
+	require.NoError(t, os.WriteFile(globalPath, []byte("[user]\n\tname = Global\n"), 0600))
+
+	require.NoError(t, gitinclude.Sync(globalPath, filepath.Join(dir, "git"), testConfig(), nil))
+
+	info, err := os.Stat(globalPath)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0600), info.Mode().Perm(), "기존 권한 유지")
+	assert.NoFileExists(t, globalPath+".lock", "git config의 lock 파일을 남기지 않는다")
+}
+
+func TestSync_Idempotent(t *testing.T) {
+	dir := t.TempDir()
+	globalPath := filepath.Join(dir, ".gitconfig")
+	includeDir := filepath.Join(dir, "git")
+	cfg := testConfig()
+
+	require.NoError(t, gitinclude.Sync(globalPath, includeDir, cfg, nil))
+	first, _ := os.ReadFile(globalPath)
+	require.NoError(t, gitinclude.Sync(globalPath, includeDir, cfg, nil))
+	second, _ := os.ReadFile(globalPath)
+
+	assert.Equal(t, string(first), string(second))
+}
+
+func TestSync_RemovesDeletedProfile(t *testing.T) {
+	dir := t.TempDir()
+	globalPath := filepath.Join(dir, ".gitconfig")
+	includeDir := filepath.Join(dir, "git")
+	cfg := testConfig()
+	require.NoError(t, gitinclude.Sync(globalPath, includeDir, cfg, nil))
+
+	delete(cfg.Profiles, "personal")
+	require.NoError(t, gitinclude.Sync(globalPath, includeDir, cfg, nil))
+
+	_, err := os.Stat(gitinclude.ProfilePath(includeDir, "personal"))
+	assert.True(t, os.IsNotExist(err))
+	data, _ := os.ReadFile(globalPath)
+	assert.NotContains(t, string(data), "personal.gitconfig")
+}
+
+func TestRemove(t *testing.T) {
+	dir := t.TempDir()
+	globalPath := filepath.Join(dir, ".gitconfig")
+	require.NoError(t, os.WriteFile(globalPath, []byte("[core]\n\teditor = vim\n"), 0644))
+	require.NoError(t, gitinclude.Sync(globalPath, filepath.Join(dir, "git"), testConfig(), nil))
+
+	require.NoError(t, gitinclude.Remove(globalPath))
+
+	data, _ := os.ReadFile(globalPath)
+	assert.Equal(t, "[core]\n\teditor = vim\n", string(data))
+}
+
+func TestParseIncludes_UserWritten(t *testing.T) {
+	dir := t.TempDir()
+	globalPath := filepath.Join(dir, ".gitconfig")
+	content := `[user]
+	name = Global
+[includeIf "gitdir:~/work/"]
+	path = ~/.gitconfig-work
+[includeIf "hasconfig:remote.*.url:git@github.com:acme/**"]
+	path = /etc/git/acme
+`
+	require.NoError(t, os.WriteFile(globalPath, []byte(content), 0644))
+
+	includes := gitinclude.ParseIncludes(globalPath)
+	require.Len(t, includes, 2)
+	assert.Equal(t, "gitdir:~/work/", includes[0].Condition)
+	assert.Equal(t, "/etc/git/acme", includes[1].Path)
+}
+
+func TestParseIncludes_NoFile(t *testing.T) {
+	assert.Nil(t, gitinclude.ParseIncludes("/nonexistent/.gitconfig"))
+}
@@ -0,0 +1,100 @@
+package guard
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/hbjs97/ctx/internal/fsutil"
+)
+
+const (
+	// DefaultAllowanceTTL은 --for 없이 --once로 만든 허용 토큰의 유효 기간이다.
+	DefaultAllowanceTTL = 15 * time.Minute
+	// MaxAllowanceTTL은 허용 토큰에 지정할 수 있는 최대 유효 기간이다.
+	MaxAllowanceTTL = 24 * time.Hour
+)
+
+// Allowance는 ctx guard allow가 리포에 남기는 우회 허용 토큰이다.
+// guard 검사가 차단할 때만 소비되며, Once면 한 번 쓰고 지운다.
+type Allowance struct {
+	Reason  string    `json:"reason"`
+	Once    bool      `json:"once"`
+	Created time.Time `json:"created"`
+	Expires time.Time `json:"expires"`
+}
+
+// AllowancePath는 리포의 허용 토큰 경로(.git/ctx-guard-allow)를 반환한다.
+func AllowancePath(repoDir string) string {
+	return filepath.Join(repoDir, ".git", "ctx-guard-allow")
+}
+
+// WriteAllowance는 리포에 허용 토큰을 쓴다. 이미 있으면 교체한다.
+func WriteAllowance(repoDir string, a Allowance) error {
+	data, err := json.Marshal(a)
+	if err != nil {
+		return fmt.Errorf("guard.WriteAllowance: %w", err)
+	}
+	if err := fsutil.WriteFileAtomic(AllowancePath(repoDir), data, 0600); err != nil {
+		return fmt.Errorf("guard.WriteAllowance: %w", err)
+	}
+	return nil
+}
+
+// validate는 ctx guard allow가 만들 수 없는 토큰을 거부한다.
+// 직접 작성한 파일로 MaxAllowanceTTL보다 긴 우회를 만들지 못하게 한다.
+func (a Allowance) validate(now time.Time) error {
+	switch {
+	case a.Reason == "":
+		return errors.New("사유 없음")
+	case a.Created.After(now):
+		return fmt.Errorf("생성 시각 %s이 미래", a.Created.Format(time.RFC3339))
+	case a.Expires.Sub(a.Created) > MaxAllowanceTTL:
+		return fmt.Errorf("유효 기간 %s이 최대 %s 초과", a.Expires.Sub(a.Created), MaxAllowanceTTL)
+	}
+	return nil
+}
+
+// ConsumeAllowance는 now에 유효한 허용 토큰을 반환한다. 없거나 만료되었으면 nil이다.
+// Once 토큰과 만료된 토큰은 지운다. 동시에 실행된 hook이 Once 토큰을 두 번 쓰지 않도록 lock을 잡는다.
+// 손상되었거나 ctx guard allow의 제한(사유, MaxAllowanceTTL)을 벗어난 토큰은 지우고 에러를 반환한다.
+func ConsumeAllowance(repoDir string, now time.Time) (*Allowance, error) {
+	path := AllowancePath(repoDir)
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		return nil, nil
+	}
+	lock, err := fsutil.Lock(path)
+	if err != nil {
+		return nil, fmt.Errorf("guard.ConsumeAllowance: %w", err)
+	}
+	defer lock.Unlock()
+
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, nil // lock을 기다리는 동안 다른 hook이 소비했다
+	}
+	if err != nil {
+		return nil, fmt.Errorf("guard.ConsumeAllowance: %w", err)
+	}
+	var a Allowance
+	if err := json.Unmarshal(data, &a); err != nil {
+		_ = os.Remove(path) // 손상된 토큰은 허용하지 않고 지운다
+		return nil, fmt.Errorf("guard.ConsumeAllowance: %s: %w", path, err)
+	}
+	if err := a.validate(now); err != nil {
+		_ = os.Remove(path) // 유효하지 않은 토큰은 허용하지 않고 지운다
+		return nil, fmt.Errorf("guard.ConsumeAllowance: %s: 유효하지 않은 허용 토큰: %w", path, err)
+	}
+	if !now.Before(a.Expires) || a.Once {
+		if err := os.Remove(path); err != nil {
+			return nil, fmt.Errorf("guard.ConsumeAllowance: %w", err)
+		}
+	}
+	if !now.Before(a.Expires) {
+		return nil, nil
+	}
+	return &a, nil
+}
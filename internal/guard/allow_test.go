@@ -0,0 +1,153 @@
+package guard_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/hbjs97/ctx/internal/guard"
+	"github.com/hbjs97/ctx/internal/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func tempRepoDir(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+	require.NoError(t, os.Mkdir(filepath.Join(dir, ".git"), 0755))
+	return dir
+}
+
+func TestConsumeAllowance(t *testing.T) {
+	now := time.Now()
+
+	t.Run("none", func(t *testing.T) {
+		a, err := guard.ConsumeAllowance(tempRepoDir(t), now)
+		require.NoError(t, err)
+		assert.Nil(t, a)
+	})
+
+	t.Run("once is removed after use", func(t *testing.T) {
+		dir := tempRepoDir(t)
+		require.NoError(t, guard.WriteAllowance(dir, guard.Allowance{Reason: "hotfix", Once: true, Created: now, Expires: now.Add(time.Minute)}))
+
+		a, err := guard.ConsumeAllowance(dir, now)
+		require.NoError(t, err)
+		require.NotNil(t, a)
+		assert.Equal(t, "hotfix", a.Reason)
+
+		a, err = guard.ConsumeAllowance(dir, now)
+		require.NoError(t, err)
+		assert.Nil(t, a)
+	})
+
+	t.Run("duration is reusable until expiry", func(t *testing.T) {
+		dir := tempRepoDir(t)
+		require.NoError(t, guard.WriteAllowance(dir, guard.Allowance{Reason: "migration", Created: now, Expires: now.Add(time.Minute)}))
+
+		for range 2 {
+			a, err := guard.ConsumeAllowance(dir, now)
+			require.NoError(t, err)
+			assert.NotNil(t, a)
+		}
+
+		a, err := guard.ConsumeAllowance(dir, now.Add(time.Minute))
+		require.NoError(t, err)
+		assert.Nil(t, a)
+		assert.NoFileExists(t, guard.AllowancePath(dir), "만료된 토큰은 지워야 함")
+	})
+
+	t.Run("corrupt token is rejected and removed", func(t *testing.T) {
+		dir := tempRepoDir(t)
+		require.NoError(t, os.WriteFile(guard.AllowancePath(dir), []byte("{"), 0600))
+
+		_, err := guard.ConsumeAllowance(dir, now)
+		assert.Error(t, err)
+		assert.NoFileExists(t, guard.AllowancePath(dir))
+	})
+}
+
+func TestConsumeAllowance_RejectsForgedToken(t *testing.T) {
+	now := time.Now()
+	tests := []struct {
+		name string
+		a    guard.Allowance
+	}{
+		{"longer than max TTL", guard.Allowance{Reason: "forever", Created: now, Expires: now.Add(guard.MaxAllowanceTTL + time.Minute)}},
+		{"created in the future", guard.Allowance{Reason: "future", Created: now.Add(time.Hour), Expires: now.Add(2 * time.Hour)}},
+		{"no reason", guard.Allowance{Created: now, Expires: now.Add(time.Minute)}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := tempRepoDir(t)
+			require.NoError(t, guard.WriteAllowance(dir, tt.a))
+
+			a, err := guard.ConsumeAllowance(dir, now)
+			assert.Error(t, err)
+			assert.Nil(t, a)
+			assert.NoFileExists(t, guard.AllowancePath(dir))
+		})
+	}
+}
+
+func TestCheckWithOptions_OversizedAllowanceBlocks(t *testing.T) {
+	dir := tempRepoDir(t)
+	require.NoError(t, os.WriteFile(guard.AllowancePath(dir),
+		[]byte(`{"reason":"bypass","once":false,"created":"2020-01-01T00:00:00Z","expires":"2099-01-01T00:00:00Z"}`), 0600))
+
+	fake := testutil.NewFakeCommander()
+	fake.Register("git -C "+dir+" remote get-url origin", "git@github-work:org/repo.git\n", nil)
+	fake.Register("git -C "+dir+" config --local user.name", "Test User\n", nil)
+	fake.Register("git -C "+dir+" config --local user.email", "wrong@email.com\n", nil)
+
+	result, err := guard.CheckWithOptions(context.Background(), dir, testProfile(), fake, guard.Options{DisableEnvBypass: true})
+	assert.Error(t, err, "직접 작성한 장기 토큰으로 우회할 수 없어야 함")
+	assert.True(t, result == nil || !result.Pass)
+	assert.NoFileExists(t, guard.AllowancePath(dir))
+}
+
+func TestCheckWithOptions_Allowance(t *testing.T) {
+	dir := tempRepoDir(t)
+	now := time.Now()
+	require.NoError(t, guard.WriteAllowance(dir, guard.Allowance{Reason: "hotfix", Once: true, Created: now, Expires: now.Add(time.Minute)}))
+
+	fake := testutil.NewFakeCommander()
+	fake.Register("git -C "+dir+" remote get-url origin", "git@github-work:org/repo.git\n", nil)
+	fake.Register("git -C "+dir+" config --local user.name", "Test User\n", nil)
+
+	// 통과하는 검사는 토큰을 소비하지 않는다
+	fake.Register("git -C "+dir+" config --local user.email", "test@company.com\n", nil)
+	result, err := guard.CheckWithOptions(context.Background(), dir, testProfile(), fake, guard.Options{})
+	require.NoError(t, err)
+	assert.True(t, result.Pass)
+	assert.Nil(t, result.Allowance)
+	assert.FileExists(t, guard.AllowancePath(dir))
+
+	fake.Register("git -C "+dir+" config --local user.email", "wrong@email.com\n", nil)
+	result, err = guard.CheckWithOptions(context.Background(), dir, testProfile(), fake, guard.Options{})
+	require.NoError(t, err)
+	assert.True(t, result.Pass)
+	require.NotNil(t, result.Allowance)
+	assert.Equal(t, "hotfix", result.Allowance.Reason)
+	require.Len(t, result.Violations, 1, "허용해도 위반은 남겨야 함")
+	assert.Equal(t, "user_email", result.Violations[0].Field)
+
+	result, err = guard.CheckWithOptions(context.Background(), dir, testProfile(), fake, guard.Options{})
+	require.NoError(t, err)
+	assert.False(t, result.Pass, "--once 토큰은 한 번만 허용")
+}
+
+func TestCheckWithOptions_DisableEnvBypass(t *testing.T) {
+	t.Setenv("CTX_SKIP_GUARD", "1")
+	fake := testutil.NewFakeCommander()
+	fake.Register("git -C /tmp/repo remote get-url origin", "git@github-work:org/repo.git\n", nil)
+	fake.Register("git -C /tmp/repo config --local user.email", "wrong@email.com\n", nil)
+	fake.Register("git -C /tmp/repo config --local user.name", "Test User\n", nil)
+
+	result, err := guard.CheckWithOptions(context.Background(), "/tmp/repo", testProfile(), fake, guard.Options{DisableEnvBypass: true})
+	require.NoError(t, err)
+	assert.False(t, result.Skipped)
+	assert.False(t, result.Pass)
+}
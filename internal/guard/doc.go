@@ -1,2 +1,2 @@
-// Package guard implements the pre-push context integrity check engine.
+// Package guard implements the pre-push and pre-commit context integrity check engine.
 package guard
@@ -0,0 +1,154 @@
+package guard
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/hbjs97/ctx/internal/cmdexec"
+	"github.com/hbjs97/ctx/internal/config"
+)
+
+// ErrPushedCommits는 다시 쓸 범위에 이미 push된 커밋이 있어 rewrite를 거부할 때 반환된다.
+var ErrPushedCommits = errors.New("이미 push된 커밋 포함 — 다시 쓸 수 없음")
+
+// Commit은 rewrite 계획에 포함된 커밋 하나다.
+type Commit struct {
+	SHA            string
+	Parents        []string
+	AuthorName     string
+	AuthorEmail    string
+	CommitterName  string
+	CommitterEmail string
+	Subject        string
+}
+
+// Short는 커밋의 짧은 SHA를 반환한다.
+func (c Commit) Short() string {
+	return shortSHA(c.SHA)
+}
+
+// RewritePlan은 ctx guard fix --rewrite가 다시 쓸 커밋 범위다.
+type RewritePlan struct {
+	Upstream string   // 기준 upstream 브랜치. 없으면 빈 문자열 (모든 remote에 없는 커밋이 대상)
+	Base     string   // rebase 기준 커밋. 비어 있으면 루트 커밋부터 (--root)
+	Commits  []Commit // Base 이후 HEAD까지 다시 쓸 커밋 (오래된 순)
+	Wrong    []Commit // Commits 중 작성자나 커미터가 프로필과 다른 커밋
+}
+
+// logFormat은 필드를 unit separator(0x1f)로 구분한다. 커밋 제목에 어떤 문자가 와도 안전하다.
+const logFormat = "--format=%H%x1f%P%x1f%an%x1f%ae%x1f%cn%x1f%ce%x1f%s"
+
+// PlanRewrite는 HEAD의 push되지 않은 커밋 중 작성자나 커미터가 profile과 다른 커밋을 찾아
+// 가장 오래된 것부터 HEAD까지를 다시 쓸 범위로 정한다. 다시 쓸 커밋이 없으면 Wrong이 비어 있다.
+// upstream이 있으면 upstream..HEAD를 보며, 그 중 다른 remote 브랜치에 이미 push된 커밋이 있으면
+// ErrPushedCommits를 반환한다. 머지 커밋이 있으면 선형 rebase로 보존할 수 없으므로 거부한다.
+func PlanRewrite(ctx context.Context, cmd cmdexec.Commander, repoDir string, profile *config.Profile) (*RewritePlan, error) {
+	plan := &RewritePlan{}
+	rangeArgs := []string{"HEAD", "--not", "--remotes"}
+	if out, err := cmd.Run(ctx, "git", "-C", repoDir, "rev-parse", "--abbrev-ref", "--symbolic-full-name", "@{upstream}"); err == nil {
+		plan.Upstream = strings.TrimSpace(string(out))
+		rangeArgs = []string{plan.Upstream + "..HEAD"}
+	}
+
+	commits, err := logCommits(ctx, cmd, repoDir, rangeArgs...)
+	if err != nil {
+		return nil, fmt.Errorf("guard.PlanRewrite: %w", err)
+	}
+	unpushed, err := logCommits(ctx, cmd, repoDir, "HEAD", "--not", "--remotes")
+	if err != nil {
+		return nil, fmt.Errorf("guard.PlanRewrite: %w", err)
+	}
+	isUnpushed := make(map[string]bool, len(unpushed))
+	for _, c := range unpushed {
+		isUnpushed[c.SHA] = true
+	}
+
+	first := -1
+	for i, c := range commits {
+		if c.AuthorName != profile.GitName || c.AuthorEmail != profile.GitEmail ||
+			c.CommitterName != profile.GitName || c.CommitterEmail != profile.GitEmail {
+			if first < 0 {
+				first = i
+			}
+			plan.Wrong = append(plan.Wrong, c)
+		}
+	}
+	if first < 0 {
+		return plan, nil
+	}
+	plan.Commits = commits[first:]
+
+	for _, c := range plan.Commits {
+		if !isUnpushed[c.SHA] {
+			return nil, fmt.Errorf("guard.PlanRewrite: %s %s: %w", c.Short(), c.Subject, ErrPushedCommits)
+		}
+		if len(c.Parents) > 1 {
+			return nil, fmt.Errorf("guard.PlanRewrite: 머지 커밋 %s 포함 — 직접 rebase 필요", c.Short())
+		}
+	}
+	if parents := plan.Commits[0].Parents; len(parents) > 0 {
+		plan.Base = parents[0]
+	}
+	return plan, nil
+}
+
+// logCommits는 git log 범위의 커밋을 오래된 순으로 반환한다.
+func logCommits(ctx context.Context, cmd cmdexec.Commander, repoDir string, rangeArgs ...string) ([]Commit, error) {
+	args := append([]string{"-C", repoDir, "log", "--reverse", logFormat}, rangeArgs...)
+	out, err := cmd.Run(ctx, "git", args...)
+	if err != nil {
+		return nil, fmt.Errorf("git log: %s: %w", strings.TrimSpace(string(out)), err)
+	}
+	var commits []Commit
+	for line := range strings.SplitSeq(strings.TrimSpace(string(out)), "\n") {
+		f := strings.Split(line, "\x1f")
+		if len(f) != 7 {
+			continue
+		}
+		commits = append(commits, Commit{
+			SHA: f[0], Parents: strings.Fields(f[1]),
+			AuthorName: f[2], AuthorEmail: f[3],
+			CommitterName: f[4], CommitterEmail: f[5],
+			Subject: f[6],
+		})
+	}
+	return commits, nil
+}
+
+// Rewrite는 plan의 커밋을 profile의 신원으로 다시 쓴다.
+// git rebase --exec로 커밋마다 작성자가 다르면 commit --amend --reset-author를 실행하고,
+// 커미터는 rebase가 profile의 신원으로 새로 기록한다. 작업 트리에 커밋하지 않은 변경이 있으면 거부한다.
+// rebase가 실패하면 rebase --abort로 원래 상태로 되돌린다.
+func Rewrite(ctx context.Context, cmd cmdexec.Commander, repoDir string, plan *RewritePlan, profile *config.Profile) error {
+	if len(plan.Commits) == 0 {
+		return nil
+	}
+	out, err := cmd.Run(ctx, "git", "-C", repoDir, "status", "--porcelain", "--untracked-files=no")
+	if err != nil {
+		return fmt.Errorf("guard.Rewrite: %w", err)
+	}
+	if strings.TrimSpace(string(out)) != "" {
+		return fmt.Errorf("guard.Rewrite: 커밋하지 않은 변경이 있음 — commit 또는 stash 후 다시 실행")
+	}
+
+	// 이름과 이메일은 셸 인용 문제를 피하려고 환경변수로 넘긴다
+	const execCmd = `test "$(git log -1 --format=%an)" = "$CTX_FIX_NAME" && test "$(git log -1 --format=%ae)" = "$CTX_FIX_EMAIL" || git commit --amend --no-edit --no-verify --allow-empty --reset-author`
+	env := map[string]string{"CTX_FIX_NAME": profile.GitName, "CTX_FIX_EMAIL": profile.GitEmail}
+	args := []string{
+		"-C", repoDir,
+		"-c", "user.name=" + profile.GitName, "-c", "user.email=" + profile.GitEmail,
+		"rebase", "--no-autosquash", "--exec", execCmd,
+	}
+	if plan.Base == "" {
+		args = append(args, "--root")
+	} else {
+		args = append(args, plan.Base)
+	}
+	if out, err := cmd.RunWithEnv(ctx, env, "git", args...); err != nil {
+		_, _ = cmd.Run(ctx, "git", "-C", repoDir, "rebase", "--abort") // 원래 브랜치로 복구. 실패하면 사용자가 직접 처리
+		return fmt.Errorf("guard.Rewrite: rebase 실패: %s: %w", strings.TrimSpace(string(out)), err)
+	}
+	return nil
+}
@@ -0,0 +1,109 @@
+package guard_test
+
+import (
+	"context"
+	"errors"
+	"os/exec"
+	"strings"
+	"testing"
+
+	"github.com/hbjs97/ctx/internal/cmdexec"
+	"github.com/hbjs97/ctx/internal/config"
+	"github.com/hbjs97/ctx/internal/guard"
+	"github.com/hbjs97/ctx/internal/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func gitRun(t *testing.T, dir string, args ...string) string {
+	t.Helper()
+	cmd := exec.Command("git", append([]string{"-C", dir, "-c", "commit.gpgsign=false"}, args...)...)
+	out, err := cmd.CombinedOutput()
+	require.NoError(t, err, "git %v: %s", args, out)
+	return strings.TrimSpace(string(out))
+}
+
+func commitAs(t *testing.T, dir, name, email, msg string) {
+	t.Helper()
+	gitRun(t, dir, "-c", "user.name="+name, "-c", "user.email="+email, "commit", "--allow-empty", "-m", msg)
+}
+
+// repoWithPushedCommit은 bare remote에 커밋 하나를 push하고 upstream을 설정한 리포를 만든다.
+func repoWithPushedCommit(t *testing.T) string {
+	t.Helper()
+	dir := testutil.TempGitRepoWithRemote(t, testutil.TempBareRepo(t))
+	commitAs(t, dir, "Test User", "test@company.com", "initial")
+	gitRun(t, dir, "push", "-u", "origin", "HEAD:refs/heads/main")
+	gitRun(t, dir, "branch", "--set-upstream-to=origin/main")
+	return dir
+}
+
+func TestPlanRewrite_AndRewrite(t *testing.T) {
+	dir := repoWithPushedCommit(t)
+	commitAs(t, dir, "Test User", "test@company.com", "ok before")
+	commitAs(t, dir, "Me", "me@personal.com", "wrong one")
+	commitAs(t, dir, "Test User", "test@company.com", "ok after")
+
+	ctx := context.Background()
+	cmd := &cmdexec.RealCommander{}
+	profile := testProfile()
+
+	plan, err := guard.PlanRewrite(ctx, cmd, dir, profile)
+	require.NoError(t, err)
+	assert.Equal(t, "origin/main", plan.Upstream)
+	require.Len(t, plan.Wrong, 1)
+	assert.Equal(t, "wrong one", plan.Wrong[0].Subject)
+	require.Len(t, plan.Commits, 2, "가장 오래된 잘못된 커밋부터 HEAD까지")
+	assert.Equal(t, gitRun(t, dir, "rev-parse", "HEAD~2"), plan.Base)
+
+	beforeOK := gitRun(t, dir, "rev-parse", "HEAD~2")
+	require.NoError(t, guard.Rewrite(ctx, cmd, dir, plan, profile))
+
+	log := gitRun(t, dir, "log", "--format=%an <%ae>|%cn <%ce>|%s", "origin/main..HEAD")
+	for line := range strings.SplitSeq(log, "\n") {
+		assert.True(t, strings.HasPrefix(line, "Test User <test@company.com>|Test User <test@company.com>|"), line)
+	}
+	assert.Equal(t, beforeOK, gitRun(t, dir, "rev-parse", "HEAD~2"), "범위 앞의 커밋은 그대로")
+
+	plan, err = guard.PlanRewrite(ctx, cmd, dir, profile)
+	require.NoError(t, err)
+	assert.Empty(t, plan.Wrong)
+}
+
+func TestPlanRewrite_RefusesPushedCommits(t *testing.T) {
+	dir := repoWithPushedCommit(t)
+	commitAs(t, dir, "Me", "me@personal.com", "wrong and pushed elsewhere")
+	gitRun(t, dir, "push", "origin", "HEAD:refs/heads/feature")
+	gitRun(t, dir, "fetch", "origin")
+
+	_, err := guard.PlanRewrite(context.Background(), &cmdexec.RealCommander{}, dir, testProfile())
+	require.Error(t, err)
+	assert.True(t, errors.Is(err, guard.ErrPushedCommits))
+}
+
+func TestPlanRewrite_NoUpstream(t *testing.T) {
+	dir := testutil.TempGitRepo(t)
+	commitAs(t, dir, "Me", "me@personal.com", "root")
+	commitAs(t, dir, "Test User", "test@company.com", "second")
+
+	ctx := context.Background()
+	cmd := &cmdexec.RealCommander{}
+	plan, err := guard.PlanRewrite(ctx, cmd, dir, testProfile())
+	require.NoError(t, err)
+	assert.Empty(t, plan.Upstream)
+	assert.Empty(t, plan.Base, "루트 커밋부터 다시 씀")
+	require.Len(t, plan.Commits, 2)
+
+	require.NoError(t, guard.Rewrite(ctx, cmd, dir, plan, testProfile()))
+	assert.Equal(t, "test@company.com", gitRun(t, dir, "log", "-1", "--format=%ae", "HEAD~1"))
+}
+
+func TestRewrite_RefusesDirtyTree(t *testing.T) {
+	fake := testutil.NewFakeCommander()
+	fake.Register("git -C /tmp/repo status --porcelain", " M main.go\n", nil)
+
+	plan := &guard.RewritePlan{Commits: []guard.Commit{{SHA: "abc"}}}
+	err := guard.Rewrite(context.Background(), fake, "/tmp/repo", plan, &config.Profile{GitName: "n", GitEmail: "e"})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "커밋하지 않은 변경")
+}
@@ -1,20 +1,29 @@
 package guard
 
 import (
+	"bufio"
 	"context"
 	"errors"
 	"fmt"
+	"io"
 	"os"
+	"path"
 	"path/filepath"
+	"slices"
 	"strings"
+	"time"
 
 	"github.com/hbjs97/ctx/internal/cmdexec"
 	"github.com/hbjs97/ctx/internal/config"
+	"github.com/hbjs97/ctx/internal/git"
 )
 
 // ErrGuardBlock는 guard 검사 실패로 push가 차단될 때 반환된다.
 var ErrGuardBlock = errors.New("guard 검사 실패 — push 차단")
 
+// ErrCommitBlock은 pre-commit guard 검사 실패로 커밋이 차단될 때 반환된다.
+var ErrCommitBlock = errors.New("guard 검사 실패 — 커밋 차단")
+
 const (
 	hookStartMarker = "# ctx-guard-start"
 	hookEndMarker   = "# ctx-guard-end"
@@ -24,54 +33,142 @@ if ! command -v ctx >/dev/null 2>&1; then
   echo "ctx: command not found — skipping guard check" >&2
   exit 0
 fi
-ctx guard check || exit 1
+ctx guard check --pre-push "$@" || exit 1
+# ctx-guard-end`
+	commitHookScript = `# ctx-guard-start
+# Installed by ctx — do not edit this block manually.
+if ! command -v ctx >/dev/null 2>&1; then
+  echo "ctx: command not found — skipping guard check" >&2
+  exit 0
+fi
+ctx guard check --stage commit || exit 1
 # ctx-guard-end`
 )
 
+// guard 검사 단계.
+const (
+	StagePush   = "push"   // pre-push hook. 모든 검사
+	StageCommit = "commit" // pre-commit hook. 커밋 작성자 신원만 검사
+)
+
+// HookMarker는 hook 파일에 guard 스크립트가 설치되었는지 판별하는 표식이다.
+const HookMarker = hookStartMarker
+
 // CheckResult는 guard 검사 결과다.
 type CheckResult struct {
 	Pass       bool
 	Skipped    bool
+	Remote     string     // 검사한 remote URL. 우회했으면 빈 문자열
+	Allowance  *Allowance // 차단을 허용 토큰으로 통과시켰으면 소비한 토큰. Violations는 그대로 남는다
 	Violations []Violation
 }
 
 // Violation은 검사 위반 항목이다.
 type Violation struct {
-	Field    string // "remote_host", "user_email", "user_name"
+	Field    string // "remote_host", "ssh_identity", "https_remote", "user_email", "user_name", "remote_owner", "protected_branch", "force_push", "repo_host", "repo_owner", "email_domain", "email_denied", "email_not_allowed", "commit_signing"
 	Expected string
 	Actual   string
 	Severity string // "error", "warning"
+	Rule     string // 위반을 낳은 email_rules 규칙 이름. 프로필 검사면 빈 문자열
+}
+
+// Options는 프로필 외에 guard가 적용할 기대값이다.
+type Options struct {
+	Hints      *config.RepoHints // 리포 .ctx.toml
+	HostName   string            // 프로필 ssh_host의 실제 HostName (Hints.Host와 비교)
+	EmailRules []config.EmailRule
+	Checks     config.GuardChecks // 검사별 수준. 빈 항목은 config.DefaultGuardChecks를 따른다
+	Push       *Push              // pre-push hook이 전달한 push 정보. 없으면 ref 검사를 건너뛴다
+	// DisableEnvBypass면 CTX_SKIP_GUARD=1을 무시하고 검사한다 (정책 allow_env_bypass = false).
+	DisableEnvBypass bool
+	Stage            string   // StagePush(빈 값) 또는 StageCommit
+	Identity         Identity // ssh_identity 검사의 캐시 설정
+}
+
+// Push는 pre-push hook이 인자와 stdin으로 전달한 push 정보다.
+type Push struct {
+	Remote  string // remote 이름 (hook의 $1)
+	URL     string // push 대상 URL (hook의 $2)
+	Updates []PushUpdate
+}
+
+// PushUpdate는 push되는 ref 하나다. 삭제면 LocalSHA가, 새 ref면 RemoteSHA가 0으로만 이루어진다.
+type PushUpdate struct {
+	LocalRef  string
+	LocalSHA  string
+	RemoteRef string
+	RemoteSHA string
+}
+
+// ParsePushUpdates는 pre-push hook stdin의 "<local ref> <local sha> <remote ref> <remote sha>" 줄을 파싱한다.
+func ParsePushUpdates(r io.Reader) ([]PushUpdate, error) {
+	var updates []PushUpdate
+	sc := bufio.NewScanner(r)
+	for sc.Scan() {
+		fields := strings.Fields(sc.Text())
+		if len(fields) == 0 {
+			continue
+		}
+		if len(fields) != 4 {
+			return nil, fmt.Errorf("guard.ParsePushUpdates: 알 수 없는 형식 %q", sc.Text())
+		}
+		updates = append(updates, PushUpdate{LocalRef: fields[0], LocalSHA: fields[1], RemoteRef: fields[2], RemoteSHA: fields[3]})
+	}
+	if err := sc.Err(); err != nil {
+		return nil, fmt.Errorf("guard.ParsePushUpdates: %w", err)
+	}
+	return updates, nil
 }
 
 // Check는 리포의 컨텍스트 무결성을 검사한다.
 func Check(ctx context.Context, repoDir string, profile *config.Profile, cmd cmdexec.Commander) (*CheckResult, error) {
+	return CheckWithOptions(ctx, repoDir, profile, cmd, Options{})
+}
+
+// CheckWithOptions는 Check에 더해 리포 힌트, email_rules와 push되는 ref를 검사한다.
+// 프로필 검사는 opts.Checks의 수준에 따라 건너뛰거나(off) 경고하거나(warn) 차단한다(block).
+// 리포 힌트와 email_rules는 항상 차단하며, 프로필과 무관하게 리포에 설정된 실제 user.email에 적용된다.
+// 차단할 위반이 있어도 유효한 허용 토큰(ctx guard allow)이 있으면 소비하고 통과시킨다.
+func CheckWithOptions(ctx context.Context, repoDir string, profile *config.Profile, cmd cmdexec.Commander, opts Options) (*CheckResult, error) {
 	// CTX_SKIP_GUARD 환경변수로 우회
 	if os.Getenv("CTX_SKIP_GUARD") == "1" {
-		fmt.Fprintln(os.Stderr, "경고: CTX_SKIP_GUARD=1 — guard 검사를 건너뜁니다")
-		return &CheckResult{Pass: true, Skipped: true}, nil
+		if !opts.DisableEnvBypass {
+			fmt.Fprintln(os.Stderr, "경고: CTX_SKIP_GUARD=1 — guard 검사를 건너뜁니다")
+			return &CheckResult{Pass: true, Skipped: true}, nil
+		}
+		fmt.Fprintln(os.Stderr, "경고: CTX_SKIP_GUARD는 정책으로 비활성화됨 — ctx guard allow로 우회하세요")
 	}
 
+	checks := config.DefaultGuardChecks().Merge(opts.Checks)
+	if opts.Stage == StageCommit {
+		return checkCommit(ctx, repoDir, profile, cmd, opts, checks)
+	}
 	result := &CheckResult{Pass: true}
+	report := result.report
 
-	// Remote host 검사
-	remoteOut, err := cmd.Run(ctx, "git", "-C", repoDir, "remote", "get-url", "origin")
-	if err != nil {
-		return nil, fmt.Errorf("guard.Check: %w", err)
+	// Remote 검사. hook이 push 대상 URL을 넘겨주면 origin 대신 그 URL을 검사한다.
+	var remoteURL string
+	if opts.Push != nil && opts.Push.URL != "" {
+		remoteURL = opts.Push.URL
+	} else {
+		remoteOut, err := cmd.Run(ctx, "git", "-C", repoDir, "remote", "get-url", "origin")
+		if err != nil {
+			return nil, fmt.Errorf("guard.Check: %w", err)
+		}
+		remoteURL = strings.TrimSpace(string(remoteOut))
 	}
-	remoteURL := strings.TrimSpace(string(remoteOut))
-	if profile.SSHHost != "" && strings.HasPrefix(remoteURL, "git@") {
-		hostPart := strings.TrimPrefix(remoteURL, "git@")
-		if idx := strings.Index(hostPart, ":"); idx > 0 {
-			actualHost := hostPart[:idx]
-			if actualHost != profile.SSHHost {
-				result.Pass = false
-				result.Violations = append(result.Violations, Violation{
-					Field: "remote_host", Expected: profile.SSHHost,
-					Actual: actualHost, Severity: "error",
-				})
-			}
+	result.Remote = remoteURL
+	if actualHost := git.SSHHost(remoteURL); profile.SSHHost != "" && actualHost != "" && actualHost != profile.SSHHost {
+		report(checks.RemoteHost, Violation{Field: "remote_host", Expected: profile.SSHHost, Actual: actualHost})
+	}
+	if actualHost := git.SSHHost(remoteURL); actualHost != "" && checks.SSHIdentity != config.GuardOff {
+		if v := CheckSSHIdentity(ctx, cmd, actualHost, profile.GHConfigDir, opts.Identity, time.Now()); v != nil {
+			report(checks.SSHIdentity, *v)
 		}
 	}
+	if git.IsHTTPSRemote(remoteURL) {
+		report(checks.HTTPSRemote, Violation{Field: "https_remote", Expected: "SSH remote (git@" + profile.SSHHost + ":...)", Actual: remoteURL})
+	}
 
 	// Email 검사
 	emailOut, err := cmd.Run(ctx, "git", "-C", repoDir, "config", "--local", "user.email")
@@ -80,65 +177,304 @@ func Check(ctx context.Context, repoDir string, profile *config.Profile, cmd cmd
 	}
 	actualEmail := strings.TrimSpace(string(emailOut))
 	if actualEmail != profile.GitEmail {
-		result.Pass = false
-		result.Violations = append(result.Violations, Violation{
-			Field: "user_email", Expected: profile.GitEmail,
-			Actual: actualEmail, Severity: "error",
-		})
+		report(checks.UserEmail, Violation{Field: "user_email", Expected: profile.GitEmail, Actual: actualEmail})
 	}
 
-	// Name 검사 (warning only)
+	// Name 검사 (기본은 warning)
 	nameOut, err := cmd.Run(ctx, "git", "-C", repoDir, "config", "--local", "user.name")
 	if err != nil {
 		return nil, fmt.Errorf("guard.Check: %w", err)
 	}
 	actualName := strings.TrimSpace(string(nameOut))
 	if actualName != profile.GitName {
-		result.Violations = append(result.Violations, Violation{
-			Field: "user_name", Expected: profile.GitName,
-			Actual: actualName, Severity: "warning",
-		})
+		report(checks.UserName, Violation{Field: "user_name", Expected: profile.GitName, Actual: actualName})
+	}
+
+	var ownerRepo string
+	if ref, err := git.ParseRepoURL(remoteURL); err == nil {
+		ownerRepo = ref.Owner + "/" + ref.Repo
+	}
+	owner, _, _ := strings.Cut(ownerRepo, "/")
+	owners := profile.EffectiveOwners()
+	if owner != "" && len(profile.Owners) > 0 && !slices.ContainsFunc(owners, func(o string) bool { return strings.EqualFold(o, owner) }) {
+		report(checks.RemoteOwner, Violation{Field: "remote_owner", Expected: strings.Join(owners, ", "), Actual: owner})
+	}
+
+	if opts.Push != nil {
+		for _, u := range opts.Push.Updates {
+			branch, isBranch := strings.CutPrefix(u.RemoteRef, "refs/heads/")
+			if !isBranch {
+				continue
+			}
+			for _, pattern := range profile.ProtectedBranches {
+				if ok, _ := path.Match(pattern, branch); ok {
+					report(checks.ProtectedBranch, Violation{Field: "protected_branch", Expected: "직접 push 금지 (" + pattern + ")", Actual: branch})
+					break
+				}
+			}
+			if checks.ForcePush != config.GuardOff && isForcePush(ctx, cmd, repoDir, u) {
+				report(checks.ForcePush, Violation{Field: "force_push", Expected: "fast-forward", Actual: fmt.Sprintf("%s (%s → %s)", branch, shortSHA(u.RemoteSHA), shortSHA(u.LocalSHA))})
+			}
+		}
+	}
+
+	extra := CheckRepoHints(opts.Hints, ownerRepo, actualEmail, opts.HostName)
+	if opts.Push != nil {
+		// push할 커밋은 이미 만들어졌으므로 현재 설정이 아니라 커밋 자체를 검사한다
+		extra = append(extra, CheckPushedCommits(ctx, cmd, repoDir, opts.EmailRules, ownerRepo, opts.Push.Updates)...)
+	} else {
+		extra = append(extra, CheckEmailRules(ctx, cmd, repoDir, opts.EmailRules, ownerRepo, actualEmail)...)
+	}
+	for _, v := range extra {
+		report(config.GuardBlock, v)
+	}
+
+	// 차단될 때만 허용 토큰을 소비한다. 통과하는 push가 --once 토큰을 써 버리지 않게 한다.
+	if !result.Pass {
+		allowance, err := ConsumeAllowance(repoDir, time.Now())
+		if err != nil {
+			return nil, fmt.Errorf("guard.Check: %w", err)
+		}
+		if allowance != nil {
+			result.Pass = true
+			result.Allowance = allowance
+		}
 	}
 
 	return result, nil
 }
 
+// checkCommit은 pre-commit에서 커밋 작성자 신원만 빠르게 검사한다.
+// git이 커밋에 쓰는 유효한 user.email/user.name을 프로필, email_rules, .ctx.toml의 email_domain과 비교한다.
+// remote 검사와 push ref 검사는 하지 않으며, origin은 email_rules의 owner 범위를 정하는 데만 쓴다.
+// 허용 토큰은 push용이므로 소비하지 않는다.
+func checkCommit(ctx context.Context, repoDir string, profile *config.Profile, cmd cmdexec.Commander, opts Options, checks config.GuardChecks) (*CheckResult, error) {
+	result := &CheckResult{Pass: true}
+
+	// 설정되지 않은 키는 git이 exit 1로 끝나므로 에러를 빈 값으로 본다
+	emailOut, _ := cmd.Run(ctx, "git", "-C", repoDir, "config", "user.email")
+	email := strings.TrimSpace(string(emailOut))
+	if email != profile.GitEmail {
+		result.report(checks.UserEmail, Violation{Field: "user_email", Expected: profile.GitEmail, Actual: email})
+	}
+	nameOut, _ := cmd.Run(ctx, "git", "-C", repoDir, "config", "user.name")
+	if name := strings.TrimSpace(string(nameOut)); name != profile.GitName {
+		result.report(checks.UserName, Violation{Field: "user_name", Expected: profile.GitName, Actual: name})
+	}
+
+	var ownerRepo string
+	if out, err := cmd.Run(ctx, "git", "-C", repoDir, "remote", "get-url", "origin"); err == nil {
+		if ref, err := git.ParseRepoURL(strings.TrimSpace(string(out))); err == nil {
+			ownerRepo = ref.Owner + "/" + ref.Repo
+		}
+	}
+	var extra []Violation
+	if opts.Hints != nil && opts.Hints.EmailDomain != "" {
+		extra = CheckRepoHints(&config.RepoHints{EmailDomain: opts.Hints.EmailDomain}, ownerRepo, email, "")
+	}
+	extra = append(extra, CheckEmailRules(ctx, cmd, repoDir, opts.EmailRules, ownerRepo, email)...)
+	for _, v := range extra {
+		result.report(config.GuardBlock, v)
+	}
+	return result, nil
+}
+
+// report는 level에 따라 위반을 건너뛰거나(off) 경고로(warn) 또는 차단으로(block) 기록한다.
+func (r *CheckResult) report(level string, v Violation) {
+	switch level {
+	case config.GuardBlock:
+		v.Severity = "error"
+		r.Pass = false
+	case config.GuardWarn:
+		v.Severity = "warning"
+	default:
+		return
+	}
+	r.Violations = append(r.Violations, v)
+}
+
+// isForcePush는 원격 커밋이 push할 커밋의 조상이 아니면 true를 반환한다.
+// 원격 커밋이 로컬에 없어도 조상일 수 없으므로 force push로 본다.
+func isForcePush(ctx context.Context, cmd cmdexec.Commander, repoDir string, u PushUpdate) bool {
+	if isZeroSHA(u.LocalSHA) || isZeroSHA(u.RemoteSHA) {
+		return false // 삭제나 새 브랜치는 원격 커밋을 덮어쓰지 않는다
+	}
+	_, err := cmd.Run(ctx, "git", "-C", repoDir, "merge-base", "--is-ancestor", u.RemoteSHA, u.LocalSHA)
+	return err != nil
+}
+
+func isZeroSHA(sha string) bool {
+	return strings.Trim(sha, "0") == ""
+}
+
+func shortSHA(sha string) string {
+	if len(sha) > 7 {
+		return sha[:7]
+	}
+	return sha
+}
+
+// CheckEmailRules는 ownerRepo에 적용되는 email_rules를 email에 대해 평가한다.
+// deny 패턴에 맞거나, allow가 있는데 어느 것에도 맞지 않으면 위반이다.
+// require_signing 규칙이 적용되면 리포의 유효한 commit.gpgsign이 true인지 확인한다.
+func CheckEmailRules(ctx context.Context, cmd cmdexec.Commander, repoDir string, rules []config.EmailRule, ownerRepo, email string) []Violation {
+	owner, _, _ := strings.Cut(ownerRepo, "/")
+	var violations []Violation
+	var signing *string
+	for i, r := range rules {
+		if !r.AppliesTo(owner) {
+			continue
+		}
+		label := r.Label(i)
+		if v := emailRuleViolation(r, label, email); v != nil {
+			violations = append(violations, *v)
+		}
+
+		if !r.RequireSigning {
+			continue
+		}
+		if signing == nil {
+			// 설정되지 않은 키는 git이 exit 1로 끝나므로 에러를 빈 값으로 본다
+			out, _ := cmd.Run(ctx, "git", "-C", repoDir, "config", "--get", "commit.gpgsign")
+			v := strings.TrimSpace(string(out))
+			signing = &v
+		}
+		if !strings.EqualFold(*signing, "true") {
+			actual := *signing
+			if actual == "" {
+				actual = "(없음)"
+			}
+			violations = append(violations, Violation{
+				Field: "commit_signing", Expected: "commit.gpgsign=true",
+				Actual: actual, Severity: "error", Rule: label,
+			})
+		}
+	}
+	return violations
+}
+
+// CheckRepoHints는 리포의 .ctx.toml 기대값과 현재 checkout을 비교한다.
+// ownerRepo는 origin remote의 "owner/repo", hostName은 프로필 ssh_host의 실제 HostName이다.
+// 위반은 모두 error 수준이다. hints가 nil이면 nil을 반환한다.
+func CheckRepoHints(hints *config.RepoHints, ownerRepo, email, hostName string) []Violation {
+	if hints == nil {
+		return nil
+	}
+	var violations []Violation
+	if hints.Host != "" && !strings.EqualFold(hostName, hints.Host) {
+		violations = append(violations, Violation{
+			Field: "repo_host", Expected: hints.Host,
+			Actual: hostName, Severity: "error",
+		})
+	}
+	owner, _, _ := strings.Cut(ownerRepo, "/")
+	if hints.Owner != "" && !strings.EqualFold(owner, hints.Owner) {
+		violations = append(violations, Violation{
+			Field: "repo_owner", Expected: hints.Owner,
+			Actual: owner, Severity: "error",
+		})
+	}
+	if hints.EmailDomain != "" {
+		_, domain, _ := strings.Cut(email, "@")
+		if !strings.EqualFold(domain, hints.EmailDomain) {
+			violations = append(violations, Violation{
+				Field: "email_domain", Expected: "@" + hints.EmailDomain,
+				Actual: email, Severity: "error",
+			})
+		}
+	}
+	return violations
+}
+
 // InstallHook은 pre-push hook에 guard 스크립트를 설치한다.
 func InstallHook(repoDir string) error {
+	if err := installHook(repoDir, "pre-push", hookScript); err != nil {
+		return fmt.Errorf("guard.InstallHook: %w", err)
+	}
+	return nil
+}
+
+// InstallCommitHook은 pre-commit hook에 guard 스크립트(ctx guard check --stage commit)를 설치한다.
+func InstallCommitHook(repoDir string) error {
+	if err := installHook(repoDir, "pre-commit", commitHookScript); err != nil {
+		return fmt.Errorf("guard.InstallCommitHook: %w", err)
+	}
+	return nil
+}
+
+// installHook은 .git/hooks/<name>에 script 블록을 설치한다. 기존 hook이 있으면 끝에 덧붙이고,
+// 이전 버전 블록이 있으면 교체한다.
+func installHook(repoDir, name, script string) error {
 	hookDir := filepath.Join(repoDir, ".git", "hooks")
 	if err := os.MkdirAll(hookDir, 0755); err != nil { // git hooks 디렉토리는 실행 권한 필요
-		return fmt.Errorf("guard.InstallHook: %w", err)
+		return err
 	}
-	hookPath := filepath.Join(hookDir, "pre-push")
+	hookPath := filepath.Join(hookDir, name)
 
 	existing, err := os.ReadFile(hookPath)
 	if err != nil && !os.IsNotExist(err) {
-		return fmt.Errorf("guard.InstallHook: %w", err)
+		return err
 	}
 
 	var content string
 	if len(existing) > 0 {
 		existingStr := string(existing)
 		if strings.Contains(existingStr, hookStartMarker) {
-			return nil // already installed
+			if !blockOutdated(existingStr, script) {
+				return nil // already installed
+			}
+			// 이전 버전 ctx가 설치한 블록을 현재 스크립트로 교체한다
+			start, end := strings.Index(existingStr, hookStartMarker), strings.Index(existingStr, hookEndMarker)
+			content = existingStr[:start] + script + existingStr[end+len(hookEndMarker):]
+			return os.WriteFile(hookPath, []byte(content), 0755) // 실행 권한 필요 (git hook)
 		}
-		content = existingStr + "\n" + hookScript + "\n"
+		content = existingStr + "\n" + script + "\n"
 	} else {
-		content = "#!/bin/sh\n" + hookScript + "\n"
+		content = "#!/bin/sh\n" + script + "\n"
 	}
 
 	return os.WriteFile(hookPath, []byte(content), 0755) // 실행 권한 필요 (git hook)
 }
 
+// HookOutdated는 hook 내용에 이전 버전 ctx가 설치한 guard 블록이 있으면 true를 반환한다.
+// 이전 블록은 push되는 ref를 넘기지 않아 protected_branch, force_push 검사가 동작하지 않는다.
+func HookOutdated(content string) bool {
+	return blockOutdated(content, hookScript)
+}
+
+func blockOutdated(content, script string) bool {
+	start, end := strings.Index(content, hookStartMarker), strings.Index(content, hookEndMarker)
+	if start < 0 || end < start {
+		return false
+	}
+	return content[start:end+len(hookEndMarker)] != script
+}
+
 // UninstallHook은 pre-push hook에서 guard 스크립트를 제거한다.
 func UninstallHook(repoDir string) error {
-	hookPath := filepath.Join(repoDir, ".git", "hooks", "pre-push")
+	if err := uninstallHook(repoDir, "pre-push"); err != nil {
+		return fmt.Errorf("guard.UninstallHook: %w", err)
+	}
+	return nil
+}
+
+// UninstallCommitHook은 pre-commit hook에서 guard 스크립트를 제거한다.
+func UninstallCommitHook(repoDir string) error {
+	if err := uninstallHook(repoDir, "pre-commit"); err != nil {
+		return fmt.Errorf("guard.UninstallCommitHook: %w", err)
+	}
+	return nil
+}
+
+// uninstallHook은 .git/hooks/<name>에서 guard 블록을 제거한다. 남는 내용이 없으면 파일을 지운다.
+func uninstallHook(repoDir, name string) error {
+	hookPath := filepath.Join(repoDir, ".git", "hooks", name)
 	data, err := os.ReadFile(hookPath)
 	if os.IsNotExist(err) {
 		return nil
 	}
 	if err != nil {
-		return fmt.Errorf("guard.UninstallHook: %w", err)
+		return err
 	}
 
 	content := string(data)
@@ -157,3 +493,118 @@ func UninstallHook(repoDir string) error {
 	}
 	return os.WriteFile(hookPath, []byte(cleaned+"\n"), 0755) // 실행 권한 필요 (git hook)
 }
+
+// emailRuleViolation은 email이 규칙 r의 deny에 맞거나 allow 어느 것에도 맞지 않으면 위반을 반환한다.
+func emailRuleViolation(r config.EmailRule, label, email string) *Violation {
+	if denied := slices.IndexFunc(r.Deny, func(p string) bool { return config.MatchEmail(p, email) }); denied >= 0 {
+		return &Violation{
+			Field: "email_denied", Expected: "not " + r.Deny[denied],
+			Actual: email, Severity: "error", Rule: label,
+		}
+	}
+	if len(r.Allow) > 0 && !slices.ContainsFunc(r.Allow, func(p string) bool { return config.MatchEmail(p, email) }) {
+		return &Violation{
+			Field: "email_not_allowed", Expected: strings.Join(r.Allow, ", "),
+			Actual: email, Severity: "error", Rule: label,
+		}
+	}
+	return nil
+}
+
+// pushedCommit은 push되는 커밋 하나의 작성자·커미터 이메일과 서명 상태(%G?)다.
+type pushedCommit struct {
+	sha       string
+	author    string
+	committer string
+	signature string
+}
+
+// CheckPushedCommits는 ownerRepo에 적용되는 email_rules를 push되는 커밋마다 평가한다.
+// 각 커밋의 작성자(%ae)와 커미터(%ce) 이메일을 규칙에 비교하고,
+// require_signing 규칙이 적용되면 서명이 없거나(N) 잘못된(B) 커밋을 위반으로 본다.
+// push할 커밋을 조회하지 못하면 통과시키지 않고 pushed_commits 위반을 반환한다.
+func CheckPushedCommits(ctx context.Context, cmd cmdexec.Commander, repoDir string, rules []config.EmailRule, ownerRepo string, updates []PushUpdate) []Violation {
+	owner, _, _ := strings.Cut(ownerRepo, "/")
+	var applied []int
+	for i, r := range rules {
+		if r.AppliesTo(owner) {
+			applied = append(applied, i)
+		}
+	}
+	if len(applied) == 0 {
+		return nil
+	}
+
+	var violations []Violation
+	seen := make(map[string]bool)
+	for _, u := range updates {
+		commits, err := pushedCommits(ctx, cmd, repoDir, u)
+		if err != nil {
+			// 커밋을 확인할 수 없으면 규칙을 통과시키지 않는다
+			violations = append(violations, Violation{
+				Field: "pushed_commits", Expected: "push할 커밋 조회 (" + u.LocalRef + ")",
+				Actual: err.Error(), Severity: "error",
+			})
+			continue
+		}
+		for _, c := range commits {
+			if seen[c.sha] {
+				continue
+			}
+			seen[c.sha] = true
+			for _, i := range applied {
+				r, label := rules[i], rules[i].Label(i)
+				emails := []string{c.author}
+				if c.committer != c.author {
+					emails = append(emails, c.committer)
+				}
+				for _, email := range emails {
+					if v := emailRuleViolation(r, label, email); v != nil {
+						v.Actual = fmt.Sprintf("%s (커밋 %s)", email, shortSHA(c.sha))
+						violations = append(violations, *v)
+					}
+				}
+				if r.RequireSigning && (c.signature == "N" || c.signature == "B") {
+					actual := "서명 없음"
+					if c.signature == "B" {
+						actual = "잘못된 서명"
+					}
+					violations = append(violations, Violation{
+						Field: "commit_signing", Expected: "서명된 커밋",
+						Actual: fmt.Sprintf("%s (커밋 %s)", actual, shortSHA(c.sha)), Severity: "error", Rule: label,
+					})
+				}
+			}
+		}
+	}
+	return violations
+}
+
+// pushedCommits는 push update 하나로 원격에 새로 올라가는 커밋을 반환한다.
+// 새 브랜치이거나 원격 커밋이 로컬에 없으면 어느 remote에도 없는 커밋을 대상으로 한다.
+func pushedCommits(ctx context.Context, cmd cmdexec.Commander, repoDir string, u PushUpdate) ([]pushedCommit, error) {
+	if isZeroSHA(u.LocalSHA) {
+		return nil, nil // 삭제는 커밋을 올리지 않는다
+	}
+	logArgs := []string{"-C", repoDir, "log", "--format=%H%x00%ae%x00%ce%x00%G?"}
+	unpushed := slices.Concat(logArgs, []string{u.LocalSHA, "--not", "--remotes"})
+	var out []byte
+	var err error
+	if isZeroSHA(u.RemoteSHA) {
+		out, err = cmd.Run(ctx, "git", unpushed...)
+	} else if out, err = cmd.Run(ctx, "git", slices.Concat(logArgs, []string{u.RemoteSHA + ".." + u.LocalSHA})...); err != nil {
+		out, err = cmd.Run(ctx, "git", unpushed...) // 원격 커밋이 로컬에 없다
+	}
+	if err != nil {
+		return nil, fmt.Errorf("git log 실패: %w", err)
+	}
+	var commits []pushedCommit
+	for line := range strings.Lines(string(out)) {
+		f := strings.Split(strings.TrimRight(line, "\n"), "\x00")
+		if len(f) != 4 {
+			continue
+		}
+		commits = append(commits, pushedCommit{sha: f[0], author: f[1], committer: f[2], signature: f[3]})
+	}
+	return commits, nil
+}
@@ -2,10 +2,14 @@ package guard_test
 
 import (
 	"context"
+	"errors"
 	"os"
+	"os/exec"
 	"path/filepath"
+	"strings"
 	"testing"
 
+	"github.com/hbjs97/ctx/internal/cmdexec"
 	"github.com/hbjs97/ctx/internal/config"
 	"github.com/hbjs97/ctx/internal/guard"
 	"github.com/hbjs97/ctx/internal/testutil"
@@ -99,6 +103,258 @@ func TestCheck_SkipGuardSetsSkippedFlag(t *testing.T) {
 	assert.Empty(t, fc.Calls, "should not execute any commands when skipped")
 }
 
+func TestCheckRepoHints(t *testing.T) {
+	assert.Empty(t, guard.CheckRepoHints(nil, "org/repo", "test@company.com", "github.com"))
+
+	hints := &config.RepoHints{Host: "github.com", Owner: "Org", EmailDomain: "company.com"}
+	assert.Empty(t, guard.CheckRepoHints(hints, "org/repo", "test@Company.com", "GitHub.com"))
+
+	violations := guard.CheckRepoHints(hints, "other/repo", "me@personal.com", "ghe.example.com")
+	require.Len(t, violations, 3)
+	assert.Equal(t, "repo_host", violations[0].Field)
+	assert.Equal(t, "repo_owner", violations[1].Field)
+	assert.Equal(t, "other", violations[1].Actual)
+	assert.Equal(t, "email_domain", violations[2].Field)
+	assert.Equal(t, "@company.com", violations[2].Expected)
+}
+
+func TestCheckEmailRules(t *testing.T) {
+	rules := []config.EmailRule{
+		{Name: "acme-only", Owners: []string{"acme"}, Allow: []string{"*@acme.com"}, RequireSigning: true},
+		{Name: "no-gmail", Deny: []string{"*@gmail.com"}},
+		{Name: "oss", Owners: []string{"oss-org"}, Allow: []string{"*@users.noreply.github.com"}},
+	}
+
+	t.Run("allowed and signed", func(t *testing.T) {
+		fake := testutil.NewFakeCommander()
+		fake.Register("git -C /repo config --get commit.gpgsign", "true\n", nil)
+		assert.Empty(t, guard.CheckEmailRules(context.Background(), fake, "/repo", rules, "acme/api", "dev@acme.com"))
+	})
+
+	t.Run("not allowed and unsigned", func(t *testing.T) {
+		fake := testutil.NewFakeCommander()
+		fake.Register("git -C /repo config --get commit.gpgsign", "", errors.New("exit status 1"))
+		violations := guard.CheckEmailRules(context.Background(), fake, "/repo", rules, "acme/api", "me@gmail.com")
+		require.Len(t, violations, 3)
+		assert.Equal(t, guard.Violation{Field: "email_not_allowed", Expected: "*@acme.com", Actual: "me@gmail.com", Severity: "error", Rule: "acme-only"}, violations[0])
+		assert.Equal(t, "commit_signing", violations[1].Field)
+		assert.Equal(t, "acme-only", violations[1].Rule)
+		assert.Equal(t, "email_denied", violations[2].Field)
+		assert.Equal(t, "no-gmail", violations[2].Rule)
+	})
+
+	t.Run("owner scoped rule does not apply elsewhere", func(t *testing.T) {
+		fake := testutil.NewFakeCommander()
+		assert.Empty(t, guard.CheckEmailRules(context.Background(), fake, "/repo", rules, "hbjs97/dotfiles", "me@example.com"))
+		assert.False(t, fake.Called("git"), "서명 규칙이 적용되지 않으면 git을 실행하지 않는다")
+	})
+
+	t.Run("noreply allowed for oss", func(t *testing.T) {
+		fake := testutil.NewFakeCommander()
+		assert.Empty(t, guard.CheckEmailRules(context.Background(), fake, "/repo", rules, "oss-org/lib", "1+me@users.noreply.github.com"))
+	})
+}
+
+func TestCheckPushedCommits(t *testing.T) {
+	const (
+		oldSHA  = "1111111111111111111111111111111111111111"
+		newSHA  = "2222222222222222222222222222222222222222"
+		zeroSHA = "0000000000000000000000000000000000000000"
+	)
+	rules := []config.EmailRule{
+		{Name: "acme-only", Owners: []string{"acme"}, Allow: []string{"*@acme.com"}, RequireSigning: true},
+	}
+	logCmd := "git -C /repo log --format=%H%x00%ae%x00%ce%x00%G? "
+
+	t.Run("checks author, committer and signature of each pushed commit", func(t *testing.T) {
+		fake := testutil.NewFakeCommander()
+		fake.Register(logCmd+oldSHA+".."+newSHA,
+			"aaaaaaaaaa\x00dev@acme.com\x00dev@acme.com\x00G\n"+
+				"bbbbbbbbbb\x00me@gmail.com\x00dev@acme.com\x00N\n", nil)
+		violations := guard.CheckPushedCommits(context.Background(), fake, "/repo", rules, "acme/api",
+			[]guard.PushUpdate{{LocalRef: "refs/heads/main", LocalSHA: newSHA, RemoteRef: "refs/heads/main", RemoteSHA: oldSHA}})
+		require.Len(t, violations, 2)
+		assert.Equal(t, "email_not_allowed", violations[0].Field)
+		assert.Equal(t, "me@gmail.com (커밋 bbbbbbb)", violations[0].Actual)
+		assert.Equal(t, "commit_signing", violations[1].Field)
+		assert.Equal(t, "서명 없음 (커밋 bbbbbbb)", violations[1].Actual)
+	})
+
+	t.Run("new branch checks commits not on any remote", func(t *testing.T) {
+		fake := testutil.NewFakeCommander()
+		fake.Register(logCmd+newSHA+" --not --remotes", "cccccccccc\x00dev@acme.com\x00ci@other.com\x00G\n", nil)
+		violations := guard.CheckPushedCommits(context.Background(), fake, "/repo", rules, "acme/api",
+			[]guard.PushUpdate{{LocalRef: "refs/heads/feature", LocalSHA: newSHA, RemoteRef: "refs/heads/feature", RemoteSHA: zeroSHA}})
+		require.Len(t, violations, 1)
+		assert.Equal(t, "ci@other.com (커밋 ccccccc)", violations[0].Actual, "커미터 이메일도 검사")
+		assert.False(t, fake.Called(logCmd+zeroSHA))
+	})
+
+	t.Run("git log failure blocks", func(t *testing.T) {
+		fake := testutil.NewFakeCommander()
+		fake.Register(logCmd, "", errors.New("fatal: bad object"))
+		violations := guard.CheckPushedCommits(context.Background(), fake, "/repo", rules, "acme/api",
+			[]guard.PushUpdate{{LocalRef: "refs/heads/main", LocalSHA: newSHA, RemoteRef: "refs/heads/main", RemoteSHA: oldSHA}})
+		require.Len(t, violations, 1, "커밋을 확인할 수 없으면 통과시키지 않는다")
+		assert.Equal(t, "pushed_commits", violations[0].Field)
+		assert.Equal(t, "error", violations[0].Severity)
+		assert.Contains(t, violations[0].Actual, "bad object")
+	})
+
+	t.Run("rules for other owners run no git", func(t *testing.T) {
+		fake := testutil.NewFakeCommander()
+		assert.Empty(t, guard.CheckPushedCommits(context.Background(), fake, "/repo", rules, "hbjs97/dotfiles",
+			[]guard.PushUpdate{{LocalSHA: newSHA, RemoteSHA: oldSHA}}))
+		assert.False(t, fake.Called("git"))
+	})
+}
+
+func TestCheckWithOptions_EmailRulesCheckPushedCommits(t *testing.T) {
+	repoDir := testutil.TempGitRepoWithRemote(t, "git@github-work:acme/repo.git")
+	run := func(args ...string) string {
+		t.Helper()
+		out, err := exec.Command("git", append([]string{"-C", repoDir}, args...)...).CombinedOutput()
+		require.NoError(t, err, string(out))
+		return strings.TrimSpace(string(out))
+	}
+	run("config", "user.email", "me@gmail.com")
+	run("commit", "--allow-empty", "-m", "personal")
+	run("config", "user.email", "test@company.com")
+	run("config", "user.name", "Test User")
+	head := run("rev-parse", "HEAD")
+
+	opts := guard.Options{
+		EmailRules: []config.EmailRule{{Name: "company", Allow: []string{"*@company.com"}}},
+		Push: &guard.Push{Remote: "origin", Updates: []guard.PushUpdate{{
+			LocalRef: "refs/heads/main", LocalSHA: head,
+			RemoteRef: "refs/heads/main", RemoteSHA: "0000000000000000000000000000000000000000",
+		}}},
+	}
+	result, err := guard.CheckWithOptions(context.Background(), repoDir, testProfile(), &cmdexec.RealCommander{}, opts)
+	require.NoError(t, err)
+	assert.False(t, result.Pass, "현재 user.email이 맞아도 push되는 커밋의 이메일이 규칙 위반이면 차단")
+	require.NotEmpty(t, result.Violations)
+	assert.Equal(t, "email_not_allowed", result.Violations[len(result.Violations)-1].Field)
+}
+
+func TestCheckWithOptions_EmailRuleBlocks(t *testing.T) {
+	fake := testutil.NewFakeCommander()
+	fake.Register("git -C /tmp/repo remote get-url origin", "git@github-work:acme/repo.git\n", nil)
+	fake.Register("git -C /tmp/repo config --local user.email", "test@company.com\n", nil)
+	fake.Register("git -C /tmp/repo config --local user.name", "Test User\n", nil)
+
+	opts := guard.Options{EmailRules: []config.EmailRule{{Name: "acme-only", Owners: []string{"acme"}, Allow: []string{"@acme.com"}}}}
+	result, err := guard.CheckWithOptions(context.Background(), "/tmp/repo", testProfile(), fake, opts)
+	require.NoError(t, err)
+	assert.False(t, result.Pass, "프로필과 일치해도 규칙 위반이면 차단")
+	require.Len(t, result.Violations, 1)
+	assert.Equal(t, "acme-only", result.Violations[0].Rule)
+}
+
+func guardFake(remote, email, name string) *testutil.FakeCommander {
+	fake := testutil.NewFakeCommander()
+	fake.Register("git -C /tmp/repo remote get-url origin", remote+"\n", nil)
+	fake.Register("git -C /tmp/repo config --local user.email", email+"\n", nil)
+	fake.Register("git -C /tmp/repo config --local user.name", name+"\n", nil)
+	return fake
+}
+
+func TestCheckWithOptions_Levels(t *testing.T) {
+	fake := guardFake("git@other-host:org/repo.git", "test@company.com", "Someone Else")
+	opts := guard.Options{Checks: config.GuardChecks{RemoteHost: config.GuardOff, UserName: config.GuardBlock}}
+
+	result, err := guard.CheckWithOptions(context.Background(), "/tmp/repo", testProfile(), fake, opts)
+	require.NoError(t, err)
+	assert.False(t, result.Pass)
+	require.Len(t, result.Violations, 1, "remote_host는 꺼져 있다")
+	assert.Equal(t, "user_name", result.Violations[0].Field)
+	assert.Equal(t, "error", result.Violations[0].Severity)
+}
+
+func TestCheckWithOptions_RemoteChecks(t *testing.T) {
+	p := testProfile()
+	p.Owners = []string{"Org"}
+	opts := guard.Options{Checks: config.GuardChecks{HTTPSRemote: config.GuardWarn, RemoteOwner: config.GuardBlock}}
+
+	result, err := guard.CheckWithOptions(context.Background(), "/tmp/repo", p, guardFake("https://github.com/org/repo.git", "test@company.com", "Test User"), opts)
+	require.NoError(t, err)
+	assert.True(t, result.Pass)
+	require.Len(t, result.Violations, 1)
+	assert.Equal(t, "https_remote", result.Violations[0].Field)
+	assert.Equal(t, "warning", result.Violations[0].Severity)
+
+	result, err = guard.CheckWithOptions(context.Background(), "/tmp/repo", p, guardFake("git@github-work:personal/repo.git", "test@company.com", "Test User"), opts)
+	require.NoError(t, err)
+	assert.False(t, result.Pass)
+	require.Len(t, result.Violations, 1)
+	assert.Equal(t, guard.Violation{Field: "remote_owner", Expected: "Org", Actual: "personal", Severity: "error"}, result.Violations[0])
+}
+
+func TestCheckWithOptions_PushUpdates(t *testing.T) {
+	const (
+		oldSHA  = "1111111111111111111111111111111111111111"
+		newSHA  = "2222222222222222222222222222222222222222"
+		zeroSHA = "0000000000000000000000000000000000000000"
+	)
+	p := testProfile()
+	p.ProtectedBranches = []string{"main", "release/*"}
+	fake := guardFake("git@github-work:org/repo.git", "test@company.com", "Test User")
+	fake.Register("git -C /tmp/repo merge-base --is-ancestor "+oldSHA+" "+newSHA, "", errors.New("exit status 1"))
+
+	updates, err := guard.ParsePushUpdates(strings.NewReader(
+		"refs/heads/feature " + newSHA + " refs/heads/feature " + zeroSHA + "\n" +
+			"refs/heads/topic " + newSHA + " refs/heads/release/1.0 " + oldSHA + "\n"))
+	require.NoError(t, err)
+	require.Len(t, updates, 2)
+
+	opts := guard.Options{
+		Checks: config.GuardChecks{ForcePush: config.GuardWarn},
+		Push:   &guard.Push{Remote: "origin", Updates: updates},
+	}
+	result, err := guard.CheckWithOptions(context.Background(), "/tmp/repo", p, fake, opts)
+	require.NoError(t, err)
+	assert.False(t, result.Pass)
+	require.Len(t, result.Violations, 2)
+	assert.Equal(t, "protected_branch", result.Violations[0].Field)
+	assert.Equal(t, "release/1.0", result.Violations[0].Actual)
+	assert.Equal(t, "force_push", result.Violations[1].Field)
+	assert.Equal(t, "warning", result.Violations[1].Severity)
+	assert.Equal(t, 1, fake.CallCount("git -C /tmp/repo merge-base"), "새 브랜치는 force push 검사를 하지 않는다")
+
+	_, err = guard.ParsePushUpdates(strings.NewReader("garbage\n"))
+	assert.Error(t, err)
+}
+
+func TestCheckWithOptions_PushURLOverridesOrigin(t *testing.T) {
+	fake := guardFake("git@github-work:org/repo.git", "test@company.com", "Test User")
+	opts := guard.Options{Push: &guard.Push{Remote: "fork", URL: "git@github-personal:me/repo.git"}}
+
+	result, err := guard.CheckWithOptions(context.Background(), "/tmp/repo", testProfile(), fake, opts)
+	require.NoError(t, err)
+	assert.False(t, result.Pass)
+	assert.Equal(t, "github-personal", result.Violations[0].Actual)
+	assert.False(t, fake.Called("git -C /tmp/repo remote get-url"))
+}
+
+func TestInstallHook_UpgradesOutdatedBlock(t *testing.T) {
+	repoDir := testutil.TempGitRepo(t)
+	hookPath := filepath.Join(repoDir, ".git", "hooks", "pre-push")
+	require.NoError(t, os.MkdirAll(filepath.Dir(hookPath), 0755))
+	old := "#!/bin/sh\necho before\n# ctx-guard-start\nctx guard check || exit 1\n# ctx-guard-end\necho after\n"
+	require.NoError(t, os.WriteFile(hookPath, []byte(old), 0755))
+	assert.True(t, guard.HookOutdated(old))
+
+	require.NoError(t, guard.InstallHook(repoDir))
+
+	data, err := os.ReadFile(hookPath)
+	require.NoError(t, err)
+	content := string(data)
+	assert.False(t, guard.HookOutdated(content))
+	assert.Contains(t, content, "--pre-push")
+	assert.True(t, strings.HasPrefix(content, "#!/bin/sh\necho before\n# ctx-guard-start"))
+	assert.True(t, strings.HasSuffix(content, "# ctx-guard-end\necho after\n"))
+}
+
 func TestInstallHook_NoExisting(t *testing.T) {
 	repoDir := testutil.TempGitRepo(t)
 	hookPath := filepath.Join(repoDir, ".git", "hooks", "pre-push")
@@ -131,7 +387,7 @@ func TestInstallHook_ContainsPathCheck(t *testing.T) {
 	require.NoError(t, err)
 	content := string(data)
 	assert.Contains(t, content, "command -v ctx")
-	assert.Contains(t, content, "ctx guard check || exit 1")
+	assert.Contains(t, content, "ctx guard check --pre-push \"$@\" || exit 1")
 	assert.Contains(t, content, "ctx-guard-start")
 	assert.Contains(t, content, "ctx-guard-end")
 }
@@ -170,3 +426,62 @@ func TestUninstallHook(t *testing.T) {
 		assert.NotContains(t, content, "ctx guard check")
 	}
 }
+
+func TestInstallCommitHook(t *testing.T) {
+	repoDir := testutil.TempGitRepo(t)
+	hookPath := filepath.Join(repoDir, ".git", "hooks", "pre-commit")
+	require.NoError(t, os.WriteFile(hookPath, []byte("#!/bin/sh\necho lint\n"), 0755))
+
+	require.NoError(t, guard.InstallCommitHook(repoDir))
+	require.NoError(t, guard.InstallCommitHook(repoDir), "두 번 설치해도 블록은 하나")
+
+	data, err := os.ReadFile(hookPath)
+	require.NoError(t, err)
+	content := string(data)
+	assert.Contains(t, content, "echo lint")
+	assert.Contains(t, content, "ctx guard check --stage commit || exit 1")
+	assert.Equal(t, 1, strings.Count(content, "# ctx-guard-start"))
+	assert.NoFileExists(t, filepath.Join(repoDir, ".git", "hooks", "pre-push"), "pre-push는 건드리지 않음")
+
+	require.NoError(t, guard.UninstallCommitHook(repoDir))
+	data, err = os.ReadFile(hookPath)
+	require.NoError(t, err)
+	assert.Equal(t, "#!/bin/sh\necho lint\n", string(data))
+}
+
+func TestCheckWithOptions_CommitStage(t *testing.T) {
+	fake := testutil.NewFakeCommander()
+	// 유효한 설정(global/includeIf 포함)을 본다
+	fake.Register("git -C /tmp/repo config user.email", "me@personal.com\n", nil)
+	fake.Register("git -C /tmp/repo config user.name", "Test User\n", nil)
+	fake.Register("git -C /tmp/repo remote get-url origin", "git@github-personal:acme/repo.git\n", nil)
+
+	opts := guard.Options{
+		Stage:      guard.StageCommit,
+		Hints:      &config.RepoHints{Host: "ghe.example.com", EmailDomain: "company.com"},
+		EmailRules: []config.EmailRule{{Name: "acme-only", Owners: []string{"acme"}, Allow: []string{"*@company.com"}}},
+	}
+	result, err := guard.CheckWithOptions(context.Background(), "/tmp/repo", testProfile(), fake, opts)
+	require.NoError(t, err)
+	assert.False(t, result.Pass)
+
+	var fields []string
+	for _, v := range result.Violations {
+		fields = append(fields, v.Field)
+	}
+	assert.Equal(t, []string{"user_email", "email_domain", "email_not_allowed"}, fields, "remote_host, repo_host 검사는 하지 않음")
+	for _, c := range fake.Calls {
+		assert.NotContains(t, c, "--local", "커밋 단계는 유효한 설정을 봐야 함")
+	}
+}
+
+func TestCheckWithOptions_CommitStagePass(t *testing.T) {
+	fake := testutil.NewFakeCommander()
+	fake.Register("git -C /tmp/repo config user.email", "test@company.com\n", nil)
+	fake.Register("git -C /tmp/repo config user.name", "Test User\n", nil)
+
+	result, err := guard.CheckWithOptions(context.Background(), "/tmp/repo", testProfile(), fake, guard.Options{Stage: guard.StageCommit})
+	require.NoError(t, err)
+	assert.True(t, result.Pass, "origin이 없어도 통과")
+	assert.Empty(t, result.Violations)
+}
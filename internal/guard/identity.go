@@ -0,0 +1,134 @@
+package guard
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/hbjs97/ctx/internal/cmdexec"
+	"github.com/hbjs97/ctx/internal/fsutil"
+	"github.com/hbjs97/ctx/internal/gh"
+)
+
+// Identity는 ssh_identity 검사 설정이다.
+type Identity struct {
+	CachePath  string        // 확인 결과 캐시 파일. 비어 있으면 캐시하지 않는다
+	TTL        time.Duration // 캐시된 결과를 재사용하는 기간
+	GitHubUser string        // 프로필의 github_user. 있으면 gh 로그인을 조회하지 않고 기대값으로 쓴다
+}
+
+// identityCache는 SSH alias별 ssh_identity 확인 결과다.
+type identityCache struct {
+	Entries map[string]identityEntry `json:"entries"`
+}
+
+type identityEntry struct {
+	Signature string    `json:"signature"` // ssh -G 설정과 gh_config_dir. 바뀌면 다시 확인한다
+	SSHLogin  string    `json:"ssh_login"`
+	GHLogin   string    `json:"gh_login"` // 기대 로그인. github_user가 있으면 그 값이다
+	CheckedAt time.Time `json:"checked_at"`
+}
+
+// CheckSSHIdentity는 SSH alias가 실제로 인증되는 GitHub 로그인(ssh -T 인사말)이
+// id.GitHubUser(없으면 ghConfigDir의 gh 로그인)와 같은지 확인한다. 같으면 nil을 반환한다.
+// ssh -G로 얻은 HostName/User/IdentityFile과 ghConfigDir, github_user가 같고 TTL 안이면 캐시된 결과를 쓴다.
+// 로그인을 확인하지 못하면 위반으로 보고하며, 실패한 결과는 캐시하지 않는다.
+func CheckSSHIdentity(ctx context.Context, cmd cmdexec.Commander, sshHost, ghConfigDir string, id Identity, now time.Time) *Violation {
+	signature := sshSignature(ctx, cmd, sshHost) + ";gh_config_dir=" + ghConfigDir
+	if id.GitHubUser != "" {
+		signature += ";github_user=" + id.GitHubUser
+	}
+
+	cache := loadIdentityCache(id.CachePath)
+	entry, ok := cache.Entries[sshHost]
+	if !ok || entry.Signature != signature || now.Sub(entry.CheckedAt) >= id.TTL || entry.CheckedAt.After(now) {
+		adapter := gh.NewAdapter(cmd)
+		entry = identityEntry{Signature: signature, CheckedAt: now}
+		entry.SSHLogin, _ = adapter.SSHLogin(ctx, sshHost) // 실패는 빈 로그인으로 보고한다
+		entry.GHLogin = id.GitHubUser
+		if entry.GHLogin == "" {
+			entry.GHLogin, _ = adapter.Login(ctx, ghConfigDir) // 실패는 빈 로그인으로 보고한다
+		}
+		if entry.SSHLogin != "" && entry.GHLogin != "" && id.CachePath != "" {
+			_ = saveIdentityEntry(id.CachePath, sshHost, entry) // 캐시 저장 실패는 다음 push에서 다시 확인할 뿐이다
+		}
+	}
+
+	switch {
+	case entry.SSHLogin == "":
+		return &Violation{Field: "ssh_identity", Expected: nonEmpty(entry.GHLogin, "(gh 로그인 확인 불가)"), Actual: fmt.Sprintf("(ssh -T git@%s 인증 실패)", sshHost)}
+	case entry.GHLogin == "":
+		return &Violation{Field: "ssh_identity", Expected: "(gh 로그인 확인 불가)", Actual: entry.SSHLogin}
+	case !strings.EqualFold(entry.SSHLogin, entry.GHLogin):
+		return &Violation{Field: "ssh_identity", Expected: entry.GHLogin, Actual: entry.SSHLogin}
+	}
+	return nil
+}
+
+func nonEmpty(s, fallback string) string {
+	if s == "" {
+		return fallback
+	}
+	return s
+}
+
+// sshSignature는 ssh -G로 alias에 실제 적용되는 hostname, user, identityfile을 모은다.
+// ssh -G는 접속하지 않으므로 빠르다. 실패하면 빈 문자열이다.
+func sshSignature(ctx context.Context, cmd cmdexec.Commander, sshHost string) string {
+	out, err := cmd.Run(ctx, "ssh", "-G", sshHost)
+	if err != nil {
+		return ""
+	}
+	var parts []string
+	for line := range strings.SplitSeq(string(out), "\n") {
+		key, value, _ := strings.Cut(strings.TrimSpace(line), " ")
+		switch key {
+		case "hostname", "user", "identityfile", "identitiesonly", "identityagent":
+			parts = append(parts, key+"="+value)
+		}
+	}
+	sort.Strings(parts)
+	return strings.Join(parts, ";")
+}
+
+// loadIdentityCache는 캐시 파일을 읽는다. 없거나 손상되었으면 빈 캐시다.
+func loadIdentityCache(path string) *identityCache {
+	c := &identityCache{}
+	if path != "" {
+		if data, err := os.ReadFile(path); err == nil {
+			_ = json.Unmarshal(data, c) // 손상된 캐시는 버리고 다시 확인한다
+		}
+	}
+	if c.Entries == nil {
+		c.Entries = make(map[string]identityEntry)
+	}
+	return c
+}
+
+// saveIdentityEntry는 동시에 실행된 다른 hook의 항목을 잃지 않도록 lock 후 다시 읽어 갱신한다.
+func saveIdentityEntry(path, sshHost string, entry identityEntry) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return fmt.Errorf("guard.saveIdentityEntry: %w", err)
+	}
+	lock, err := fsutil.Lock(path)
+	if err != nil {
+		return fmt.Errorf("guard.saveIdentityEntry: %w", err)
+	}
+	defer lock.Unlock()
+
+	c := loadIdentityCache(path)
+	c.Entries[sshHost] = entry
+	data, err := json.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return fmt.Errorf("guard.saveIdentityEntry: %w", err)
+	}
+	if err := fsutil.WriteFileAtomic(path, data, 0600); err != nil {
+		return fmt.Errorf("guard.saveIdentityEntry: %w", err)
+	}
+	return nil
+}
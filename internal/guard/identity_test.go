@@ -0,0 +1,124 @@
+package guard_test
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hbjs97/ctx/internal/config"
+	"github.com/hbjs97/ctx/internal/guard"
+	"github.com/hbjs97/ctx/internal/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const greeting = "Hi %s! You've successfully authenticated, but GitHub does not provide shell access.\n"
+
+func identityFake(sshLogin, ghLogin string) *testutil.FakeCommander {
+	fake := testutil.NewFakeCommander()
+	fake.Register("ssh -G github-work", "hostname github.com\nuser git\nidentityfile ~/.ssh/id_work\nport 22\n", nil)
+	fake.Register("ssh -T git@github-work", strings.Replace(greeting, "%s", sshLogin, 1), nil)
+	fake.Register("gh api user", ghLogin+"\n", nil)
+	return fake
+}
+
+func countCalls(fake *testutil.FakeCommander, prefix string) int {
+	var n int
+	for _, c := range fake.Calls {
+		if strings.HasPrefix(c, prefix) {
+			n++
+		}
+	}
+	return n
+}
+
+func TestCheckSSHIdentity(t *testing.T) {
+	now := time.Now()
+	id := guard.Identity{TTL: time.Hour}
+
+	assert.Nil(t, guard.CheckSSHIdentity(context.Background(), identityFake("work-me", "Work-Me"), "github-work", "/tmp/gh-work", id, now))
+
+	v := guard.CheckSSHIdentity(context.Background(), identityFake("home-me", "work-me"), "github-work", "/tmp/gh-work", id, now)
+	require.NotNil(t, v)
+	assert.Equal(t, "ssh_identity", v.Field)
+	assert.Equal(t, "work-me", v.Expected)
+	assert.Equal(t, "home-me", v.Actual)
+
+	fake := testutil.NewFakeCommander()
+	fake.Register("ssh -T", "git@github.com: Permission denied (publickey).\n", nil)
+	fake.Register("gh api user", "work-me\n", nil)
+	v = guard.CheckSSHIdentity(context.Background(), fake, "github-work", "/tmp/gh-work", id, now)
+	require.NotNil(t, v, "인증 실패도 위반")
+	assert.Contains(t, v.Actual, "인증 실패")
+}
+
+func TestCheckSSHIdentity_PrefersGitHubUser(t *testing.T) {
+	fake := identityFake("work-me", "home-me") // gh 설정 디렉토리가 다른 계정으로 로그인된 상태
+	id := guard.Identity{TTL: time.Hour, GitHubUser: "work-me"}
+
+	assert.Nil(t, guard.CheckSSHIdentity(context.Background(), fake, "github-work", "/tmp/gh-work", id, time.Now()))
+	assert.Zero(t, countCalls(fake, "gh api user"), "github_user가 있으면 gh 로그인을 조회하지 않음")
+}
+
+func TestCheckSSHIdentity_Cache(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now()
+	id := guard.Identity{CachePath: filepath.Join(t.TempDir(), "ssh-identity.json"), TTL: time.Hour}
+
+	fake := identityFake("work-me", "work-me")
+	require.Nil(t, guard.CheckSSHIdentity(ctx, fake, "github-work", "/tmp/gh-work", id, now))
+	require.Nil(t, guard.CheckSSHIdentity(ctx, fake, "github-work", "/tmp/gh-work", id, now.Add(30*time.Minute)))
+	assert.Equal(t, 1, countCalls(fake, "ssh -T"), "TTL 안에서는 캐시 사용")
+	assert.Equal(t, 2, countCalls(fake, "ssh -G"), "설정 변경 감지는 매번")
+
+	// ssh config가 바뀌면 TTL 안이어도 다시 확인한다
+	fake.Register("ssh -G github-work", "hostname github.com\nuser git\nidentityfile ~/.ssh/id_personal\n", nil)
+	fake.Register("ssh -T git@github-work", strings.Replace(greeting, "%s", "home-me", 1), nil)
+	v := guard.CheckSSHIdentity(ctx, fake, "github-work", "/tmp/gh-work", id, now.Add(31*time.Minute))
+	require.NotNil(t, v)
+	assert.Equal(t, "home-me", v.Actual)
+	assert.Equal(t, 2, countCalls(fake, "ssh -T"))
+
+	// TTL이 지나면 다시 확인한다
+	fake.Register("ssh -T git@github-work", strings.Replace(greeting, "%s", "work-me", 1), nil)
+	assert.NotNil(t, guard.CheckSSHIdentity(ctx, fake, "github-work", "/tmp/gh-work", id, now.Add(60*time.Minute)), "TTL 안이면 캐시된 불일치 결과")
+	assert.Nil(t, guard.CheckSSHIdentity(ctx, fake, "github-work", "/tmp/gh-work", id, now.Add(2*time.Hour)))
+	assert.Equal(t, 3, countCalls(fake, "ssh -T"))
+}
+
+func TestCheckSSHIdentity_FailureNotCached(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now()
+	id := guard.Identity{CachePath: filepath.Join(t.TempDir(), "ssh-identity.json"), TTL: time.Hour}
+
+	fake := identityFake("work-me", "work-me")
+	fake.Register("gh api user", "", assert.AnError)
+	require.NotNil(t, guard.CheckSSHIdentity(ctx, fake, "github-work", "/tmp/gh-work", id, now))
+
+	fake.Register("gh api user", "work-me\n", nil)
+	assert.Nil(t, guard.CheckSSHIdentity(ctx, fake, "github-work", "/tmp/gh-work", id, now))
+	assert.Equal(t, 2, countCalls(fake, "ssh -T"))
+}
+
+func TestCheckWithOptions_SSHIdentityLevel(t *testing.T) {
+	fake := identityFake("home-me", "work-me")
+	fake.Register("git -C /tmp/repo remote get-url origin", "git@github-work:org/repo.git\n", nil)
+	fake.Register("git -C /tmp/repo config --local user.email", "test@company.com\n", nil)
+	fake.Register("git -C /tmp/repo config --local user.name", "Test User\n", nil)
+
+	result, err := guard.CheckWithOptions(context.Background(), "/tmp/repo", testProfile(), fake, guard.Options{})
+	require.NoError(t, err)
+	assert.True(t, result.Pass, "기본값은 off")
+	assert.Zero(t, countCalls(fake, "ssh"), "off면 ssh를 실행하지 않음")
+
+	result, err = guard.CheckWithOptions(context.Background(), "/tmp/repo", testProfile(), fake, guard.Options{
+		Checks:   config.GuardChecks{SSHIdentity: config.GuardBlock},
+		Identity: guard.Identity{TTL: time.Hour},
+	})
+	require.NoError(t, err)
+	assert.False(t, result.Pass)
+	require.Len(t, result.Violations, 1)
+	assert.Equal(t, "ssh_identity", result.Violations[0].Field)
+}
@@ -0,0 +1,2 @@
+// Package migrate runs the versioned schema migrations shared by the config and cache files.
+package migrate
@@ -0,0 +1,30 @@
+package migrate
+
+import "fmt"
+
+// Step은 문서를 From 버전에서 From+1 버전으로 올린다.
+// 필드 이름 변경 등에 대응할 수 있도록 구조체로 디코딩하기 전의 문서에 적용한다.
+type Step struct {
+	From        int
+	Description string
+	Apply       func(doc map[string]any) error
+}
+
+// Run은 version 이상에서 시작하는 steps를 순서대로 적용하고 적용한 단계 설명을 반환한다.
+// steps는 From 순서대로 빠짐없이 등록되어 있어야 한다.
+// 문서 형식마다 숫자 타입이 달라 단계가 끝날 때마다 setVersion으로 doc의 version을 기록한다.
+func Run(doc map[string]any, version int, steps []Step, setVersion func(doc map[string]any, version int)) ([]string, error) {
+	var applied []string
+	for _, s := range steps {
+		if s.From < version {
+			continue
+		}
+		if err := s.Apply(doc); err != nil {
+			return nil, fmt.Errorf("version %d → %d 마이그레이션 실패: %w", s.From, s.From+1, err)
+		}
+		version = s.From + 1
+		setVersion(doc, version)
+		applied = append(applied, fmt.Sprintf("v%d → v%d: %s", s.From, version, s.Description))
+	}
+	return applied, nil
+}
@@ -0,0 +1,43 @@
+package migrate_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/hbjs97/ctx/internal/migrate"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func setVersion(doc map[string]any, version int) { doc["version"] = version }
+
+func TestRun(t *testing.T) {
+	steps := []migrate.Step{
+		{From: 0, Description: "초기 버전", Apply: func(map[string]any) error { return nil }},
+		{From: 1, Description: "name → title", Apply: func(doc map[string]any) error {
+			doc["title"] = doc["name"]
+			delete(doc, "name")
+			return nil
+		}},
+	}
+
+	doc := map[string]any{"name": "x"}
+	applied, err := migrate.Run(doc, 0, steps, setVersion)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"v0 → v1: 초기 버전", "v1 → v2: name → title"}, applied)
+	assert.Equal(t, map[string]any{"title": "x", "version": 2}, doc)
+
+	applied, err = migrate.Run(doc, 2, steps, setVersion)
+	require.NoError(t, err)
+	assert.Empty(t, applied, "이미 최신이면 적용하지 않는다")
+}
+
+func TestRun_StepError(t *testing.T) {
+	boom := errors.New("boom")
+	steps := []migrate.Step{{From: 0, Apply: func(map[string]any) error { return boom }}}
+
+	doc := map[string]any{}
+	_, err := migrate.Run(doc, 0, steps, setVersion)
+	assert.ErrorIs(t, err, boom)
+	assert.NotContains(t, doc, "version")
+}
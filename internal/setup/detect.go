@@ -2,6 +2,7 @@ package setup
 
 import (
 	"context"
+	"slices"
 	"strings"
 
 	"github.com/hbjs97/ctx/internal/cmdexec"
@@ -11,30 +12,38 @@ import (
 // DetectOrgs는 gh api로 인증된 사용자의 조직 목록과 사용자명을 조회한다.
 // 조회 실패 시 빈 슬라이스를 반환한다 (에러로 차단하지 않음).
 func DetectOrgs(ctx context.Context, cmd cmdexec.Commander, ghConfigDir string) []string {
-	env := gh.SuppressEnvTokens()
-	env["GH_CONFIG_DIR"] = ghConfigDir
+	login, orgs := DetectAccount(ctx, cmd, ghConfigDir)
+	if login != "" {
+		orgs = append(orgs, login)
+	}
+	return orgs
+}
 
-	var orgs []string
+// DetectAccount는 gh api로 인증된 사용자의 로그인과 조직 목록을 따로 조회한다.
+// 조회에 실패한 값은 비어 있다 (에러로 차단하지 않음).
+func DetectAccount(ctx context.Context, cmd cmdexec.Commander, ghConfigDir string) (string, []string) {
+	adapter := gh.NewAdapter(cmd)
+	orgs, _ := adapter.Orgs(ctx, ghConfigDir)   // 실패는 빈 목록으로 본다
+	login, _ := adapter.Login(ctx, ghConfigDir) // 실패는 빈 로그인으로 본다
+	return login, orgs
+}
 
-	// 조직 목록 조회
-	out, err := cmd.RunWithEnv(ctx, env, "gh", "api", "user/orgs", "--jq", ".[].login")
-	if err == nil {
-		for _, line := range strings.Split(strings.TrimSpace(string(out)), "\n") {
-			line = strings.TrimSpace(line)
-			if line != "" {
-				orgs = append(orgs, line)
-			}
+// DiffOwners는 조회한 조직 목록과 프로필 owners를 비교해 추가·제거 후보를 반환한다.
+// login(프로필의 GitHub 계정)은 owner 매칭에 자동 포함되므로 어느 쪽에도 넣지 않는다.
+// 대소문자를 구분하지 않는다.
+func DiffOwners(owners []string, login string, orgs []string) (add, remove []string) {
+	has := func(list []string, v string) bool {
+		return slices.ContainsFunc(list, func(o string) bool { return strings.EqualFold(o, v) })
+	}
+	for _, o := range orgs {
+		if !has(owners, o) && !strings.EqualFold(o, login) {
+			add = append(add, o)
 		}
 	}
-
-	// 사용자명 조회
-	out, err = cmd.RunWithEnv(ctx, env, "gh", "api", "user", "--jq", ".login")
-	if err == nil {
-		login := strings.TrimSpace(string(out))
-		if login != "" {
-			orgs = append(orgs, login)
+	for _, o := range owners {
+		if !has(orgs, o) && !strings.EqualFold(o, login) {
+			remove = append(remove, o)
 		}
 	}
-
-	return orgs
+	return add, remove
 }
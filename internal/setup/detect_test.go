@@ -35,3 +35,23 @@ func TestDetectOrgs_PartialFailure(t *testing.T) {
 	orgs := DetectOrgs(context.Background(), fc, "/tmp/gh-work")
 	assert.Equal(t, []string{"myuser"}, orgs)
 }
+
+func TestDetectAccount_SplitsLogin(t *testing.T) {
+	fc := testutil.NewFakeCommander()
+	fc.Register("gh api user/orgs --jq .[].login", "company-org\n", nil)
+	fc.Register("gh api user --jq .login", "hbjs97\n", nil)
+
+	login, orgs := DetectAccount(context.Background(), fc, "/tmp/gh-work")
+	assert.Equal(t, "hbjs97", login)
+	assert.Equal(t, []string{"company-org"}, orgs)
+}
+
+func TestDiffOwners(t *testing.T) {
+	add, remove := DiffOwners([]string{"acme", "Old-Org", "hbjs97"}, "HBJS97", []string{"ACME", "new-org"})
+	assert.Equal(t, []string{"new-org"}, add)
+	assert.Equal(t, []string{"Old-Org"}, remove, "github_user는 제거 후보가 아님")
+
+	add, remove = DiffOwners(nil, "", nil)
+	assert.Empty(t, add)
+	assert.Empty(t, remove)
+}
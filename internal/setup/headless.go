@@ -0,0 +1,192 @@
+package setup
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"github.com/hbjs97/ctx/internal/fsutil"
+)
+
+// ErrNonInteractive는 비대화형 모드에서 사용자 입력이 필요할 때 반환된다.
+var ErrNonInteractive = errors.New("비대화형 모드에서 입력이 필요합니다")
+
+// ProfileSpec은 비대화형 setup에 사용하는 프로필 선언이다.
+type ProfileSpec struct {
+	Name     string `toml:"name"`
+	GitName  string `toml:"git_name"`
+	GitEmail string `toml:"git_email"`
+	// SSHKey는 SSH 키 선택이다: "existing:<개인 키 경로>" | "generate" | "skip".
+	// 비어있으면 ssh_host가 있을 때 "skip", 없으면 "generate"로 간주한다.
+	SSHKey string `toml:"ssh_key"`
+	// SSHHost는 ssh_key가 "skip"일 때 사용할 기존 SSH Host alias다.
+	SSHHost string   `toml:"ssh_host"`
+	Owners  []string `toml:"owners"`
+}
+
+// Spec은 선언적 프로필 파일(TOML)의 최상위 구조다.
+//
+//	[[profiles]]
+//	name = "work"
+//	git_name = "HBJS"
+//	git_email = "hbjs@company.com"
+//	ssh_key = "existing:~/.ssh/id_work"
+//	owners = ["acme"]
+type Spec struct {
+	Profiles []ProfileSpec `toml:"profiles"`
+}
+
+// LoadSpec은 선언적 프로필 파일을 파싱한다.
+func LoadSpec(path string) (*Spec, error) {
+	var spec Spec
+	if _, err := toml.DecodeFile(path, &spec); err != nil {
+		return nil, fmt.Errorf("setup.LoadSpec: %w", err)
+	}
+	if len(spec.Profiles) == 0 {
+		return nil, fmt.Errorf("setup.LoadSpec: %s에 [[profiles]]가 없습니다", path)
+	}
+	return &spec, nil
+}
+
+// HeadlessFormRunner는 ProfileSpec 목록으로 동작하는 비대화형 FormRunner 구현이다.
+// 입력이 부족하면 프롬프트 대신 ErrNonInteractive를 감싼 에러를 반환한다.
+type HeadlessFormRunner struct {
+	Specs []ProfileSpec
+	// Yes가 true면 확인 프롬프트를 자동 승인한다.
+	Yes bool
+
+	idx int
+}
+
+var _ FormRunner = (*HeadlessFormRunner)(nil)
+
+// Remaining은 아직 처리되지 않은 프로필 선언 수를 반환한다.
+func (h *HeadlessFormRunner) Remaining() int {
+	return len(h.Specs) - h.idx
+}
+
+// current는 마지막으로 RunProfileForm이 소비한 선언을 반환한다.
+func (h *HeadlessFormRunner) current() (*ProfileSpec, error) {
+	if h.idx == 0 || h.idx > len(h.Specs) {
+		return nil, fmt.Errorf("setup.HeadlessFormRunner: 처리 중인 프로필 없음: %w", ErrNonInteractive)
+	}
+	return &h.Specs[h.idx-1], nil
+}
+
+// RunProfileForm은 다음 프로필 선언을 검증하여 반환한다.
+func (h *HeadlessFormRunner) RunProfileForm(defaults *ProfileInput, existingNames []string) (*ProfileInput, error) {
+	if h.idx >= len(h.Specs) {
+		return nil, fmt.Errorf("setup.RunProfileForm: 남은 프로필 선언 없음: %w", ErrNonInteractive)
+	}
+	spec := h.Specs[h.idx]
+	h.idx++
+
+	if !profileNameRegex.MatchString(spec.Name) {
+		return nil, fmt.Errorf("setup.RunProfileForm: 잘못된 프로필 이름 %q (영문, 숫자, 하이픈만 허용)", spec.Name)
+	}
+	for _, n := range existingNames {
+		if n == spec.Name && (defaults == nil || defaults.Name != spec.Name) {
+			return nil, fmt.Errorf("setup.RunProfileForm: 이미 존재하는 프로필 이름입니다: %s", spec.Name)
+		}
+	}
+	if spec.GitName == "" {
+		return nil, fmt.Errorf("setup.RunProfileForm: 프로필 %q git_name 필수", spec.Name)
+	}
+	if !strings.Contains(spec.GitEmail, "@") {
+		return nil, fmt.Errorf("setup.RunProfileForm: 프로필 %q git_email 형식 오류: %q", spec.Name, spec.GitEmail)
+	}
+
+	return &ProfileInput{
+		Name:     spec.Name,
+		GitName:  spec.GitName,
+		GitEmail: spec.GitEmail,
+		SSHHost:  spec.SSHHost,
+		Owners:   spec.Owners,
+	}, nil
+}
+
+// RunActionSelect는 항상 프로필 추가를 선택한다.
+func (h *HeadlessFormRunner) RunActionSelect(profileNames []string) (Action, error) {
+	return ActionAdd, nil
+}
+
+// RunProfileSelect는 비대화형 모드에서 지원하지 않는다.
+func (h *HeadlessFormRunner) RunProfileSelect(profileNames []string) (string, error) {
+	return "", fmt.Errorf("setup.RunProfileSelect: 프로필 선택: %w", ErrNonInteractive)
+}
+
+// RunConfirm은 Yes가 true면 승인하고, 아니면 에러를 반환한다.
+func (h *HeadlessFormRunner) RunConfirm(message string) (bool, error) {
+	if h.Yes {
+		return true, nil
+	}
+	return false, fmt.Errorf("setup.RunConfirm: %q 확인 필요 (--yes로 승인): %w", message, ErrNonInteractive)
+}
+
+// RunAddMore는 남은 프로필 선언이 있으면 true를 반환한다.
+func (h *HeadlessFormRunner) RunAddMore() (bool, error) {
+	return h.Remaining() > 0, nil
+}
+
+// RunSSHHostSelect는 선언된 ssh_host를 반환한다.
+func (h *HeadlessFormRunner) RunSSHHostSelect(hosts []string) (string, error) {
+	spec, err := h.current()
+	if err != nil {
+		return "", err
+	}
+	if spec.SSHHost == "" {
+		return "", fmt.Errorf("setup.RunSSHHostSelect: 프로필 %q ssh_host 필요 (감지된 host: %s): %w",
+			spec.Name, strings.Join(hosts, ", "), ErrNonInteractive)
+	}
+	return spec.SSHHost, nil
+}
+
+// RunOwnersSelect는 선언된 owners를 반환한다. 선언이 없으면 감지된 목록을 사용한다.
+func (h *HeadlessFormRunner) RunOwnersSelect(detected []string) ([]string, error) {
+	spec, err := h.current()
+	if err != nil {
+		return nil, err
+	}
+	if len(spec.Owners) > 0 {
+		return spec.Owners, nil
+	}
+	if len(detected) > 0 {
+		return detected, nil
+	}
+	return nil, fmt.Errorf("setup.RunOwnersSelect: 프로필 %q owners 필요 (조직 자동 감지 실패): %w", spec.Name, ErrNonInteractive)
+}
+
+// RunSSHKeySelect는 선언된 ssh_key를 SSHKeyChoice로 변환한다.
+func (h *HeadlessFormRunner) RunSSHKeySelect(existingKeys []SSHKeyInfo, profileName string) (SSHKeyChoice, error) {
+	spec, err := h.current()
+	if err != nil {
+		return SSHKeyChoice{}, err
+	}
+	return ParseSSHKeyChoice(spec.SSHKey, spec.SSHHost)
+}
+
+// ParseSSHKeyChoice는 "existing:<경로>" | "generate" | "skip" 형식의 SSH 키 선언을 해석한다.
+// 값이 비어있으면 sshHost가 있을 때 skip, 없을 때 generate로 간주한다.
+func ParseSSHKeyChoice(value, sshHost string) (SSHKeyChoice, error) {
+	switch {
+	case value == "" && sshHost != "":
+		return SSHKeyChoice{Action: "skip"}, nil
+	case value == "" || value == "generate":
+		return SSHKeyChoice{Action: "generate"}, nil
+	case value == "skip":
+		return SSHKeyChoice{Action: "skip"}, nil
+	case strings.HasPrefix(value, "existing:"):
+		path := fsutil.ExpandHome(strings.TrimPrefix(value, "existing:"))
+		if _, err := os.Stat(path); err != nil {
+			return SSHKeyChoice{}, fmt.Errorf("setup.ParseSSHKeyChoice: 개인 키 없음: %s", path)
+		}
+		if _, err := os.Stat(path + ".pub"); err != nil {
+			return SSHKeyChoice{}, fmt.Errorf("setup.ParseSSHKeyChoice: 공개 키 없음: %s.pub", path)
+		}
+		return SSHKeyChoice{Action: "existing", ExistingKey: path}, nil
+	default:
+		return SSHKeyChoice{}, fmt.Errorf("setup.ParseSSHKeyChoice: 알 수 없는 ssh_key %q (existing:<경로> | generate | skip)", value)
+	}
+}
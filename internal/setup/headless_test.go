@@ -0,0 +1,214 @@
+package setup
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/hbjs97/ctx/internal/config"
+	"github.com/hbjs97/ctx/internal/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadSpec(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "profiles.toml")
+	require.NoError(t, os.WriteFile(path, []byte(`
+[[profiles]]
+name = "work"
+git_name = "Work"
+git_email = "w@acme.com"
+ssh_key = "generate"
+owners = ["acme"]
+
+[[profiles]]
+name = "personal"
+git_name = "Me"
+git_email = "me@example.com"
+ssh_host = "github.com-personal"
+`), 0600))
+
+	spec, err := LoadSpec(path)
+	require.NoError(t, err)
+	require.Len(t, spec.Profiles, 2)
+	assert.Equal(t, "generate", spec.Profiles[0].SSHKey)
+	assert.Equal(t, "github.com-personal", spec.Profiles[1].SSHHost)
+}
+
+func TestLoadSpec_Empty(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "empty.toml")
+	require.NoError(t, os.WriteFile(path, []byte(""), 0600))
+	_, err := LoadSpec(path)
+	assert.Error(t, err)
+}
+
+func TestParseSSHKeyChoice(t *testing.T) {
+	dir := t.TempDir()
+	key := filepath.Join(dir, "id_work")
+	require.NoError(t, os.WriteFile(key, []byte("private"), 0600))
+	require.NoError(t, os.WriteFile(key+".pub", []byte("public"), 0644))
+
+	tests := []struct {
+		name    string
+		value   string
+		sshHost string
+		want    SSHKeyChoice
+		wantErr bool
+	}{
+		{name: "empty with host", value: "", sshHost: "github.com-work", want: SSHKeyChoice{Action: "skip"}},
+		{name: "empty without host", value: "", want: SSHKeyChoice{Action: "generate"}},
+		{name: "generate", value: "generate", want: SSHKeyChoice{Action: "generate"}},
+		{name: "skip", value: "skip", want: SSHKeyChoice{Action: "skip"}},
+		{name: "existing", value: "existing:" + key, want: SSHKeyChoice{Action: "existing", ExistingKey: key}},
+		{name: "existing missing", value: "existing:" + filepath.Join(dir, "nope"), wantErr: true},
+		{name: "unknown", value: "magic", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseSSHKeyChoice(tt.value, tt.sshHost)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestHeadlessFormRunner_ValidatesInput(t *testing.T) {
+	h := &HeadlessFormRunner{Specs: []ProfileSpec{{Name: "bad name", GitName: "n", GitEmail: "e@x.com"}}}
+	_, err := h.RunProfileForm(nil, nil)
+	assert.Error(t, err)
+
+	h = &HeadlessFormRunner{Specs: []ProfileSpec{{Name: "work", GitName: "n", GitEmail: "no-at"}}}
+	_, err = h.RunProfileForm(nil, nil)
+	assert.Error(t, err)
+
+	h = &HeadlessFormRunner{Specs: []ProfileSpec{{Name: "work", GitName: "n", GitEmail: "e@x.com"}}}
+	_, err = h.RunProfileForm(nil, []string{"work"})
+	assert.Error(t, err)
+}
+
+func TestHeadlessFormRunner_ConfirmRequiresYes(t *testing.T) {
+	h := &HeadlessFormRunner{}
+	_, err := h.RunConfirm("삭제?")
+	assert.True(t, errors.Is(err, ErrNonInteractive))
+
+	h.Yes = true
+	ok, err := h.RunConfirm("삭제?")
+	require.NoError(t, err)
+	assert.True(t, ok)
+}
+
+func TestHeadlessFormRunner_OwnersFallbackToDetected(t *testing.T) {
+	h := &HeadlessFormRunner{Specs: []ProfileSpec{{Name: "work", GitName: "n", GitEmail: "e@x.com"}}}
+	_, err := h.RunProfileForm(nil, nil)
+	require.NoError(t, err)
+
+	owners, err := h.RunOwnersSelect([]string{"detected-org"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"detected-org"}, owners)
+
+	_, err = h.RunOwnersSelect(nil)
+	assert.True(t, errors.Is(err, ErrNonInteractive))
+}
+
+func TestRunner_Headless_MultipleProfiles(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("SHELL", "")
+	dir := t.TempDir()
+	cfgPath := dir + "/config.toml"
+	sshDir := dir + "/ssh"
+	require.NoError(t, os.MkdirAll(sshDir, 0700))
+
+	fc := testutil.NewFakeCommander()
+	fc.Register("ssh-keygen", "", nil)
+	fc.Register("gh api user/orgs --jq .[].login", "acme\n", nil)
+	fc.Register("gh api user --jq .login", "me\n", nil)
+	registerDoctorCommands(fc)
+
+	h := &HeadlessFormRunner{Specs: []ProfileSpec{
+		{Name: "work", GitName: "Work", GitEmail: "w@acme.com", SSHKey: "generate", Owners: []string{"acme"}},
+		{Name: "personal", GitName: "Me", GitEmail: "me@example.com", SSHHost: "github.com-personal"},
+	}}
+	r := &Runner{
+		CfgPath:        cfgPath,
+		Commander:      fc,
+		FormRunner:     h,
+		SSHDir:         sshDir,
+		SSHConfigPath:  sshDir + "/config",
+		NonInteractive: true,
+	}
+
+	require.NoError(t, r.Run(context.Background()))
+	assert.Equal(t, 0, h.Remaining())
+	assert.False(t, fc.Called("gh auth login"), "비대화형 모드에서는 gh auth login을 실행하지 않는다")
+
+	cfg, err := config.Load(cfgPath)
+	require.NoError(t, err)
+	assert.Equal(t, "github.com-work", cfg.Profiles["work"].SSHHost)
+	assert.Equal(t, "github.com-personal", cfg.Profiles["personal"].SSHHost)
+	assert.Equal(t, []string{"acme", "me"}, cfg.Profiles["personal"].Owners)
+}
+
+func TestRunner_Headless_ShellHookOnlyWhenRequested(t *testing.T) {
+	for _, shellHook := range []bool{false, true} {
+		t.Run(fmt.Sprintf("ShellHook=%v", shellHook), func(t *testing.T) {
+			home := t.TempDir()
+			t.Setenv("HOME", home)
+			t.Setenv("SHELL", "/bin/bash")
+			dir := t.TempDir()
+
+			fc := testutil.NewFakeCommander()
+			fc.Register("gh api user/orgs --jq .[].login", "", nil)
+			fc.Register("gh api user --jq .login", "me\n", nil)
+			registerDoctorCommands(fc)
+
+			r := &Runner{
+				CfgPath:   filepath.Join(dir, "config.toml"),
+				Commander: fc,
+				FormRunner: &HeadlessFormRunner{Specs: []ProfileSpec{
+					{Name: "personal", GitName: "Me", GitEmail: "me@example.com", SSHHost: "github.com-personal"},
+				}},
+				SSHDir:         dir,
+				SSHConfigPath:  filepath.Join(dir, "config"),
+				NonInteractive: true,
+				ShellHook:      shellHook,
+			}
+			require.NoError(t, r.Run(context.Background()))
+
+			_, err := os.Stat(filepath.Join(home, ".bashrc"))
+			assert.Equal(t, shellHook, err == nil, "비대화형 설정은 요청했을 때만 RC 파일을 수정한다")
+		})
+	}
+}
+
+func TestRunner_Headless_RequiresGHAuth(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	dir := t.TempDir()
+
+	fc := testutil.NewFakeCommander()
+	fc.Register("gh auth status", "", fmt.Errorf("not logged in"))
+
+	r := &Runner{
+		CfgPath:   dir + "/config.toml",
+		Commander: fc,
+		FormRunner: &HeadlessFormRunner{Specs: []ProfileSpec{
+			{Name: "work", GitName: "Work", GitEmail: "w@acme.com", SSHHost: "github.com-work"},
+		}},
+		SSHConfigPath:  dir + "/ssh_config",
+		NonInteractive: true,
+	}
+
+	err := r.Run(context.Background())
+	require.Error(t, err)
+	assert.True(t, errors.Is(err, ErrNonInteractive))
+	assert.Contains(t, err.Error(), "gh auth login")
+	_, statErr := os.Stat(dir + "/config.toml")
+	assert.True(t, os.IsNotExist(statErr), "실패 시 설정 파일을 만들지 않는다")
+}
@@ -0,0 +1,324 @@
+package setup
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/hbjs97/ctx/internal/config"
+	"github.com/hbjs97/ctx/internal/gitinclude"
+)
+
+// ImportCandidate는 기존 환경(gh hosts.yml, includeIf, SSH config)에서 감지된 프로필 후보다.
+type ImportCandidate struct {
+	Name         string
+	GHConfigDir  string
+	Login        string
+	SSHHost      string
+	IdentityFile string
+	GitName      string
+	GitEmail     string
+	Owners       []string
+	GitDirs      []string
+}
+
+// includeGroup은 같은 gitconfig 파일을 가리키는 includeIf 항목들의 묶음이다.
+type includeGroup struct {
+	path    string
+	owners  []string
+	gitDirs []string
+	aliases []string
+}
+
+var (
+	includeOwnerPattern = regexp.MustCompile(`^hasconfig:remote\.\*\.url:(?:git@([^:]+):|https://github\.com/)([^/]+)/`)
+	invalidNameChars    = regexp.MustCompile(`[^a-zA-Z0-9-]+`)
+)
+
+// ParseGHHostsLogin은 gh의 hosts.yml에서 github.com 로그인 사용자명을 추출한다.
+// 파일이 없거나 로그인 정보가 없으면 빈 문자열을 반환한다.
+func ParseGHHostsLogin(path string) string {
+	f, err := os.Open(path)
+	if err != nil {
+		return ""
+	}
+	defer f.Close()
+
+	inGitHub := false
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		raw := scanner.Text()
+		line := strings.TrimSpace(raw)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if !strings.HasPrefix(raw, " ") && !strings.HasPrefix(raw, "\t") {
+			inGitHub = strings.TrimSuffix(line, ":") == "github.com"
+			continue
+		}
+		if inGitHub && strings.HasPrefix(line, "user:") {
+			return strings.Trim(strings.TrimSpace(strings.TrimPrefix(line, "user:")), `"'`)
+		}
+	}
+	return ""
+}
+
+// DetectGHConfigDirs는 root(보통 ~/.config) 아래의 gh, gh-* 디렉토리 중 hosts.yml이 있는 것을 찾는다.
+// GH_CONFIG_DIR 환경변수가 설정되어 있으면 함께 포함한다.
+func DetectGHConfigDirs(root string) []string {
+	seen := make(map[string]bool)
+	var dirs []string
+	add := func(dir string) {
+		if dir == "" || seen[dir] {
+			return
+		}
+		if _, err := os.Stat(filepath.Join(dir, "hosts.yml")); err != nil {
+			return
+		}
+		seen[dir] = true
+		dirs = append(dirs, dir)
+	}
+
+	entries, _ := os.ReadDir(root) // 디렉토리가 없으면 빈 목록
+	for _, e := range entries {
+		if e.IsDir() && (e.Name() == "gh" || strings.HasPrefix(e.Name(), "gh-")) {
+			add(filepath.Join(root, e.Name()))
+		}
+	}
+	add(os.Getenv("GH_CONFIG_DIR"))
+	return dirs
+}
+
+// DetectImportCandidates는 기존 멀티계정 설정에서 프로필 후보를 만든다.
+// gh 인증이 완료된 config 디렉토리마다 하나의 후보를 만들고,
+// SSH Host alias와 includeIf로 지정된 identity/owners를 연결한다.
+func (r *Runner) DetectImportCandidates(ctx context.Context) []ImportCandidate {
+	sshConfigPath := r.sshConfigPath()
+	sshHosts := ParseSSHConfig(sshConfigPath)
+	identityFiles := ParseSSHConfigIdentityFiles(sshConfigPath)
+	groups := parseIncludeGroups(r.gitConfigPath())
+
+	var candidates []ImportCandidate
+	for _, dir := range DetectGHConfigDirs(r.ghConfigRoot()) {
+		login := ParseGHHostsLogin(filepath.Join(dir, "hosts.yml"))
+		if login == "" {
+			continue
+		}
+
+		c := ImportCandidate{
+			Name:        importProfileName(dir, login),
+			GHConfigDir: dir,
+			Login:       login,
+		}
+		c.SSHHost = matchSSHHost(sshHosts, c.Name, login)
+		c.IdentityFile = identityFiles[c.SSHHost]
+
+		// 이미 인증된 계정이므로 gh auth login 없이 조직을 조회한다.
+		c.Owners = DetectOrgs(ctx, r.Commander, dir)
+		if len(c.Owners) == 0 {
+			c.Owners = []string{login}
+		}
+
+		if g := matchIncludeGroup(groups, c); g != nil {
+			c.GitName, c.GitEmail = gitinclude.ReadIdentity(g.path)
+			c.Owners = mergeOwners(c.Owners, g.owners)
+			c.GitDirs = g.gitDirs
+		}
+		candidates = append(candidates, c)
+	}
+
+	sort.Slice(candidates, func(i, j int) bool { return candidates[i].Name < candidates[j].Name })
+	return candidates
+}
+
+// runImport는 기존 설정을 가져와 사용자 확인 후 프로필로 저장한다.
+func (r *Runner) runImport(ctx context.Context) error {
+	candidates := r.DetectImportCandidates(ctx)
+	if len(candidates) == 0 {
+		return fmt.Errorf("setup.Import: 가져올 gh 인증 정보가 없습니다 (hosts.yml 미발견)")
+	}
+
+	cfg := &config.Config{Version: 1, Profiles: make(map[string]config.Profile)}
+	if _, err := os.Stat(r.CfgPath); err == nil {
+		loaded, err := config.Load(r.CfgPath)
+		if err != nil {
+			return err
+		}
+		cfg = loaded
+	}
+
+	usedDirs := make(map[string]bool)
+	for _, p := range cfg.Profiles {
+		usedDirs[p.GHConfigDir] = true
+	}
+
+	imported := 0
+	for _, c := range candidates {
+		if usedDirs[c.GHConfigDir] {
+			fmt.Printf("건너뜀: %s (이미 프로필에 등록된 gh 설정)\n", c.GHConfigDir)
+			continue
+		}
+
+		fmt.Printf("\n감지된 계정: %s (%s)\n", c.Login, c.GHConfigDir)
+		if c.SSHHost != "" {
+			fmt.Printf("  SSH host: %s\n", c.SSHHost)
+		}
+		if c.GitEmail != "" {
+			fmt.Printf("  includeIf identity: %s <%s>\n", c.GitName, c.GitEmail)
+		}
+
+		existingNames := make([]string, 0, len(cfg.Profiles))
+		for name := range cfg.Profiles {
+			existingNames = append(existingNames, name)
+		}
+		input, err := r.FormRunner.RunProfileForm(&ProfileInput{
+			Name:     c.Name,
+			GitName:  c.GitName,
+			GitEmail: c.GitEmail,
+			SSHHost:  c.SSHHost,
+			Owners:   c.Owners,
+		}, existingNames)
+		if err != nil {
+			return err
+		}
+
+		sshHost := input.SSHHost
+		if sshHost == "" {
+			sshHost, err = r.FormRunner.RunSSHHostSelect(ParseSSHConfig(r.sshConfigPath()))
+			if err != nil {
+				return err
+			}
+		}
+
+		owners, err := r.FormRunner.RunOwnersSelect(c.Owners)
+		if err != nil {
+			return err
+		}
+
+		ok, err := r.FormRunner.RunConfirm(fmt.Sprintf("프로필 %q으로 가져오시겠습니까?", input.Name))
+		if err != nil {
+			return err
+		}
+		if !ok {
+			continue
+		}
+
+		cfg.Profiles[input.Name] = config.Profile{
+			GHConfigDir:  c.GHConfigDir,
+			SSHHost:      sshHost,
+			IdentityFile: c.IdentityFile,
+			GitName:      input.GitName,
+			GitEmail:     input.GitEmail,
+			Owners:       owners,
+			GitDirs:      c.GitDirs,
+			GitHubUser:   c.Login,
+		}
+		usedDirs[c.GHConfigDir] = true
+		imported++
+	}
+
+	if imported == 0 {
+		fmt.Println("가져온 프로필이 없습니다.")
+		return nil
+	}
+
+	if err := r.save(cfg); err != nil {
+		return err
+	}
+	fmt.Printf("%d개 프로필을 가져왔습니다: %s\n", imported, r.CfgPath)
+	r.runDoctor(ctx, cfg)
+	return nil
+}
+
+// parseIncludeGroups는 전역 git config의 includeIf 항목을 대상 파일 기준으로 묶는다.
+func parseIncludeGroups(globalPath string) []*includeGroup {
+	byPath := make(map[string]*includeGroup)
+	var groups []*includeGroup
+	for _, inc := range gitinclude.ParseIncludes(globalPath) {
+		g, ok := byPath[inc.Path]
+		if !ok {
+			g = &includeGroup{path: inc.Path}
+			byPath[inc.Path] = g
+			groups = append(groups, g)
+		}
+		if dir, ok := strings.CutPrefix(inc.Condition, "gitdir:"); ok {
+			g.gitDirs = append(g.gitDirs, dir)
+			continue
+		}
+		if m := includeOwnerPattern.FindStringSubmatch(inc.Condition); m != nil {
+			g.owners = mergeOwners(g.owners, []string{m[2]})
+			if m[1] != "" && m[1] != "github.com" {
+				g.aliases = append(g.aliases, m[1])
+			}
+		}
+	}
+	return groups
+}
+
+// matchIncludeGroup은 후보의 SSH alias 또는 owners와 겹치는 includeIf 묶음을 찾는다.
+func matchIncludeGroup(groups []*includeGroup, c ImportCandidate) *includeGroup {
+	for _, g := range groups {
+		for _, a := range g.aliases {
+			if a == c.SSHHost {
+				return g
+			}
+		}
+	}
+	for _, g := range groups {
+		for _, o := range g.owners {
+			for _, co := range c.Owners {
+				if o == co {
+					return g
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// matchSSHHost는 프로필 이름이나 로그인에 해당하는 GitHub Host alias를 찾는다.
+// alias가 이름과 같거나 "-<이름>"으로 끝나야 한다 (github.com-work).
+// 부분 문자열로 찾으면 "work"가 github.com-work2나 github.com-coworker에 잘못 맞는다.
+func matchSSHHost(hosts []string, name, login string) string {
+	for _, needle := range []string{name, login} {
+		needle = strings.ToLower(needle)
+		if needle == "" {
+			continue
+		}
+		for _, h := range hosts {
+			if alias := strings.ToLower(h); alias == needle || strings.HasSuffix(alias, "-"+needle) {
+				return h
+			}
+		}
+	}
+	return ""
+}
+
+// importProfileName은 gh config 디렉토리 이름에서 프로필 이름을 정한다.
+// gh-work → work, 기본 gh 디렉토리는 로그인 이름을 사용한다.
+func importProfileName(dir, login string) string {
+	name := strings.TrimPrefix(filepath.Base(dir), "gh-")
+	if name == "gh" || name == "" {
+		name = login
+	}
+	return strings.Trim(invalidNameChars.ReplaceAllString(name, "-"), "-")
+}
+
+func mergeOwners(a, b []string) []string {
+	seen := make(map[string]bool, len(a))
+	result := make([]string, 0, len(a)+len(b))
+	for _, list := range [][]string{a, b} {
+		for _, o := range list {
+			if !seen[o] {
+				seen[o] = true
+				result = append(result, o)
+			}
+		}
+	}
+	return result
+}
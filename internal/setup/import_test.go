@@ -0,0 +1,157 @@
+package setup
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/hbjs97/ctx/internal/config"
+	"github.com/hbjs97/ctx/internal/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	require.NoError(t, os.MkdirAll(filepath.Dir(path), 0700))
+	require.NoError(t, os.WriteFile(path, []byte(content), 0600))
+}
+
+func TestParseGHHostsLogin(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "hosts.yml")
+	writeFile(t, path, `github.com:
+    users:
+        hbjs97:
+            oauth_token: gho_xxx
+    git_protocol: ssh
+    oauth_token: gho_xxx
+    user: hbjs97
+ghe.example.com:
+    user: other
+`)
+	assert.Equal(t, "hbjs97", ParseGHHostsLogin(path))
+	assert.Empty(t, ParseGHHostsLogin(filepath.Join(dir, "missing.yml")))
+}
+
+func TestDetectGHConfigDirs(t *testing.T) {
+	t.Setenv("GH_CONFIG_DIR", "")
+	root := t.TempDir()
+	writeFile(t, filepath.Join(root, "gh", "hosts.yml"), "github.com:\n    user: me\n")
+	writeFile(t, filepath.Join(root, "gh-work", "hosts.yml"), "github.com:\n    user: corp\n")
+	require.NoError(t, os.MkdirAll(filepath.Join(root, "gh-empty"), 0700))
+	require.NoError(t, os.MkdirAll(filepath.Join(root, "other"), 0700))
+
+	dirs := DetectGHConfigDirs(root)
+	assert.Equal(t, []string{filepath.Join(root, "gh"), filepath.Join(root, "gh-work")}, dirs)
+}
+
+func newImportFixture(t *testing.T) (*Runner, *testutil.FakeCommander, string) {
+	t.Helper()
+	t.Setenv("GH_CONFIG_DIR", "")
+	dir := t.TempDir()
+	root := filepath.Join(dir, "config")
+	writeFile(t, filepath.Join(root, "gh", "hosts.yml"), "github.com:\n    user: me\n")
+	writeFile(t, filepath.Join(root, "gh-work", "hosts.yml"), "github.com:\n    user: corp-me\n")
+	writeFile(t, filepath.Join(dir, "ssh_config"), `Host github.com-work
+    HostName github.com
+    IdentityFile ~/.ssh/id_work
+
+Host github.com-me
+    HostName github.com
+    IdentityFile ~/.ssh/id_me
+`)
+	writeFile(t, filepath.Join(dir, "work.gitconfig"), "[user]\n\tname = Corp Me\n\temail = me@acme.com\n")
+	writeFile(t, filepath.Join(dir, ".gitconfig"), `[includeIf "hasconfig:remote.*.url:git@github.com:acme/**"]
+	path = `+filepath.Join(dir, "work.gitconfig")+`
+[includeIf "gitdir:~/work/"]
+	path = `+filepath.Join(dir, "work.gitconfig")+`
+`)
+
+	fc := testutil.NewFakeCommander()
+	fc.Register("gh api user/orgs --jq .[].login", "acme\n", nil)
+	fc.Register("gh api user --jq .login", "corp-me\n", nil)
+	registerDoctorCommands(fc)
+
+	r := &Runner{
+		CfgPath:       filepath.Join(dir, "ctx", "config.toml"),
+		Commander:     fc,
+		SSHConfigPath: filepath.Join(dir, "ssh_config"),
+		GitConfigPath: filepath.Join(dir, ".gitconfig"),
+		GHConfigRoot:  root,
+		Import:        true,
+	}
+	return r, fc, root
+}
+
+func TestDetectImportCandidates(t *testing.T) {
+	r, _, root := newImportFixture(t)
+
+	candidates := r.DetectImportCandidates(context.Background())
+	require.Len(t, candidates, 2)
+
+	me := candidates[0]
+	assert.Equal(t, "me", me.Name)
+	assert.Equal(t, filepath.Join(root, "gh"), me.GHConfigDir)
+	assert.Equal(t, "github.com-me", me.SSHHost)
+
+	work := candidates[1]
+	assert.Equal(t, "work", work.Name)
+	assert.Equal(t, "corp-me", work.Login)
+	assert.Equal(t, "github.com-work", work.SSHHost)
+	assert.Equal(t, "~/.ssh/id_work", work.IdentityFile)
+	assert.Equal(t, "me@acme.com", work.GitEmail)
+	assert.Equal(t, []string{"~/work/"}, work.GitDirs)
+	assert.Contains(t, work.Owners, "acme")
+}
+
+func TestMatchSSHHost(t *testing.T) {
+	hosts := []string{"github.com-coworker", "github.com-work2", "github.com-work", "gh-corp-me"}
+
+	assert.Equal(t, "github.com-work", matchSSHHost(hosts, "work", "someone"), "겹치는 alias가 있어도 정확히 맞는 것을 고른다")
+	assert.Equal(t, "gh-corp-me", matchSSHHost(hosts, "corp", "corp-me"), "이름이 없으면 로그인으로 찾는다")
+	assert.Equal(t, "", matchSSHHost(hosts, "ork", "nobody"), "부분 문자열은 맞지 않는다")
+	assert.Equal(t, "", matchSSHHost([]string{"github.com-coworker", "github.com-work2"}, "work", "me"))
+}
+
+func TestRunner_Import_SavesConfirmedProfiles(t *testing.T) {
+	r, fc, root := newImportFixture(t)
+	r.FormRunner = &mockFormRunner{
+		profileInputs: []*ProfileInput{
+			{Name: "me", GitName: "Me", GitEmail: "me@example.com", SSHHost: "github.com-me"},
+			{Name: "work", GitName: "Corp Me", GitEmail: "me@acme.com", SSHHost: "github.com-work"},
+		},
+		owners:   []string{"acme"},
+		confirms: []bool{false, true}, // me는 건너뛰고 work만 가져온다
+	}
+
+	require.NoError(t, r.Run(context.Background()))
+
+	cfg, err := config.Load(r.CfgPath)
+	require.NoError(t, err)
+	require.Len(t, cfg.Profiles, 1)
+	work := cfg.Profiles["work"]
+	assert.Equal(t, filepath.Join(root, "gh-work"), work.GHConfigDir)
+	assert.Equal(t, "me@acme.com", work.GitEmail)
+	assert.Equal(t, []string{"~/work/"}, work.GitDirs)
+	assert.Equal(t, "corp-me", work.GitHubUser)
+
+	// 이미 인증된 계정은 gh auth login을 다시 실행하지 않는다
+	assert.False(t, fc.Called("gh auth login"))
+}
+
+func TestRunner_Import_NothingFound(t *testing.T) {
+	t.Setenv("GH_CONFIG_DIR", "")
+	dir := t.TempDir()
+	r := &Runner{
+		CfgPath:       filepath.Join(dir, "config.toml"),
+		Commander:     testutil.NewFakeCommander(),
+		FormRunner:    &mockFormRunner{},
+		SSHConfigPath: filepath.Join(dir, "ssh_config"),
+		GitConfigPath: filepath.Join(dir, ".gitconfig"),
+		GHConfigRoot:  dir,
+		Import:        true,
+	}
+	assert.Error(t, r.Run(context.Background()))
+}
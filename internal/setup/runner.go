@@ -11,7 +11,9 @@ import (
 	"github.com/hbjs97/ctx/internal/cmdexec"
 	"github.com/hbjs97/ctx/internal/config"
 	"github.com/hbjs97/ctx/internal/doctor"
+	"github.com/hbjs97/ctx/internal/fsutil"
 	"github.com/hbjs97/ctx/internal/gh"
+	"github.com/hbjs97/ctx/internal/gitinclude"
 )
 
 // Runner는 interactive setup의 진입점이다.
@@ -21,10 +23,30 @@ type Runner struct {
 	FormRunner    FormRunner
 	SSHConfigPath string // 테스트용. 비어있으면 기본 경로.
 	SSHDir        string // 테스트용. 비어있으면 ~/.ssh.
+	GitConfigPath string // 테스트용. 비어있으면 ~/.gitconfig.
+	GitIncludeDir string // 테스트용. 비어있으면 ~/.config/ctx/git.
+	GHConfigRoot  string // 테스트용. 비어있으면 ~/.config.
+
+	// IncludeIf가 true면 프로필별 gitconfig 파일과 includeIf 항목을 생성하도록 설정을 켠다.
+	IncludeIf bool
+
+	// Import가 true면 기존 includeIf, gh hosts.yml, SSH config에서 프로필을 가져온다.
+	Import bool
+
+	// NonInteractive가 true면 gh auth login 등 터미널 입력이 필요한 단계를 실행하지 않고
+	// 사전 조건이 충족되지 않으면 ErrNonInteractive로 실패한다.
+	NonInteractive bool
+
+	// ShellHook이 true면 NonInteractive 첫 설정에서도 셸 hook을 RC 파일에 설치한다.
+	// 대화형 첫 설정은 항상 설치한다.
+	ShellHook bool
 }
 
 // Run은 setup 플로우를 실행한다.
 func (r *Runner) Run(ctx context.Context) error {
+	if r.Import {
+		return r.runImport(ctx)
+	}
 	_, err := os.Stat(r.CfgPath)
 	if os.IsNotExist(err) {
 		return r.runFirstTime(ctx)
@@ -39,8 +61,9 @@ func (r *Runner) runFirstTime(ctx context.Context) error {
 	fmt.Println("ctx 초기 설정을 시작합니다.")
 
 	cfg := &config.Config{
-		Version:  1,
-		Profiles: make(map[string]config.Profile),
+		Version:      1,
+		GitIncludeIf: r.IncludeIf,
+		Profiles:     make(map[string]config.Profile),
 	}
 
 	for {
@@ -49,11 +72,13 @@ func (r *Runner) runFirstTime(ctx context.Context) error {
 			return err
 		}
 		cfg.Profiles[profile.Name] = config.Profile{
-			GHConfigDir: r.ghConfigDir(profile.Name),
-			SSHHost:     profile.SSHHost,
-			GitName:     profile.GitName,
-			GitEmail:    profile.GitEmail,
-			Owners:      profile.Owners,
+			GHConfigDir:  r.ghConfigDir(profile.Name),
+			SSHHost:      profile.SSHHost,
+			IdentityFile: profile.IdentityFile,
+			GitName:      profile.GitName,
+			GitEmail:     profile.GitEmail,
+			Owners:       profile.Owners,
+			GitHubUser:   profile.GitHubUser,
 		}
 
 		more, err := r.FormRunner.RunAddMore()
@@ -62,15 +87,14 @@ func (r *Runner) runFirstTime(ctx context.Context) error {
 		}
 	}
 
-	if err := config.Save(r.CfgPath, cfg); err != nil {
+	if err := r.save(cfg); err != nil {
 		return err
 	}
 
 	fmt.Printf("설정 파일이 저장되었습니다: %s\n", r.CfgPath)
 
-	// 셸 hook 설치
-	shellType := DetectShell()
-	if shellType != "" {
+	// 셸 hook 설치. 비대화형 실행은 요청했을 때만 dotfile을 수정한다.
+	if shellType := DetectShell(); shellType != "" && (!r.NonInteractive || r.ShellHook) {
 		rcPath := ShellRCPath(shellType)
 		if rcPath != "" {
 			if err := InstallShellHook(shellType, rcPath); err != nil {
@@ -109,10 +133,7 @@ func (r *Runner) collectProfile(ctx context.Context, cfg *config.Config, default
 
 	// SSH 키 감지 + 사용 중인 키 필터링
 	sshDirPath := r.sshDir()
-	sshConfigPath := r.SSHConfigPath
-	if sshConfigPath == "" {
-		sshConfigPath = DefaultSSHConfigPath()
-	}
+	sshConfigPath := r.sshConfigPath()
 
 	allKeys := DetectSSHKeys(sshDirPath)
 	usedPaths := r.usedIdentityFiles(cfg, sshConfigPath)
@@ -131,20 +152,8 @@ func (r *Runner) collectProfile(ctx context.Context, cfg *config.Config, default
 			return nil, err
 		}
 		identityFile = keyPath
-		// SSH config에 Host 엔트리 자동 추가
-		sshHost := fmt.Sprintf("github.com-%s", input.Name)
-		if err := WriteSSHConfigEntry(sshConfigPath, sshHost, identityFile); err != nil {
-			return nil, err
-		}
-		input.SSHHost = sshHost
 	case "existing":
 		identityFile = keyChoice.ExistingKey
-		// SSH config에 Host 엔트리 자동 추가
-		sshHost := fmt.Sprintf("github.com-%s", input.Name)
-		if err := WriteSSHConfigEntry(sshConfigPath, sshHost, identityFile); err != nil {
-			return nil, err
-		}
-		input.SSHHost = sshHost
 	default:
 		// "skip" — 기존 SSH host 선택 플로우로 fallback
 		hosts := ParseSSHConfig(sshConfigPath)
@@ -155,6 +164,20 @@ func (r *Runner) collectProfile(ctx context.Context, cfg *config.Config, default
 		input.SSHHost = sshHost
 	}
 
+	if identityFile != "" {
+		// ctx 전용 include 파일에 Host 엔트리 추가 — 아래 SSH 연결 확인 전에 반영되어야 한다
+		input.SSHHost = fmt.Sprintf("github.com-%s", input.Name)
+		input.IdentityFile = identityFile
+		pending := make(map[string]config.Profile, len(cfg.Profiles)+1)
+		for name, p := range cfg.Profiles {
+			pending[name] = p
+		}
+		pending[input.Name] = config.Profile{SSHHost: input.SSHHost, IdentityFile: identityFile}
+		if err := SyncSSHInclude(sshConfigPath, r.sshIncludePath(cfg), pending); err != nil {
+			return nil, err
+		}
+	}
+
 	// gh auth login 실행
 	ghDir := r.ghConfigDir(input.Name)
 	if err := os.MkdirAll(ghDir, 0700); err != nil {
@@ -163,17 +186,25 @@ func (r *Runner) collectProfile(ctx context.Context, cfg *config.Config, default
 
 	env := gh.SuppressEnvTokens()
 	env["GH_CONFIG_DIR"] = ghDir
-	ghLoginArgs := []string{"auth", "login", "--hostname", "github.com", "--git-protocol", "ssh"}
-	if identityFile != "" {
-		ghLoginArgs = append(ghLoginArgs, "--ssh-key", identityFile+".pub")
-	}
-	err = r.Commander.RunInteractiveWithEnv(ctx, env, "gh", ghLoginArgs...)
-	if err != nil {
-		// gh auth login이 SSH 키 업로드 실패(422) 등으로 에러를 반환해도
-		// 인증 자체는 성공했을 수 있다. gh auth status로 실제 인증 상태를 확인한다.
-		_, statusErr := r.Commander.RunWithEnv(ctx, env, "gh", "auth", "status", "--hostname", "github.com")
-		if statusErr != nil {
-			fmt.Fprintf(os.Stderr, "경고: gh 인증 실패 — 나중에 직접 인증하세요\n")
+	if r.NonInteractive {
+		// 비대화형 모드에서는 gh auth login을 실행할 수 없으므로 사전 인증을 요구한다.
+		if _, err := r.Commander.RunWithEnv(ctx, env, "gh", "auth", "status", "--hostname", "github.com"); err != nil {
+			return nil, fmt.Errorf("setup: 프로필 %q gh 인증 필요 — GH_CONFIG_DIR=%s gh auth login 실행 후 재시도: %w",
+				input.Name, ghDir, ErrNonInteractive)
+		}
+	} else {
+		ghLoginArgs := []string{"auth", "login", "--hostname", "github.com", "--git-protocol", "ssh"}
+		if identityFile != "" {
+			ghLoginArgs = append(ghLoginArgs, "--ssh-key", identityFile+".pub")
+		}
+		err = r.Commander.RunInteractiveWithEnv(ctx, env, "gh", ghLoginArgs...)
+		if err != nil {
+			// gh auth login이 SSH 키 업로드 실패(422) 등으로 에러를 반환해도
+			// 인증 자체는 성공했을 수 있다. gh auth status로 실제 인증 상태를 확인한다.
+			_, statusErr := r.Commander.RunWithEnv(ctx, env, "gh", "auth", "status", "--hostname", "github.com")
+			if statusErr != nil {
+				fmt.Fprintf(os.Stderr, "경고: gh 인증 실패 — 나중에 직접 인증하세요\n")
+			}
 		}
 	}
 
@@ -183,12 +214,16 @@ func (r *Runner) collectProfile(ctx context.Context, cfg *config.Config, default
 	}
 
 	// 조직 조회 + 선택
-	detected := DetectOrgs(ctx, r.Commander, ghDir)
+	login, detected := DetectAccount(ctx, r.Commander, ghDir)
+	if login != "" {
+		detected = append(detected, login)
+	}
 	owners, err := r.FormRunner.RunOwnersSelect(detected)
 	if err != nil {
 		return nil, err
 	}
 	input.Owners = owners
+	input.GitHubUser = login
 
 	return input, nil
 }
@@ -219,6 +254,10 @@ func (r *Runner) ensureSSHKeyRegistered(ctx context.Context, env map[string]stri
 
 	// scope 부족(404)일 수 있음 — admin:public_key 권한 추가
 	fmt.Fprintf(os.Stderr, "SSH 키 등록에 admin:public_key 권한이 필요합니다.\n")
+	if r.NonInteractive {
+		r.printSSHKeyManualFix(pubKeyPath, title)
+		return
+	}
 	refreshErr := r.Commander.RunInteractiveWithEnv(ctx, env, "gh", "auth", "refresh", "-h", "github.com", "-s", "admin:public_key")
 	if refreshErr != nil {
 		r.printSSHKeyManualFix(pubKeyPath, title)
@@ -245,15 +284,11 @@ func (r *Runner) usedIdentityFiles(cfg *config.Config, sshConfigPath string) map
 	hostToKey := ParseSSHConfigIdentityFiles(sshConfigPath)
 	used := make(map[string]bool)
 	for _, p := range cfg.Profiles {
+		if p.IdentityFile != "" {
+			used[fsutil.ExpandHome(p.IdentityFile)] = true
+		}
 		if idFile, ok := hostToKey[p.SSHHost]; ok {
-			// ~ 경로를 절대 경로로 확장
-			if strings.HasPrefix(idFile, "~/") {
-				home, err := os.UserHomeDir()
-				if err == nil {
-					idFile = filepath.Join(home, idFile[2:])
-				}
-			}
-			used[idFile] = true
+			used[fsutil.ExpandHome(idFile)] = true
 		}
 	}
 	return used
@@ -270,10 +305,15 @@ func (r *Runner) ghConfigDir(profileName string) string {
 // runDoctor는 설정 완료 후 환경 진단을 실행한다.
 func (r *Runner) runDoctor(ctx context.Context, cfg *config.Config) {
 	fmt.Println("\n환경 진단 실행 중...")
-	for name, p := range cfg.Profiles {
-		fmt.Printf("\n[%s] 프로필 진단:\n", name)
-		results := doctor.RunAll(ctx, r.Commander, p.GHConfigDir, p.SSHHost)
-		for _, res := range results {
+	checks, _ := doctor.Select([]string{"binaries", "env_tokens", "gh_auth", "ssh"}) // 등록된 이름이므로 실패하지 않음
+	env := &doctor.Env{Commander: r.Commander, Config: cfg}
+	profile := ""
+	for _, tr := range doctor.RunTasks(ctx, env, doctor.Plan(checks, cfg), doctor.RunOptions{}) {
+		if tr.Task.Profile != profile {
+			profile = tr.Task.Profile
+			fmt.Printf("\n[%s] 프로필 진단:\n", profile)
+		}
+		for _, res := range tr.Results {
 			icon := "✓"
 			if res.Status == doctor.StatusFail {
 				icon = "✗"
@@ -331,13 +371,15 @@ func (r *Runner) addProfile(ctx context.Context, cfg *config.Config) error {
 		return err
 	}
 	cfg.Profiles[profile.Name] = config.Profile{
-		GHConfigDir: r.ghConfigDir(profile.Name),
-		SSHHost:     profile.SSHHost,
-		GitName:     profile.GitName,
-		GitEmail:    profile.GitEmail,
-		Owners:      profile.Owners,
-	}
-	if err := config.Save(r.CfgPath, cfg); err != nil {
+		GHConfigDir:  r.ghConfigDir(profile.Name),
+		SSHHost:      profile.SSHHost,
+		IdentityFile: profile.IdentityFile,
+		GitName:      profile.GitName,
+		GitEmail:     profile.GitEmail,
+		Owners:       profile.Owners,
+		GitHubUser:   profile.GitHubUser,
+	}
+	if err := r.save(cfg); err != nil {
 		return err
 	}
 	r.runDoctor(ctx, cfg)
@@ -371,14 +413,18 @@ func (r *Runner) editProfile(ctx context.Context, cfg *config.Config, profileNam
 	}
 
 	cfg.Profiles[input.Name] = config.Profile{
-		GHConfigDir: existing.GHConfigDir,
-		SSHHost:     input.SSHHost,
-		GitName:     input.GitName,
-		GitEmail:    input.GitEmail,
-		Owners:      input.Owners,
-	}
-
-	if err := config.Save(r.CfgPath, cfg); err != nil {
+		GHConfigDir:  existing.GHConfigDir,
+		SSHHost:      input.SSHHost,
+		IdentityFile: existing.IdentityFile,
+		GitName:      input.GitName,
+		GitEmail:     input.GitEmail,
+		Owners:       input.Owners,
+		GitDirs:      existing.GitDirs,
+		SigningKey:   existing.SigningKey,
+		GitHubUser:   existing.GitHubUser,
+	}
+
+	if err := r.save(cfg); err != nil {
 		return err
 	}
 
@@ -409,5 +455,84 @@ func (r *Runner) deleteProfile(cfg *config.Config, profileNames []string) error
 	}
 
 	delete(cfg.Profiles, selected)
-	return config.Save(r.CfgPath, cfg)
+	return r.save(cfg)
+}
+
+// save는 설정을 저장하고 ctx 전용 SSH include 파일을 다시 생성한다.
+// includeIf 모드가 켜져 있으면 git include 파일도 동기화한다.
+func (r *Runner) save(cfg *config.Config) error {
+	if r.IncludeIf {
+		cfg.GitIncludeIf = true
+	}
+	if err := config.Save(r.CfgPath, cfg); err != nil {
+		return err
+	}
+	if err := r.SyncProfiles(cfg); err != nil {
+		return err
+	}
+	if cfg.GitIncludeIf {
+		fmt.Printf("includeIf 설정이 갱신되었습니다: %s\n", r.gitConfigPath())
+	}
+	return nil
+}
+
+// SyncProfiles는 cfg의 프로필로 ctx SSH include 파일과 includeIf 파일을 다시 맞춘다.
+// 삭제된 프로필의 Host 블록과 gitconfig 파일은 제거되며,
+// git_include_if가 꺼져 있으면 전역 git config의 ctx 블록을 제거한다.
+// 설정 파일을 바꾸는 모든 경로(setup, ctx config set/unset/edit)가 저장 후 호출한다.
+func (r *Runner) SyncProfiles(cfg *config.Config) error {
+	if err := SyncSSHInclude(r.sshConfigPath(), r.sshIncludePath(cfg), cfg.Profiles); err != nil {
+		return fmt.Errorf("setup: SSH include 동기화 실패: %w", err)
+	}
+	if !cfg.GitIncludeIf {
+		if err := gitinclude.Remove(r.gitConfigPath()); err != nil {
+			return fmt.Errorf("setup: includeIf 동기화 실패: %w", err)
+		}
+		return nil
+	}
+	if err := gitinclude.Sync(r.gitConfigPath(), r.gitIncludeDir(), cfg, ParseSSHConfigIdentityFiles(r.sshConfigPath())); err != nil {
+		return fmt.Errorf("setup: includeIf 동기화 실패: %w", err)
+	}
+	return nil
+}
+
+func (r *Runner) sshConfigPath() string {
+	if r.SSHConfigPath != "" {
+		return r.SSHConfigPath
+	}
+	return DefaultSSHConfigPath()
+}
+
+// sshIncludePath는 ctx가 Host 블록을 관리하는 SSH include 파일 경로다.
+// ssh_include_path 설정이 없으면 main SSH config 옆의 config.d/ctx를 사용한다.
+func (r *Runner) sshIncludePath(cfg *config.Config) string {
+	if cfg.SSHIncludePath != "" {
+		return fsutil.ExpandHome(cfg.SSHIncludePath)
+	}
+	return DefaultSSHIncludePath(r.sshConfigPath())
+}
+
+func (r *Runner) ghConfigRoot() string {
+	if r.GHConfigRoot != "" {
+		return r.GHConfigRoot
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(home, ".config")
+}
+
+func (r *Runner) gitConfigPath() string {
+	if r.GitConfigPath != "" {
+		return r.GitConfigPath
+	}
+	return gitinclude.DefaultGlobalPath()
+}
+
+func (r *Runner) gitIncludeDir() string {
+	if r.GitIncludeDir != "" {
+		return r.GitIncludeDir
+	}
+	return gitinclude.DefaultDir()
 }
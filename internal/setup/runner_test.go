@@ -23,6 +23,8 @@ type mockFormRunner struct {
 	addMore         []bool
 	addMoreIdx      int
 	sshHost      string
+	sshHosts     []string // 설정되면 호출 순서대로 반환
+	sshHostIdx   int
 	sshKeyChoice SSHKeyChoice
 	owners       []string
 }
@@ -63,6 +65,11 @@ func (m *mockFormRunner) RunAddMore() (bool, error) {
 }
 
 func (m *mockFormRunner) RunSSHHostSelect(hosts []string) (string, error) {
+	if m.sshHostIdx < len(m.sshHosts) {
+		h := m.sshHosts[m.sshHostIdx]
+		m.sshHostIdx++
+		return h, nil
+	}
 	return m.sshHost, nil
 }
 
@@ -74,7 +81,7 @@ func (m *mockFormRunner) RunSSHKeySelect(existingKeys []SSHKeyInfo, profileName
 	return m.sshKeyChoice, nil
 }
 
-// registerDoctorCommands는 doctor.RunAll이 호출하는 명령어 응답을 등록한다.
+// registerDoctorCommands는 setup 후 진단이 호출하는 명령어 응답을 등록한다.
 func registerDoctorCommands(fc *testutil.FakeCommander) {
 	fc.Register("git --version", "git version 2.40.0", nil)
 	fc.Register("gh --version", "gh version 2.40.0", nil)
@@ -117,6 +124,7 @@ func TestRunner_FirstRun_SingleProfile(t *testing.T) {
 	assert.Equal(t, "test@work.com", cfg.Profiles["work"].GitEmail)
 	assert.Equal(t, "github.com-work", cfg.Profiles["work"].SSHHost)
 	assert.Equal(t, []string{"my-org", "myuser"}, cfg.Profiles["work"].Owners)
+	assert.Equal(t, "myuser", cfg.Profiles["work"].GitHubUser)
 }
 
 func TestRunner_FirstRun_MultipleProfiles(t *testing.T) {
@@ -134,9 +142,9 @@ func TestRunner_FirstRun_MultipleProfiles(t *testing.T) {
 			{Name: "work", GitName: "Work", GitEmail: "work@co.com"},
 			{Name: "personal", GitName: "Personal", GitEmail: "me@personal.com"},
 		},
-		sshHost: "github.com-work",
-		owners:  []string{"org1", "user1"},
-		addMore: []bool{true, false}, // add one more, then stop
+		sshHosts: []string{"github.com-work", "github.com-personal"},
+		owners:   []string{"org1", "user1"},
+		addMore:  []bool{true, false}, // add one more, then stop
 	}
 
 	r := &Runner{
@@ -359,11 +367,14 @@ func TestRunner_FirstRun_SSHKeyGenerate(t *testing.T) {
 	// ssh-keygen이 호출되었는지 확인
 	assert.True(t, fc.Called("ssh-keygen"))
 
-	// SSH config에 엔트리가 작성되었는지 확인
+	// main SSH config에는 Include만 추가되고, Host 엔트리는 ctx 전용 파일에 작성되었는지 확인
 	sshCfgData, err := os.ReadFile(sshConfigPath)
 	require.NoError(t, err)
-	assert.Contains(t, string(sshCfgData), "Host github.com-work")
-	assert.Contains(t, string(sshCfgData), "IdentityFile "+sshDir+"/id_ed25519_work")
+	assert.Equal(t, "Include config.d/ctx\n", string(sshCfgData))
+	includeData, err := os.ReadFile(sshDir + "/config.d/ctx")
+	require.NoError(t, err)
+	assert.Contains(t, string(includeData), "Host github.com-work")
+	assert.Contains(t, string(includeData), "IdentityFile "+sshDir+"/id_ed25519_work")
 
 	// config.toml에 SSHHost가 자동 결정되었는지 확인
 	cfg, err := config.Load(cfgPath)
@@ -412,11 +423,14 @@ func TestRunner_FirstRun_SSHKeyExisting(t *testing.T) {
 	// ssh-keygen이 호출되지 않았는지 확인
 	assert.False(t, fc.Called("ssh-keygen"))
 
-	// SSH config에 엔트리가 작성되었는지 확인
+	// main SSH config에는 Include만 추가되고, Host 엔트리는 ctx 전용 파일에 작성되었는지 확인
 	sshCfgData, err := os.ReadFile(sshConfigPath)
 	require.NoError(t, err)
-	assert.Contains(t, string(sshCfgData), "Host github.com-personal")
-	assert.Contains(t, string(sshCfgData), "IdentityFile "+existingKeyPath)
+	assert.Equal(t, "Include config.d/ctx\n", string(sshCfgData))
+	includeData, err := os.ReadFile(sshDir + "/config.d/ctx")
+	require.NoError(t, err)
+	assert.Contains(t, string(includeData), "Host github.com-personal")
+	assert.Contains(t, string(includeData), "IdentityFile "+existingKeyPath)
 
 	// config.toml에 SSHHost가 자동 결정되었는지 확인
 	cfg, err := config.Load(cfgPath)
@@ -649,3 +663,87 @@ func TestRunner_GhAuthLogin_PassesSSHKeyFlag_Existing(t *testing.T) {
 	assert.Contains(t, ghLoginCmd, expectedKeyFlag,
 		"gh auth login에 --ssh-key 플래그로 기존 공개키가 전달되어야 한다")
 }
+
+func TestRunner_FirstRun_IncludeIf(t *testing.T) {
+	dir := t.TempDir()
+	cfgPath := dir + "/config.toml"
+	gitConfigPath := dir + "/.gitconfig"
+	includeDir := dir + "/git"
+
+	fc := testutil.NewFakeCommander()
+	fc.Register("gh auth login --hostname github.com", "ok", nil)
+	fc.Register("gh api user/orgs --jq .[].login", "my-org\n", nil)
+	fc.Register("gh api user --jq .login", "myuser\n", nil)
+	registerDoctorCommands(fc)
+
+	mock := &mockFormRunner{
+		profileInputs: []*ProfileInput{{
+			Name: "work", GitName: "Test", GitEmail: "test@work.com",
+		}},
+		sshHost: "github.com-work",
+		owners:  []string{"my-org"},
+		addMore: []bool{false},
+	}
+
+	r := &Runner{
+		CfgPath:       cfgPath,
+		Commander:     fc,
+		FormRunner:    mock,
+		SSHConfigPath: dir + "/ssh_config",
+		GitConfigPath: gitConfigPath,
+		GitIncludeDir: includeDir,
+		IncludeIf:     true,
+	}
+
+	require.NoError(t, r.Run(context.Background()))
+
+	cfg, err := config.Load(cfgPath)
+	require.NoError(t, err)
+	assert.True(t, cfg.GitIncludeIf)
+
+	data, err := os.ReadFile(gitConfigPath)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), `[includeIf "hasconfig:remote.*.url:git@github.com:my-org/**"]`)
+	assert.FileExists(t, includeDir+"/work.gitconfig")
+}
+
+func TestRunner_Existing_EditProfile_SyncsIncludeIf(t *testing.T) {
+	dir := t.TempDir()
+	cfgPath := dir + "/config.toml"
+	includeDir := dir + "/git"
+	cfg := &config.Config{
+		Version:      1,
+		GitIncludeIf: true,
+		Profiles: map[string]config.Profile{
+			"work": {
+				GHConfigDir: "/tmp/gh-work", SSHHost: "github.com-work",
+				GitName: "Old", GitEmail: "old@work.com", Owners: []string{"work-org"},
+			},
+		},
+	}
+	require.NoError(t, config.Save(cfgPath, cfg))
+
+	fc := testutil.NewFakeCommander()
+	registerDoctorCommands(fc)
+
+	mock := &mockFormRunner{
+		action:          ActionEdit,
+		selectedProfile: "work",
+		profileInputs: []*ProfileInput{{
+			Name: "work", GitName: "New", GitEmail: "new@work.com",
+			SSHHost: "github.com-work", Owners: []string{"work-org"},
+		}},
+	}
+
+	r := &Runner{
+		CfgPath: cfgPath, Commander: fc, FormRunner: mock,
+		SSHConfigPath: dir + "/ssh_config",
+		GitConfigPath: dir + "/.gitconfig",
+		GitIncludeDir: includeDir,
+	}
+	require.NoError(t, r.Run(context.Background()))
+
+	data, err := os.ReadFile(includeDir + "/work.gitconfig")
+	require.NoError(t, err)
+	assert.Contains(t, string(data), `email = "new@work.com"`)
+}
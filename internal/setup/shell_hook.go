@@ -4,7 +4,6 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
-	"strings"
 
 	"github.com/hbjs97/ctx/internal/shell"
 )
@@ -33,25 +32,8 @@ func ShellRCPath(shellType string) string {
 // InstallShellHook은 셸 RC 파일에 ctx hook을 추가한다.
 // 이미 설치되어 있으면 건너뛴다.
 func InstallShellHook(shellType, rcPath string) error {
-	snippet := shell.HookSnippet(shellType)
-	if snippet == "" {
-		return fmt.Errorf("setup.InstallShellHook: 지원하지 않는 셸: %s", shellType)
-	}
-
-	existing, _ := os.ReadFile(rcPath) // 파일이 없으면 빈 바이트
-	if strings.Contains(string(existing), "ctx shell integration") {
-		return nil // 이미 설치됨
-	}
-
-	f, err := os.OpenFile(rcPath, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
-	if err != nil {
+	if err := shell.InstallHook(shellType, rcPath); err != nil {
 		return fmt.Errorf("setup.InstallShellHook: %w", err)
 	}
-	defer f.Close()
-
-	if _, err := fmt.Fprintf(f, "\n%s", snippet); err != nil {
-		return fmt.Errorf("setup.InstallShellHook: %w", err)
-	}
-
 	return nil
 }
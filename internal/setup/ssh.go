@@ -1,87 +1,41 @@
 package setup
 
 import (
-	"bufio"
 	"context"
 	"fmt"
 	"os"
 	"path/filepath"
+	"slices"
+	"sort"
 	"strings"
 
 	"github.com/hbjs97/ctx/internal/cmdexec"
+	"github.com/hbjs97/ctx/internal/config"
+	"github.com/hbjs97/ctx/internal/sshconfig"
 )
 
+// sshIncludeHeader는 ctx가 관리하는 SSH include 파일의 머리말이다.
+const sshIncludeHeader = "# ctx가 관리하는 파일입니다. 직접 수정하지 마세요 (ctx setup 실행 시 다시 생성됩니다).\n"
+
 // ParseSSHConfig는 SSH config 파일에서 GitHub 관련 Host alias 목록을 추출한다.
+// Include를 따라가며, 실제 HostName이 GitHub인 alias만 반환한다.
 // 파일이 없거나 파싱 실패 시 빈 슬라이스를 반환한다.
 func ParseSSHConfig(path string) []string {
-	f, err := os.Open(path)
+	c, err := sshconfig.Load(path)
 	if err != nil {
 		return nil
 	}
-	defer f.Close()
-
-	var hosts []string
-	var currentHost string
-	var isGitHub bool
-
-	scanner := bufio.NewScanner(f)
-	for scanner.Scan() {
-		line := strings.TrimSpace(scanner.Text())
-
-		if strings.HasPrefix(line, "Host ") && !strings.Contains(line, "*") {
-			// 이전 Host 블록 처리
-			if currentHost != "" && isGitHub {
-				hosts = append(hosts, currentHost)
-			}
-			currentHost = strings.TrimPrefix(line, "Host ")
-			currentHost = strings.TrimSpace(currentHost)
-			// Host 이름에 github이 포함되면 GitHub으로 간주
-			isGitHub = strings.Contains(strings.ToLower(currentHost), "github")
-		}
-
-		if strings.HasPrefix(line, "HostName") {
-			hostName := strings.TrimSpace(strings.TrimPrefix(line, "HostName"))
-			if strings.Contains(hostName, "github.com") {
-				isGitHub = true
-			}
-		}
-	}
-
-	// 마지막 Host 블록 처리
-	if currentHost != "" && isGitHub {
-		hosts = append(hosts, currentHost)
-	}
-
-	return hosts
+	return c.GitHubHosts()
 }
 
 // ParseSSHConfigIdentityFiles는 SSH config 파일에서 Host → IdentityFile 매핑을 추출한다.
-// 모든 Host 블록을 파싱하며, IdentityFile이 없는 블록은 무시한다.
+// 모든 Host alias에 대해 실제로 적용되는 IdentityFile을 계산하며, 없는 alias는 제외한다.
 func ParseSSHConfigIdentityFiles(path string) map[string]string {
-	f, err := os.Open(path)
+	c, err := sshconfig.Load(path)
 	if err != nil {
 		return nil
 	}
-	defer f.Close()
-
-	result := make(map[string]string)
-	var currentHost string
-
-	scanner := bufio.NewScanner(f)
-	for scanner.Scan() {
-		line := strings.TrimSpace(scanner.Text())
-
-		if strings.HasPrefix(line, "Host ") && !strings.Contains(line, "*") {
-			currentHost = strings.TrimSpace(strings.TrimPrefix(line, "Host "))
-		}
-
-		if currentHost != "" && strings.HasPrefix(line, "IdentityFile") {
-			idFile := strings.TrimSpace(strings.TrimPrefix(line, "IdentityFile"))
-			result[currentHost] = idFile
-		}
-	}
-
-	return result
+	return c.IdentityFiles()
 }
 
 // FilterUsedSSHKeys는 이미 다른 프로필에서 사용 중인 키를 제외한다.
@@ -131,29 +85,120 @@ func DefaultSSHConfigPath() string {
 	return filepath.Join(home, ".ssh", "config")
 }
 
-// WriteSSHConfigEntry는 SSH config 파일에 GitHub Host alias 블록을 추가한다.
-// 동일한 Host가 이미 존재하면 스킵한다. 파일이 없으면 생성한다.
-func WriteSSHConfigEntry(configPath, host, identityFile string) error {
-	existing, _ := os.ReadFile(configPath)
-	hostLine := "Host " + host
-	if strings.Contains(string(existing), hostLine) {
-		return nil // 이미 존재
+// DefaultSSHIncludePath는 main SSH config 옆의 ctx 전용 include 파일 경로를 반환한다.
+func DefaultSSHIncludePath(mainPath string) string {
+	return filepath.Join(filepath.Dir(mainPath), "config.d", "ctx")
+}
+
+// SyncSSHInclude는 identity_file이 있는 프로필로 ctx 전용 SSH include 파일을 다시 생성하고,
+// main config에 해당 파일을 불러오는 Include가 없으면 맨 앞에 한 번 추가한다.
+// 사용자가 다른 파일에 직접 선언한 Host alias는 덮어쓰지 않도록 건너뛴다.
+// 이전 버전이 main config에 직접 쓴 Host 블록은 include 파일로 옮기고,
+// ssh_include_path가 바뀌어 더 이상 쓰지 않는 ctx include 파일과 그 Include 줄은 지운다.
+// 관리할 블록이 없고 include 파일도 없으면 include 파일을 만들지 않는다.
+func SyncSSHInclude(mainPath, includePath string, profiles map[string]config.Profile) error {
+	main, err := sshconfig.Load(mainPath)
+	if err != nil {
+		return fmt.Errorf("setup.SyncSSHInclude: %w", err)
 	}
 
-	entry := fmt.Sprintf("\nHost %s\n  HostName github.com\n  User git\n  IdentityFile %s\n  IdentitiesOnly yes\n", host, identityFile)
+	// 옛 include 파일의 블록이 사용자 선언으로 보이지 않도록 프로필보다 먼저 정리한다
+	var stale []string
+	for _, inc := range main.Includes() {
+		if inc.Path != includePath && strings.HasPrefix(string(inc.Bytes()), sshIncludeHeader) {
+			main.RemoveInclude(inc.Path)
+			stale = append(stale, inc.Path)
+		}
+	}
+	mainChanged := len(stale) > 0
 
-	f, err := os.OpenFile(configPath, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
-	if err != nil {
-		return fmt.Errorf("setup.WriteSSHConfigEntry: %w", err)
+	names := make([]string, 0, len(profiles))
+	for name := range profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	out := sshconfig.Parse(includePath, []byte(sshIncludeHeader))
+	managed := 0
+	for _, name := range names {
+		p := profiles[name]
+		if p.IdentityFile == "" || p.SSHHost == "" || sshconfig.IsGitHubHostName(p.SSHHost) {
+			continue
+		}
+		if b := main.LocalHost(p.SSHHost); b != nil && writtenByCtx(b, p) {
+			main.RemoveHost(p.SSHHost)
+			mainChanged = true
+		}
+		if declaredByUser(main, p.SSHHost, includePath) {
+			continue
+		}
+		b := out.AddHost(p.SSHHost)
+		b.Set("HostName", "github.com")
+		b.Set("User", "git")
+		b.Set("IdentityFile", p.IdentityFile)
+		b.Set("IdentitiesOnly", "yes")
+		managed++
 	}
-	defer f.Close()
 
-	if _, err := f.WriteString(entry); err != nil {
-		return fmt.Errorf("setup.WriteSSHConfigEntry: %w", err)
+	_, statErr := os.Stat(includePath)
+	if managed > 0 || statErr == nil {
+		if err := out.Save(); err != nil {
+			return fmt.Errorf("setup.SyncSSHInclude: %w", err)
+		}
+		if !main.IncludesPath(includePath) {
+			main.PrependInclude(includeDirective(mainPath, includePath))
+			mainChanged = true
+		}
+	}
+	if mainChanged {
+		if err := main.Save(); err != nil {
+			return fmt.Errorf("setup.SyncSSHInclude: %w", err)
+		}
+	}
+	// main config가 더 이상 불러오지 않게 된 뒤에 지운다
+	for _, path := range stale {
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("setup.SyncSSHInclude: %w", err)
+		}
 	}
 	return nil
 }
 
+// writtenByCtx는 Host 블록이 이전 버전의 ctx가 main config에 직접 쓴 형태 그대로인지 확인한다.
+// 사용자가 다른 지시어를 더했거나 값을 바꾼 블록은 사용자 선언으로 남긴다.
+func writtenByCtx(b *sshconfig.Block, p config.Profile) bool {
+	return len(b.Patterns()) == 1 &&
+		slices.Equal(b.Keys(), []string{"hostname", "user", "identityfile", "identitiesonly"}) &&
+		b.Get("HostName") == "github.com" &&
+		b.Get("User") == "git" &&
+		b.Get("IdentityFile") == p.IdentityFile &&
+		b.Get("IdentitiesOnly") == "yes"
+}
+
+// declaredByUser는 ctx include 파일 외의 곳에서 alias가 선언되어 있는지 확인한다.
+func declaredByUser(main *sshconfig.Config, alias, includePath string) bool {
+	for _, f := range main.DeclaredIn(alias) {
+		if f != includePath {
+			return true
+		}
+	}
+	return false
+}
+
+// includeDirective는 Include에 쓸 경로를 만든다.
+// main config 디렉토리 아래면 상대 경로, 홈 디렉토리 아래면 ~ 경로를 사용한다.
+func includeDirective(mainPath, includePath string) string {
+	if rel, err := filepath.Rel(filepath.Dir(mainPath), includePath); err == nil && !strings.HasPrefix(rel, "..") {
+		return rel
+	}
+	if home, err := os.UserHomeDir(); err == nil {
+		if rel, err := filepath.Rel(home, includePath); err == nil && !strings.HasPrefix(rel, "..") {
+			return "~/" + rel
+		}
+	}
+	return includePath
+}
+
 // GenerateSSHKey는 ssh-keygen으로 ed25519 키 쌍을 생성한다.
 // 빈 passphrase로 생성하며, Commander를 통해 실행한다.
 func GenerateSSHKey(ctx context.Context, cmd cmdexec.Commander, email, keyPath string) error {
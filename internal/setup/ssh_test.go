@@ -8,6 +8,7 @@ import (
 	"strings"
 	"testing"
 
+	"github.com/hbjs97/ctx/internal/config"
 	"github.com/hbjs97/ctx/internal/testutil"
 	"github.com/stretchr/testify/assert"
 )
@@ -161,54 +162,6 @@ func TestGenerateSSHKey_Failure(t *testing.T) {
 	}
 }
 
-func TestWriteSSHConfigEntry_NewFile(t *testing.T) {
-	dir := t.TempDir()
-	configPath := filepath.Join(dir, "config")
-
-	err := WriteSSHConfigEntry(configPath, "github.com-work", "~/.ssh/id_ed25519_work")
-	if err != nil {
-		t.Fatalf("unexpected error: %v", err)
-	}
-
-	content, _ := os.ReadFile(configPath)
-	s := string(content)
-	if !strings.Contains(s, "Host github.com-work") {
-		t.Error("missing Host line")
-	}
-	if !strings.Contains(s, "HostName github.com") {
-		t.Error("missing HostName line")
-	}
-	if !strings.Contains(s, "User git") {
-		t.Error("missing User line")
-	}
-	if !strings.Contains(s, "IdentityFile ~/.ssh/id_ed25519_work") {
-		t.Error("missing IdentityFile line")
-	}
-	if !strings.Contains(s, "IdentitiesOnly yes") {
-		t.Error("missing IdentitiesOnly line")
-	}
-}
-
-func TestWriteSSHConfigEntry_AppendsToExisting(t *testing.T) {
-	dir := t.TempDir()
-	configPath := filepath.Join(dir, "config")
-	os.WriteFile(configPath, []byte("Host existing\n  HostName example.com\n"), 0600)
-
-	err := WriteSSHConfigEntry(configPath, "github.com-personal", "~/.ssh/id_ed25519_personal")
-	if err != nil {
-		t.Fatalf("unexpected error: %v", err)
-	}
-
-	content, _ := os.ReadFile(configPath)
-	s := string(content)
-	if !strings.Contains(s, "Host existing") {
-		t.Error("existing entry was lost")
-	}
-	if !strings.Contains(s, "Host github.com-personal") {
-		t.Error("new entry not appended")
-	}
-}
-
 func TestParseSSHConfigIdentityFiles_MapsHostToKey(t *testing.T) {
 	dir := t.TempDir()
 	configPath := filepath.Join(dir, "config")
@@ -280,19 +233,146 @@ func TestFilterUsedSSHKeys_AllUsed(t *testing.T) {
 	}
 }
 
-func TestWriteSSHConfigEntry_SkipsDuplicate(t *testing.T) {
+func TestParseSSHConfig_MultiPatternAndInclude(t *testing.T) {
 	dir := t.TempDir()
-	configPath := filepath.Join(dir, "config")
-	existing := "Host github.com-work\n  HostName github.com\n  User git\n  IdentityFile ~/.ssh/id_ed25519_work\n"
-	os.WriteFile(configPath, []byte(existing), 0600)
+	path := filepath.Join(dir, "config")
+	os.WriteFile(filepath.Join(dir, "extra"), []byte("Host gh-extra\n  HostName github.com\n"), 0600)
+	os.WriteFile(path, []byte(`Include extra
 
-	err := WriteSSHConfigEntry(configPath, "github.com-work", "~/.ssh/id_ed25519_work")
-	if err != nil {
+Host gh-a gh-b
+  hostname = github.com
+
+Host github-lookalike
+  HostName gitlab.example.com
+`), 0600)
+
+	hosts := ParseSSHConfig(path)
+	assert.Equal(t, []string{"gh-extra", "gh-a", "gh-b"}, hosts)
+}
+
+func TestSyncSSHInclude_WritesOwnedFileAndIncludeOnce(t *testing.T) {
+	dir := t.TempDir()
+	mainPath := filepath.Join(dir, "config")
+	includePath := DefaultSSHIncludePath(mainPath)
+	userContent := "# dotfiles managed\nHost example\n  HostName example.com\n"
+	os.WriteFile(mainPath, []byte(userContent), 0600)
+
+	profiles := map[string]config.Profile{
+		"work":     {SSHHost: "github.com-work", IdentityFile: "~/.ssh/id_work"},
+		"personal": {SSHHost: "github.com-personal", IdentityFile: "~/.ssh/id_personal"},
+		"manual":   {SSHHost: "github.com-manual"},
+	}
+	if err := SyncSSHInclude(mainPath, includePath, profiles); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := SyncSSHInclude(mainPath, includePath, profiles); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	mainData, _ := os.ReadFile(mainPath)
+	assert.Equal(t, "Include config.d/ctx\n\n"+userContent, string(mainData), "Include는 한 번만 추가되고 기존 내용은 보존된다")
+
+	includeData, _ := os.ReadFile(includePath)
+	s := string(includeData)
+	assert.Less(t, strings.Index(s, "Host github.com-personal"), strings.Index(s, "Host github.com-work"), "프로필 이름 순")
+	assert.Contains(t, s, "IdentityFile ~/.ssh/id_work\n  IdentitiesOnly yes")
+	assert.NotContains(t, s, "github.com-manual")
+	assert.Equal(t, []string{"github.com-personal", "github.com-work"}, ParseSSHConfig(mainPath))
+}
+
+func TestSyncSSHInclude_RemovesDeletedProfile(t *testing.T) {
+	dir := t.TempDir()
+	mainPath := filepath.Join(dir, "config")
+	includePath := DefaultSSHIncludePath(mainPath)
+	profiles := map[string]config.Profile{
+		"work": {SSHHost: "github.com-work", IdentityFile: "~/.ssh/id_work"},
+	}
+	if err := SyncSSHInclude(mainPath, includePath, profiles); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if err := SyncSSHInclude(mainPath, includePath, map[string]config.Profile{}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	includeData, _ := os.ReadFile(includePath)
+	assert.NotContains(t, string(includeData), "github.com-work")
+	assert.Empty(t, ParseSSHConfig(mainPath))
+}
+
+func TestSyncSSHInclude_SkipsUserDeclaredHost(t *testing.T) {
+	dir := t.TempDir()
+	mainPath := filepath.Join(dir, "config")
+	includePath := filepath.Join(dir, "custom", "ctx.conf")
+	userContent := "Host github.com-work\n  HostName github.com\n  IdentityFile ~/.ssh/mine\n"
+	os.WriteFile(mainPath, []byte(userContent), 0600)
+
+	profiles := map[string]config.Profile{
+		"work": {SSHHost: "github.com-work", IdentityFile: "~/.ssh/id_work"},
+	}
+	if err := SyncSSHInclude(mainPath, includePath, profiles); err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
 
-	content, _ := os.ReadFile(configPath)
-	if count := strings.Count(string(content), "Host github.com-work"); count != 1 {
-		t.Errorf("expected 1 occurrence, got %d", count)
+	mainData, _ := os.ReadFile(mainPath)
+	assert.Equal(t, userContent, string(mainData), "관리할 블록이 없으면 main config를 건드리지 않는다")
+	_, err := os.Stat(includePath)
+	assert.True(t, os.IsNotExist(err))
+}
+
+func TestSyncSSHInclude_MigratesLegacyHostBlocks(t *testing.T) {
+	dir := t.TempDir()
+	mainPath := filepath.Join(dir, "config")
+	includePath := DefaultSSHIncludePath(mainPath)
+	// 이전 버전이 main config 끝에 덧붙인 블록과, 사용자가 고친 블록
+	legacy := "Host example\n  HostName example.com\n" +
+		"\nHost github.com-work\n  HostName github.com\n  User git\n  IdentityFile ~/.ssh/id_work\n  IdentitiesOnly yes\n" +
+		"\nHost github.com-personal\n  HostName github.com\n  User git\n  IdentityFile ~/.ssh/id_personal\n  IdentitiesOnly yes\n  AddKeysToAgent yes\n"
+	os.WriteFile(mainPath, []byte(legacy), 0600)
+
+	profiles := map[string]config.Profile{
+		"work":     {SSHHost: "github.com-work", IdentityFile: "~/.ssh/id_work"},
+		"personal": {SSHHost: "github.com-personal", IdentityFile: "~/.ssh/id_personal"},
+	}
+	if err := SyncSSHInclude(mainPath, includePath, profiles); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	mainData, _ := os.ReadFile(mainPath)
+	assert.NotContains(t, string(mainData), "Host github.com-work", "ctx가 쓴 블록은 include 파일로 옮긴다")
+	assert.Contains(t, string(mainData), "Host github.com-personal", "사용자가 고친 블록은 그대로 둔다")
+	assert.Contains(t, string(mainData), "Host example")
+
+	includeData, _ := os.ReadFile(includePath)
+	assert.Contains(t, string(includeData), "Host github.com-work")
+	assert.NotContains(t, string(includeData), "github.com-personal")
+}
+
+func TestSyncSSHInclude_RemovesPreviousIncludePath(t *testing.T) {
+	dir := t.TempDir()
+	mainPath := filepath.Join(dir, "config")
+	oldPath := DefaultSSHIncludePath(mainPath)
+	newPath := filepath.Join(dir, "custom", "ctx.conf")
+	userInclude := filepath.Join(dir, "mine")
+	os.WriteFile(userInclude, []byte("Host example\n  HostName example.com\n"), 0600)
+	os.WriteFile(mainPath, []byte("Include mine\n"), 0600)
+
+	profiles := map[string]config.Profile{
+		"work": {SSHHost: "github.com-work", IdentityFile: "~/.ssh/id_work"},
+	}
+	if err := SyncSSHInclude(mainPath, oldPath, profiles); err != nil {
+		t.Fatalf("unexpected error: %v", err)
 	}
+	if err := SyncSSHInclude(mainPath, newPath, profiles); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	mainData, _ := os.ReadFile(mainPath)
+	assert.Equal(t, "Include custom/ctx.conf\n\nInclude mine\n", string(mainData), "옛 Include 줄은 지우고 사용자 Include는 남긴다")
+	_, err := os.Stat(oldPath)
+	assert.True(t, os.IsNotExist(err), "옛 include 파일을 지운다")
+	_, err = os.Stat(userInclude)
+	assert.NoError(t, err)
+
+	includeData, _ := os.ReadFile(newPath)
+	assert.Contains(t, string(includeData), "Host github.com-work", "옛 파일의 블록을 사용자 선언으로 보지 않는다")
 }
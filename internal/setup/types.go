@@ -16,6 +16,10 @@ type ProfileInput struct {
 	GitEmail string
 	SSHHost  string
 	Owners   []string
+	// IdentityFile은 ctx가 SSH Host 블록을 관리할 때 사용할 개인 키 경로다.
+	IdentityFile string
+	// GitHubUser는 gh 인증 후 조회한 GitHub 로그인이다. 조회하지 못하면 비어 있다.
+	GitHubUser string
 }
 
 // SSHKeyInfo는 ~/.ssh 디렉토리에서 발견된 SSH 키 쌍 정보다.
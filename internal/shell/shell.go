@@ -2,6 +2,8 @@ package shell
 
 import (
 	"fmt"
+	"os"
+	"strings"
 
 	"github.com/hbjs97/ctx/internal/config"
 )
@@ -32,6 +34,9 @@ func Deactivate(shellType string) string {
 	}
 }
 
+// HookMarker는 RC 파일에 ctx 셸 hook이 설치되었는지 판별하는 표식이다.
+const HookMarker = "ctx shell integration"
+
 // HookSnippet는 셸 디렉토리 변경 hook 스니펫을 반환한다.
 func HookSnippet(shellType string) string {
 	switch shellType {
@@ -59,3 +64,28 @@ end
 		return ""
 	}
 }
+
+// InstallHook은 셸 RC 파일 끝에 ctx hook 스니펫을 추가한다.
+// 이미 설치되어 있으면 건너뛴다.
+func InstallHook(shellType, rcPath string) error {
+	snippet := HookSnippet(shellType)
+	if snippet == "" {
+		return fmt.Errorf("shell.InstallHook: 지원하지 않는 셸: %s", shellType)
+	}
+
+	existing, _ := os.ReadFile(rcPath) // 파일이 없으면 빈 바이트
+	if strings.Contains(string(existing), HookMarker) {
+		return nil // 이미 설치됨
+	}
+
+	f, err := os.OpenFile(rcPath, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return fmt.Errorf("shell.InstallHook: %w", err)
+	}
+	defer f.Close()
+
+	if _, err := fmt.Fprintf(f, "\n%s", snippet); err != nil {
+		return fmt.Errorf("shell.InstallHook: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,2 @@
+// Package sshconfig parses and edits OpenSSH client config files while preserving their formatting.
+package sshconfig
@@ -0,0 +1,707 @@
+package sshconfig
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/hbjs97/ctx/internal/fsutil"
+)
+
+// maxIncludeDepth는 Include 재귀 깊이 제한이다 (OpenSSH와 동일).
+const maxIncludeDepth = 16
+
+// GitHub SSH 엔드포인트로 간주하는 HostName 값.
+var githubHostNames = []string{"github.com", "ssh.github.com"}
+
+// Config는 하나의 SSH config 파일이다. 수정하지 않은 줄은 원문 그대로 보존된다.
+type Config struct {
+	Path string
+
+	blocks     []*Block
+	missingEOL bool
+}
+
+// Block은 Host/Match 블록, 또는 첫 Host 이전의 전역 영역이다.
+type Block struct {
+	// Kind는 "host", "match" 또는 전역 영역인 경우 빈 문자열이다.
+	Kind string
+
+	file  string
+	lead  []*line // 헤더 바로 위의 주석. 블록과 함께 제거된다.
+	lines []*line
+	scope *Block // 이 블록을 Include한 블록. 최상위 파일이면 nil.
+}
+
+// line은 설정 파일의 한 줄이다. 빈 줄과 주석은 keyword가 비어있다.
+type line struct {
+	raw      string
+	indent   string
+	keyword  string
+	sep      string
+	value    string
+	included []*Config // Include 지시어로 불러온 파일
+}
+
+func (l *line) key() string {
+	return strings.ToLower(l.keyword)
+}
+
+func (l *line) isComment() bool {
+	return strings.HasPrefix(strings.TrimSpace(l.raw), "#")
+}
+
+func (l *line) setValue(value string) {
+	if l.sep == "" {
+		l.sep = " "
+	}
+	l.value = value
+	l.raw = l.indent + l.keyword + l.sep + l.value
+}
+
+// Load는 SSH config 파일을 읽고 Include 지시어를 따라간다.
+// Host/Match 블록 안의 Include는 OpenSSH처럼 그 블록이 적용될 때만 유효하다.
+// 파일이 없으면 빈 Config를 반환한다.
+func Load(path string) (*Config, error) {
+	return load(path, filepath.Dir(path), 0)
+}
+
+func load(path, baseDir string, depth int) (*Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return Parse(path, nil), nil
+		}
+		return nil, fmt.Errorf("sshconfig.Load: %w", err)
+	}
+
+	if depth >= maxIncludeDepth {
+		return nil, fmt.Errorf("sshconfig.Load: Include 깊이 초과: %s", path)
+	}
+	c := Parse(path, data)
+	for _, b := range c.blocks {
+		for _, l := range b.lines {
+			if l.key() != "include" {
+				continue
+			}
+			for _, pattern := range splitArgs(l.value) {
+				matches, _ := filepath.Glob(resolveInclude(baseDir, pattern)) // 잘못된 패턴은 OpenSSH처럼 무시
+				for _, m := range matches {
+					inc, err := load(m, baseDir, depth+1)
+					if err != nil {
+						return nil, err
+					}
+					for _, ib := range inc.blocks {
+						ib.scope = b
+					}
+					l.included = append(l.included, inc)
+				}
+			}
+		}
+	}
+	return c, nil
+}
+
+// Parse는 SSH config 내용을 파싱한다. Include는 따라가지 않는다.
+// Host/Match 줄 바로 위에 같은 들여쓰기로 붙은 주석은 그 블록에 속한다.
+func Parse(path string, data []byte) *Config {
+	c := &Config{Path: path}
+	global := &Block{file: path}
+	c.blocks = []*Block{global}
+
+	text := string(data)
+	if text == "" {
+		return c
+	}
+	c.missingEOL = !strings.HasSuffix(text, "\n")
+	text = strings.TrimSuffix(text, "\n")
+
+	current := global
+	for _, raw := range strings.Split(text, "\n") {
+		l := parseLine(raw)
+		switch l.key() {
+		case "host", "match":
+			next := &Block{Kind: l.key(), file: path}
+			n := len(current.lines)
+			for n > 0 && current.lines[n-1].isComment() && current.lines[n-1].indent == l.indent {
+				n--
+			}
+			next.lead = slices.Clone(current.lines[n:])
+			current.lines = current.lines[:n]
+			current = next
+			c.blocks = append(c.blocks, current)
+		}
+		current.lines = append(current.lines, l)
+	}
+	return c
+}
+
+func parseLine(raw string) *line {
+	l := &line{raw: raw}
+	trimmed := strings.TrimLeft(raw, " \t")
+	l.indent = raw[:len(raw)-len(trimmed)]
+	trimmed = strings.TrimRight(trimmed, " \t\r")
+	if trimmed == "" || strings.HasPrefix(trimmed, "#") {
+		return l
+	}
+
+	i := strings.IndexAny(trimmed, " \t=")
+	if i < 0 {
+		l.keyword = trimmed
+		return l
+	}
+	l.keyword = trimmed[:i]
+	rest := trimmed[i:]
+	value := strings.TrimLeft(rest, " \t")
+	if strings.HasPrefix(value, "=") {
+		value = strings.TrimLeft(value[1:], " \t")
+	}
+	l.sep = rest[:len(rest)-len(value)]
+	l.value = value
+	return l
+}
+
+// Bytes는 현재 내용을 파일 형식으로 직렬화한다.
+func (c *Config) Bytes() []byte {
+	var b strings.Builder
+	for _, blk := range c.blocks {
+		for _, l := range slices.Concat(blk.lead, blk.lines) {
+			b.WriteString(l.raw)
+			b.WriteByte('\n')
+		}
+	}
+	out := b.String()
+	if c.missingEOL {
+		out = strings.TrimSuffix(out, "\n")
+	}
+	return []byte(out)
+}
+
+// Save는 Path에 내용을 기록한다. Include된 파일은 기록하지 않는다.
+// 사용자가 관리하는 파일이므로 lock을 잡고 원자적으로 교체하며, 기존 파일 권한은 유지한다.
+func (c *Config) Save() error {
+	if err := os.MkdirAll(filepath.Dir(c.Path), 0700); err != nil {
+		return fmt.Errorf("sshconfig.Save: %w", err)
+	}
+	lock, err := fsutil.Lock(c.Path)
+	if err != nil {
+		return fmt.Errorf("sshconfig.Save: %w", err)
+	}
+	defer lock.Unlock()
+
+	perm := os.FileMode(0600)
+	if info, err := os.Stat(c.Path); err == nil {
+		perm = info.Mode().Perm()
+	}
+	if err := fsutil.WriteFileAtomic(c.Path, c.Bytes(), perm); err != nil {
+		return fmt.Errorf("sshconfig.Save: %w", err)
+	}
+	return nil
+}
+
+// walk는 Include를 펼친 순서대로 블록을 방문한다. fn이 false를 반환하면 중단한다.
+func (c *Config) walk(fn func(*Block) bool) bool {
+	for _, b := range c.blocks {
+		if !fn(b) {
+			return false
+		}
+		for _, l := range b.lines {
+			for _, inc := range l.included {
+				if !inc.walk(fn) {
+					return false
+				}
+			}
+		}
+	}
+	return true
+}
+
+// Hosts는 Include를 포함한 모든 Host 블록의 alias(와일드카드, 부정 패턴 제외)를 등장 순서대로 반환한다.
+func (c *Config) Hosts() []string {
+	seen := make(map[string]bool)
+	var hosts []string
+	c.walk(func(b *Block) bool {
+		for _, a := range b.Aliases() {
+			if !seen[a] {
+				seen[a] = true
+				hosts = append(hosts, a)
+			}
+		}
+		return true
+	})
+	return hosts
+}
+
+// Lookup은 alias로 접속할 때 적용되는 key의 값을 반환한다.
+// OpenSSH처럼 처음 일치한 값이 우선한다. Match 블록과 그 안에서 Include한 파일은 평가하지 않는다.
+func (c *Config) Lookup(alias, key string) string {
+	key = strings.ToLower(key)
+	var value string
+	c.walk(func(b *Block) bool {
+		if !b.matches(alias) {
+			return true
+		}
+		if v, ok := b.get(key); ok {
+			value = v
+			return false
+		}
+		return true
+	})
+	return value
+}
+
+// HostName은 alias로 접속할 때의 실제 호스트 이름을 반환한다. HostName이 없으면 alias 자체다.
+func (c *Config) HostName(alias string) string {
+	if h := c.Lookup(alias, "HostName"); h != "" {
+		return h
+	}
+	return alias
+}
+
+// GitHubHosts는 실제 HostName이 GitHub인 Host alias 목록을 반환한다.
+// HostName이 없으면 alias 자체를 접속 호스트로 본다.
+func (c *Config) GitHubHosts() []string {
+	var hosts []string
+	for _, alias := range c.Hosts() {
+		hostName := c.Lookup(alias, "HostName")
+		if hostName == "" {
+			hostName = alias
+		}
+		if IsGitHubHostName(hostName) {
+			hosts = append(hosts, alias)
+		}
+	}
+	return hosts
+}
+
+// IdentityFiles는 Host alias → 적용되는 IdentityFile 매핑을 반환한다.
+func (c *Config) IdentityFiles() map[string]string {
+	result := make(map[string]string)
+	for _, alias := range c.Hosts() {
+		if v := c.Lookup(alias, "IdentityFile"); v != "" {
+			result[alias] = v
+		}
+	}
+	return result
+}
+
+// FindHost는 alias를 패턴으로 정확히 선언한 Host 블록을 찾는다. Include된 파일도 검색한다.
+// 와일드카드 일치는 고려하지 않는다.
+func (c *Config) FindHost(alias string) *Block {
+	var found *Block
+	c.walk(func(b *Block) bool {
+		if b.declares(alias) {
+			found = b
+			return false
+		}
+		return true
+	})
+	return found
+}
+
+// HasHost는 alias를 선언한 Host 블록이 있는지 반환한다.
+func (c *Config) HasHost(alias string) bool {
+	return c.FindHost(alias) != nil
+}
+
+// DeclaredIn은 alias를 패턴으로 선언한 파일 경로 목록을 반환한다 (Include 포함).
+func (c *Config) DeclaredIn(alias string) []string {
+	var files []string
+	c.walk(func(b *Block) bool {
+		if b.declares(alias) {
+			files = append(files, b.file)
+		}
+		return true
+	})
+	return files
+}
+
+// IncludesPath는 이 파일의 Include 지시어가 path를 불러오는지 확인한다.
+// 중첩된 Include는 확인하지 않는다.
+func (c *Config) IncludesPath(path string) bool {
+	baseDir := filepath.Dir(c.Path)
+	for _, b := range c.blocks {
+		for _, l := range b.lines {
+			if l.key() != "include" {
+				continue
+			}
+			for _, pattern := range splitArgs(l.value) {
+				if ok, _ := filepath.Match(resolveInclude(baseDir, pattern), path); ok {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
+
+// PrependInclude는 파일 맨 앞에 Include 지시어를 추가한다.
+// Host 블록 안에 들어가지 않도록 항상 전역 영역의 첫 줄에 넣는다.
+func (c *Config) PrependInclude(pattern string) {
+	global := c.blocks[0]
+	lines := []*line{parseLine("Include " + quote(pattern))}
+	if len(global.lines) > 0 || len(c.blocks) > 1 {
+		lines = append(lines, &line{})
+	}
+	global.lines = append(lines, global.lines...)
+}
+
+// AddHost는 새 Host 블록을 추가하고 반환한다.
+// 뒤쪽의 "Host *"나 Match 블록이 새 블록의 값을 가리지 않도록 그 앞에 삽입한다.
+func (c *Config) AddHost(alias string) *Block {
+	b := &Block{Kind: "host", file: c.Path, lines: []*line{parseLine("Host " + quote(alias))}}
+
+	at := len(c.blocks)
+	for i, blk := range c.blocks {
+		if blk.Kind == "match" || (blk.Kind == "host" && blk.isCatchAll()) {
+			at = i
+			break
+		}
+	}
+
+	prev := c.blocks[at-1]
+	if n := len(prev.lines); n > 0 && strings.TrimSpace(prev.lines[n-1].raw) != "" {
+		prev.lines = append(prev.lines, &line{})
+	}
+	if at < len(c.blocks) {
+		b.lines = append(b.lines, &line{})
+	}
+
+	c.blocks = append(c.blocks[:at], append([]*Block{b}, c.blocks[at:]...)...)
+	return b
+}
+
+// RemoveHost는 이 파일에서 alias를 선언한 Host 블록을 제거한다.
+// 블록에 다른 패턴이 함께 있으면 alias만 패턴 목록에서 뺀다. 제거했으면 true를 반환한다.
+func (c *Config) RemoveHost(alias string) bool {
+	for i, b := range c.blocks {
+		if !b.declares(alias) {
+			continue
+		}
+		patterns := b.Patterns()
+		if len(patterns) == 1 {
+			c.blocks = append(c.blocks[:i], c.blocks[i+1:]...)
+			return true
+		}
+		kept := make([]string, 0, len(patterns)-1)
+		for _, p := range patterns {
+			if !strings.EqualFold(p, alias) {
+				kept = append(kept, p)
+			}
+		}
+		b.lines[0].setValue(strings.Join(kept, " "))
+		return true
+	}
+	return false
+}
+
+// LocalHost는 이 파일(Include 제외)에서 alias를 선언한 Host 블록을 찾는다.
+func (c *Config) LocalHost(alias string) *Block {
+	for _, b := range c.blocks {
+		if b.declares(alias) {
+			return b
+		}
+	}
+	return nil
+}
+
+// Includes는 이 파일의 Include 지시어가 직접 불러온 파일 목록이다. 중첩된 Include는 포함하지 않는다.
+func (c *Config) Includes() []*Config {
+	var files []*Config
+	for _, b := range c.blocks {
+		for _, l := range b.lines {
+			files = append(files, l.included...)
+		}
+	}
+	return files
+}
+
+// RemoveInclude는 이 파일에서 path를 가리키는 Include 패턴을 제거한다.
+// 지시어에 다른 패턴이 함께 있으면 해당 패턴만 뺀다. 와일드카드 패턴은 다른 파일도 불러오므로 남긴다.
+// 제거했으면 true를 반환한다.
+func (c *Config) RemoveInclude(path string) bool {
+	baseDir := filepath.Dir(c.Path)
+	removed := false
+	for _, b := range c.blocks {
+		for i := 0; i < len(b.lines); i++ {
+			l := b.lines[i]
+			if l.key() != "include" {
+				continue
+			}
+			var kept []string
+			for _, pattern := range splitArgs(l.value) {
+				if resolveInclude(baseDir, pattern) == path {
+					removed = true
+					continue
+				}
+				kept = append(kept, quote(pattern))
+			}
+			if len(kept) > 0 {
+				if len(kept) < len(splitArgs(l.value)) {
+					l.setValue(strings.Join(kept, " "))
+					l.included = slices.DeleteFunc(l.included, func(inc *Config) bool { return inc.Path == path })
+				}
+				continue
+			}
+			// PrependInclude가 넣은 빈 줄도 함께 지운다
+			end := i + 1
+			if b.Kind == "" && i == 0 && end < len(b.lines) && strings.TrimSpace(b.lines[end].raw) == "" {
+				end++
+			}
+			b.lines = append(b.lines[:i], b.lines[end:]...)
+			i--
+		}
+	}
+	return removed
+}
+
+// File은 블록이 선언된 파일 경로다.
+func (b *Block) File() string {
+	return b.file
+}
+
+// Patterns는 Host 블록의 패턴 목록을 반환한다. Host 블록이 아니면 nil을 반환한다.
+func (b *Block) Patterns() []string {
+	if b.Kind != "host" {
+		return nil
+	}
+	return splitArgs(b.lines[0].value)
+}
+
+// Aliases는 Host 패턴 중 와일드카드와 부정 패턴을 제외한 구체적인 alias를 반환한다.
+func (b *Block) Aliases() []string {
+	var aliases []string
+	for _, p := range b.Patterns() {
+		if strings.ContainsAny(p, "*?") || strings.HasPrefix(p, "!") {
+			continue
+		}
+		aliases = append(aliases, p)
+	}
+	return aliases
+}
+
+// Keys는 블록 본문의 지시어 이름을 소문자로 등장 순서대로 반환한다.
+func (b *Block) Keys() []string {
+	var keys []string
+	for _, l := range b.body() {
+		if l.keyword != "" {
+			keys = append(keys, l.key())
+		}
+	}
+	return keys
+}
+
+// Get은 블록 안에서 key의 첫 번째 값을 반환한다. key는 대소문자를 구분하지 않는다.
+func (b *Block) Get(key string) string {
+	v, _ := b.get(strings.ToLower(key))
+	return v
+}
+
+func (b *Block) get(key string) (string, bool) {
+	for _, l := range b.body() {
+		if l.key() == key {
+			return unquote(l.value), true
+		}
+	}
+	return "", false
+}
+
+// Set은 key의 값을 제자리에서 바꾼다. key가 없으면 블록의 마지막 지시어 뒤에 추가한다.
+func (b *Block) Set(key, value string) {
+	lk := strings.ToLower(key)
+	for _, l := range b.body() {
+		if l.key() == lk {
+			l.setValue(quote(value))
+			return
+		}
+	}
+
+	last := -1
+	indent := ""
+	if b.Kind != "" {
+		indent = "  "
+	}
+	foundIndent := false
+	for i, l := range b.lines {
+		if l.keyword == "" {
+			continue
+		}
+		last = i
+		if !foundIndent && (b.Kind == "" || i > 0) {
+			indent = l.indent
+			foundIndent = true
+		}
+	}
+
+	nl := &line{indent: indent, keyword: key}
+	nl.setValue(quote(value))
+	b.lines = append(b.lines[:last+1], append([]*line{nl}, b.lines[last+1:]...)...)
+}
+
+// Unset은 블록에서 key 지시어를 모두 제거한다.
+func (b *Block) Unset(key string) {
+	lk := strings.ToLower(key)
+	kept := b.lines[:0]
+	for i, l := range b.lines {
+		if (b.Kind == "" || i > 0) && l.key() == lk {
+			continue
+		}
+		kept = append(kept, l)
+	}
+	b.lines = kept
+}
+
+// body는 헤더 줄을 제외한 블록의 줄 목록이다.
+func (b *Block) body() []*line {
+	if b.Kind == "" {
+		return b.lines
+	}
+	return b.lines[1:]
+}
+
+// matches는 alias로 접속할 때 블록이 적용되는지 판단한다.
+// Include된 블록은 그 Include를 둔 블록도 적용되어야 한다.
+func (b *Block) matches(alias string) bool {
+	if b.scope != nil && !b.scope.matches(alias) {
+		return false
+	}
+	switch b.Kind {
+	case "":
+		return true
+	case "host":
+		return matchPatternList(b.Patterns(), alias)
+	default:
+		return false
+	}
+}
+
+func (b *Block) declares(alias string) bool {
+	for _, p := range b.Patterns() {
+		if strings.EqualFold(p, alias) {
+			return true
+		}
+	}
+	return false
+}
+
+func (b *Block) isCatchAll() bool {
+	for _, p := range b.Patterns() {
+		if p == "*" {
+			return true
+		}
+	}
+	return false
+}
+
+// IsGitHubHostName은 HostName 값이 GitHub SSH 엔드포인트인지 판단한다.
+func IsGitHubHostName(hostName string) bool {
+	for _, h := range githubHostNames {
+		if strings.EqualFold(hostName, h) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchPatternList는 OpenSSH의 Host 패턴 목록 규칙을 따른다.
+// 부정 패턴(!)이 일치하면 즉시 불일치, 그 외 패턴이 하나라도 일치하면 일치다.
+func matchPatternList(patterns []string, host string) bool {
+	host = strings.ToLower(host)
+	matched := false
+	for _, p := range patterns {
+		p = strings.ToLower(p)
+		if neg, ok := strings.CutPrefix(p, "!"); ok {
+			if matchPattern(neg, host) {
+				return false
+			}
+			continue
+		}
+		if matchPattern(p, host) {
+			matched = true
+		}
+	}
+	return matched
+}
+
+// matchPattern은 *와 ? 와일드카드를 지원하는 패턴 일치 검사다.
+func matchPattern(pattern, s string) bool {
+	for len(pattern) > 0 {
+		switch pattern[0] {
+		case '*':
+			pattern = pattern[1:]
+			if pattern == "" {
+				return true
+			}
+			for i := 0; i <= len(s); i++ {
+				if matchPattern(pattern, s[i:]) {
+					return true
+				}
+			}
+			return false
+		case '?':
+			if s == "" {
+				return false
+			}
+		default:
+			if s == "" || pattern[0] != s[0] {
+				return false
+			}
+		}
+		pattern, s = pattern[1:], s[1:]
+	}
+	return s == ""
+}
+
+// splitArgs는 공백으로 구분된 인자를 나눈다. 큰따옴표로 묶인 인자는 하나로 취급한다.
+func splitArgs(value string) []string {
+	var args []string
+	var cur strings.Builder
+	inQuote, hasArg := false, false
+	for _, r := range value {
+		switch {
+		case r == '"':
+			inQuote = !inQuote
+			hasArg = true
+		case (r == ' ' || r == '\t') && !inQuote:
+			if hasArg {
+				args = append(args, cur.String())
+				cur.Reset()
+				hasArg = false
+			}
+		default:
+			cur.WriteRune(r)
+			hasArg = true
+		}
+	}
+	if hasArg {
+		args = append(args, cur.String())
+	}
+	return args
+}
+
+func unquote(value string) string {
+	if len(value) >= 2 && value[0] == '"' && value[len(value)-1] == '"' {
+		return value[1 : len(value)-1]
+	}
+	return value
+}
+
+func quote(value string) string {
+	if strings.ContainsAny(value, " \t") && !strings.HasPrefix(value, `"`) {
+		return `"` + value + `"`
+	}
+	return value
+}
+
+// resolveInclude는 Include 경로의 ~를 확장하고, 상대 경로를 baseDir 기준으로 바꾼다.
+func resolveInclude(baseDir, pattern string) string {
+	pattern = fsutil.ExpandHome(pattern)
+	if !filepath.IsAbs(pattern) {
+		return filepath.Join(baseDir, pattern)
+	}
+	return pattern
+}
@@ -0,0 +1,257 @@
+package sshconfig_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/hbjs97/ctx/internal/sshconfig"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const sample = `# personal defaults
+AddKeysToAgent yes
+
+Host github.com-work github.com-work-alt
+    HostName github.com
+    IdentityFile ~/.ssh/id_work
+
+host github.com-personal
+	hostname=github.com
+	IDENTITYFILE = "~/.ssh/id personal"
+
+Host github-mirror
+    HostName git.example.com
+
+Match host *.corp exec "true"
+    User corp
+
+Host *
+    IdentityFile ~/.ssh/id_default
+`
+
+func TestParse_RoundTrip(t *testing.T) {
+	c := sshconfig.Parse("config", []byte(sample))
+	assert.Equal(t, sample, string(c.Bytes()))
+
+	noEOL := "Host a\n  HostName github.com"
+	assert.Equal(t, noEOL, string(sshconfig.Parse("config", []byte(noEOL)).Bytes()))
+}
+
+func TestConfig_Hosts(t *testing.T) {
+	c := sshconfig.Parse("config", []byte(sample))
+	assert.Equal(t, []string{"github.com-work", "github.com-work-alt", "github.com-personal", "github-mirror"}, c.Hosts())
+}
+
+func TestConfig_GitHubHosts_UsesHostName(t *testing.T) {
+	c := sshconfig.Parse("config", []byte(sample))
+	// github-mirror는 이름에 github이 있지만 실제 HostName이 다르므로 제외된다
+	assert.Equal(t, []string{"github.com-work", "github.com-work-alt", "github.com-personal"}, c.GitHubHosts())
+}
+
+func TestConfig_Lookup(t *testing.T) {
+	c := sshconfig.Parse("config", []byte(sample))
+
+	assert.Equal(t, "~/.ssh/id_work", c.Lookup("github.com-work-alt", "IdentityFile"))
+	assert.Equal(t, "~/.ssh/id personal", c.Lookup("github.com-personal", "identityfile"), "대소문자, = 구문, 따옴표")
+	assert.Equal(t, "~/.ssh/id_default", c.Lookup("github-mirror", "IdentityFile"), "Host * 기본값")
+	assert.Equal(t, "yes", c.Lookup("anything", "AddKeysToAgent"), "전역 영역")
+	assert.Empty(t, c.Lookup("x.corp", "User"), "Match 블록은 평가하지 않는다")
+}
+
+func TestConfig_Lookup_NegatedPattern(t *testing.T) {
+	c := sshconfig.Parse("config", []byte("Host *.example.com !bastion.example.com\n  User app\n"))
+	assert.Equal(t, "app", c.Lookup("web.example.com", "User"))
+	assert.Empty(t, c.Lookup("bastion.example.com", "User"))
+}
+
+func TestConfig_FindHost_ExactMatch(t *testing.T) {
+	c := sshconfig.Parse("config", []byte(sample))
+
+	assert.NotNil(t, c.FindHost("github.com-work-alt"))
+	assert.Nil(t, c.FindHost("github.com-wor"))
+	assert.Nil(t, c.FindHost("github.com-work2"), "접두사가 같은 alias와 혼동하지 않는다")
+}
+
+func TestBlock_Set_InPlace(t *testing.T) {
+	c := sshconfig.Parse("config", []byte(sample))
+
+	c.FindHost("github.com-personal").Set("IdentityFile", "~/.ssh/id_new")
+	c.FindHost("github.com-work").Set("IdentitiesOnly", "yes")
+
+	out := string(c.Bytes())
+	assert.Contains(t, out, "\tIDENTITYFILE = ~/.ssh/id_new\n", "키워드 표기와 구분자 유지")
+	assert.Contains(t, out, "    IdentityFile ~/.ssh/id_work\n    IdentitiesOnly yes\n\nhost github.com-personal", "블록 들여쓰기로 추가")
+	assert.Contains(t, out, "# personal defaults\n", "주석 보존")
+}
+
+func TestBlock_Unset(t *testing.T) {
+	c := sshconfig.Parse("config", []byte("Host a\n  User git\n  IdentityFile x\n"))
+	c.FindHost("a").Unset("user")
+	assert.Equal(t, "Host a\n  IdentityFile x\n", string(c.Bytes()))
+}
+
+func TestConfig_AddHost_BeforeCatchAll(t *testing.T) {
+	c := sshconfig.Parse("config", []byte(sample))
+
+	b := c.AddHost("github.com-new")
+	b.Set("HostName", "github.com")
+	b.Set("IdentityFile", "~/.ssh/id_new")
+
+	assert.Equal(t, "~/.ssh/id_new", c.Lookup("github.com-new", "IdentityFile"), "Host *에 가려지지 않는다")
+	assert.Contains(t, string(c.Bytes()), "Host github.com-new\n  HostName github.com\n  IdentityFile ~/.ssh/id_new\n\nMatch host")
+}
+
+func TestConfig_AddHost_EmptyFile(t *testing.T) {
+	c := sshconfig.Parse("config", nil)
+	c.AddHost("github.com-work").Set("HostName", "github.com")
+	assert.Equal(t, "Host github.com-work\n  HostName github.com\n", string(c.Bytes()))
+}
+
+func TestConfig_RemoveHost(t *testing.T) {
+	c := sshconfig.Parse("config", []byte(sample))
+
+	assert.True(t, c.RemoveHost("github.com-work-alt"))
+	assert.Contains(t, string(c.Bytes()), "Host github.com-work\n")
+	assert.True(t, c.RemoveHost("github.com-personal"))
+	assert.NotContains(t, string(c.Bytes()), "personal\n\tIDENTITYFILE")
+	assert.False(t, c.RemoveHost("missing"))
+	assert.Equal(t, []string{"github.com-work", "github-mirror"}, c.Hosts())
+}
+
+func TestConfig_RemoveHost_TakesLeadingComments(t *testing.T) {
+	c := sshconfig.Parse("config", []byte("Host a\n  HostName a.example.com\n  # a note\n\n# work account\n# managed by hand\nHost b\n  HostName github.com\n\nHost c\n  HostName c.example.com\n"))
+
+	require.True(t, c.RemoveHost("b"))
+	assert.Equal(t, "Host a\n  HostName a.example.com\n  # a note\n\nHost c\n  HostName c.example.com\n", string(c.Bytes()),
+		"Host 줄 바로 위 주석은 그 블록과 함께 지우고, 앞 블록 안의 주석은 남긴다")
+}
+
+func TestLoad_IncludeInHostBlockIsScoped(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "scoped"), []byte("User scoped\n"), 0600))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "config"),
+		[]byte("Host a\n  Include scoped\n\nMatch host b\n  Include scoped\n\nHost *\n  User default\n"), 0600))
+
+	c, err := sshconfig.Load(filepath.Join(dir, "config"))
+	require.NoError(t, err)
+
+	assert.Equal(t, "scoped", c.Lookup("a", "User"))
+	assert.Equal(t, "default", c.Lookup("b", "User"), "Match 안의 Include는 평가하지 않는다")
+	assert.Equal(t, "default", c.Lookup("other", "User"))
+}
+
+func TestLoad_FollowsInclude(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "config.d"), 0700))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "config.d", "work"),
+		[]byte("Host github.com-work\n  HostName github.com\n  IdentityFile ~/.ssh/id_work\n"), 0600))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "config"),
+		[]byte("Include config.d/*\n\nHost *\n  IdentityFile ~/.ssh/id_default\n"), 0600))
+
+	c, err := sshconfig.Load(filepath.Join(dir, "config"))
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"github.com-work"}, c.GitHubHosts())
+	assert.Equal(t, "~/.ssh/id_work", c.IdentityFiles()["github.com-work"])
+	assert.NotNil(t, c.FindHost("github.com-work"))
+}
+
+func TestLoad_IncludeLoop(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "config")
+	require.NoError(t, os.WriteFile(path, []byte("Include config\n"), 0600))
+
+	_, err := sshconfig.Load(path)
+	assert.Error(t, err)
+}
+
+func TestLoad_NoFile(t *testing.T) {
+	c, err := sshconfig.Load("/nonexistent/config")
+	require.NoError(t, err)
+	assert.Empty(t, c.Hosts())
+}
+
+func TestConfig_Save(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "ssh", "config")
+	c, err := sshconfig.Load(path)
+	require.NoError(t, err)
+	c.AddHost("github.com-work").Set("HostName", "github.com")
+	require.NoError(t, c.Save())
+
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+}
+
+func TestConfig_Save_KeepsModeAndSymlink(t *testing.T) {
+	dir := t.TempDir()
+	target := filepath.Join(dir, "dotfiles", "ssh_config")
+	require.NoError(t, os.MkdirAll(filepath.Dir(target), 0700))
+	require.NoError(t, os.WriteFile(target, []byte("Host a\n  HostName a.example.com\n"), 0644))
+	path := filepath.Join(dir, "config")
+	require.NoError(t, os.Symlink(target, path))
+
+	c, err := sshconfig.Load(path)
+	require.NoError(t, err)
+	c.AddHost("github.com-work").Set("HostName", "github.com")
+	require.NoError(t, c.Save())
+
+	info, err := os.Lstat(path)
+	require.NoError(t, err)
+	assert.NotZero(t, info.Mode()&os.ModeSymlink, "심볼릭 링크는 유지하고 대상 파일을 교체한다")
+	info, err = os.Stat(target)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0644), info.Mode().Perm(), "기존 권한 유지")
+	data, err := os.ReadFile(target)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), "Host github.com-work")
+}
+
+func TestConfig_IncludeHelpers(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "config")
+	ctxPath := filepath.Join(dir, "config.d", "ctx")
+	require.NoError(t, os.MkdirAll(filepath.Dir(ctxPath), 0700))
+	require.NoError(t, os.WriteFile(ctxPath, []byte("Host github.com-work\n  HostName github.com\n"), 0600))
+	require.NoError(t, os.WriteFile(path, []byte("Host github.com-work\n  User git\n"), 0600))
+
+	c, err := sshconfig.Load(path)
+	require.NoError(t, err)
+	assert.False(t, c.IncludesPath(ctxPath))
+
+	c.PrependInclude("config.d/ctx")
+	assert.True(t, c.IncludesPath(ctxPath))
+	assert.Equal(t, "Include config.d/ctx\n\nHost github.com-work\n  User git\n", string(c.Bytes()))
+	require.NoError(t, c.Save())
+
+	c, err = sshconfig.Load(path)
+	require.NoError(t, err)
+	assert.Equal(t, []string{ctxPath, path}, c.DeclaredIn("github.com-work"))
+	assert.Equal(t, ctxPath, c.FindHost("github.com-work").File())
+}
+
+func TestConfig_RemoveInclude(t *testing.T) {
+	dir := t.TempDir()
+	ctxPath := filepath.Join(dir, "config.d", "ctx")
+	c := sshconfig.Parse(filepath.Join(dir, "config"), []byte("Include config.d/ctx\n\nInclude mine config.d/ctx\nInclude config.d/*\n\nHost a\n  HostName a.example.com\n"))
+
+	assert.True(t, c.RemoveInclude(ctxPath))
+	assert.Equal(t, "Include mine\nInclude config.d/*\n\nHost a\n  HostName a.example.com\n", string(c.Bytes()),
+		"맨 앞 Include와 그 뒤 빈 줄을 지우고, 다른 패턴과 와일드카드는 남긴다")
+	assert.False(t, c.RemoveInclude(ctxPath))
+}
+
+func TestBlock_Keys(t *testing.T) {
+	c := sshconfig.Parse("config", []byte("Host a\n  # comment\n  HostName a.example.com\n  user git\n"))
+	assert.Equal(t, []string{"hostname", "user"}, c.LocalHost("a").Keys())
+	assert.Nil(t, c.LocalHost("b"))
+}
+
+func TestConfig_IncludesPath_Glob(t *testing.T) {
+	dir := t.TempDir()
+	c := sshconfig.Parse(filepath.Join(dir, "config"), []byte("Include config.d/*\n"))
+	assert.True(t, c.IncludesPath(filepath.Join(dir, "config.d", "ctx")))
+	assert.False(t, c.IncludesPath(filepath.Join(dir, "other", "ctx")))
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"strings"
+	"sync"
 )
 
 // Response는 FakeCommander의 사전 설정된 명령 응답이다.
@@ -29,6 +30,8 @@ type FakeCommander struct {
 	// DefaultResponse는 매칭되는 응답이 없을 때 반환된다.
 	// nil이면 미매칭 명령에 대해 에러를 반환한다.
 	DefaultResponse *Response
+
+	mu sync.Mutex // 병렬로 실행되는 진단 항목에서 동시에 호출될 수 있다
 }
 
 // NewFakeCommander는 빈 응답 맵으로 FakeCommander를 생성한다.
@@ -53,6 +56,8 @@ func (c *FakeCommander) Run(_ context.Context, name string, args ...string) ([]b
 		fullCmd = name + " " + strings.Join(args, " ")
 	}
 
+	c.mu.Lock()
+	defer c.mu.Unlock()
 	c.Calls = append(c.Calls, fullCmd)
 
 	// Exact match first.
@@ -82,20 +87,26 @@ func (c *FakeCommander) Run(_ context.Context, name string, args ...string) ([]b
 
 // RunWithEnv는 환경변수를 기록하고 Run 로직에 위임한다.
 func (c *FakeCommander) RunWithEnv(ctx context.Context, env map[string]string, name string, args ...string) ([]byte, error) {
+	c.mu.Lock()
 	c.EnvCalls = append(c.EnvCalls, env)
+	c.mu.Unlock()
 	return c.Run(ctx, name, args...)
 }
 
 // RunInteractiveWithEnv는 대화형 명령 실행을 시뮬레이션한다.
 // 테스트에서는 Run과 동일하게 동작하며 출력은 무시한다.
 func (c *FakeCommander) RunInteractiveWithEnv(ctx context.Context, env map[string]string, name string, args ...string) error {
+	c.mu.Lock()
 	c.EnvCalls = append(c.EnvCalls, env)
+	c.mu.Unlock()
 	_, err := c.Run(ctx, name, args...)
 	return err
 }
 
 // Called는 주어진 prefix와 매칭되는 명령이 실행되었으면 true를 반환한다.
 func (c *FakeCommander) Called(prefix string) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
 	for _, call := range c.Calls {
 		if strings.HasPrefix(call, prefix) {
 			return true
@@ -106,6 +117,8 @@ func (c *FakeCommander) Called(prefix string) bool {
 
 // CallCount는 주어진 prefix와 매칭되는 명령이 실행된 횟수를 반환한다.
 func (c *FakeCommander) CallCount(prefix string) int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
 	count := 0
 	for _, call := range c.Calls {
 		if strings.HasPrefix(call, prefix) {